@@ -9,26 +9,41 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/metrics"
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/config"
 	"github.com/qlcchain/go-qlc/ledger"
 	"github.com/qlcchain/go-qlc/ledger/process"
 	"github.com/qlcchain/go-qlc/log"
+	"github.com/qlcchain/go-qlc/vm/contract"
 	"github.com/qlcchain/go-qlc/vm/vmstore"
 	"go.uber.org/zap"
 )
 
+const (
+	uncheckedMetric = "qlc_ledger_unchecked_blocks"
+	dbSizeMetric    = "qlc_ledger_db_size_bytes"
+)
+
 type LedgerService struct {
 	common.ServiceLifecycle
 	Ledger *ledger.Ledger
+	cfg    *config.Config
+	quitCh chan bool
+	wg     sync.WaitGroup
 	logger *zap.SugaredLogger
 }
 
 func NewLedgerService(cfg *config.Config) *LedgerService {
 	return &LedgerService{
 		Ledger: ledger.NewLedger(cfg.LedgerDir()),
+		cfg:    cfg,
+		quitCh: make(chan bool, 1),
 		logger: log.NewLogger("ledger_service"),
 	}
 }
@@ -40,6 +55,23 @@ func (ls *LedgerService) Init() error {
 	defer ls.PostInit()
 	l := ls.Ledger
 
+	// a ledger can only be reopened with the genesis it is created from
+	if c, err := l.CountStateBlocks(); err != nil {
+		return err
+	} else if c > 0 {
+		if b, err := l.HasStateBlock(common.GenesisBlockHash()); err != nil {
+			return err
+		} else if !b {
+			return fmt.Errorf("ledger is not created from genesis %s", common.GenesisBlockHash().String())
+		}
+	}
+
+	if v, err := nep5Verifier(ls.cfg); err != nil {
+		return err
+	} else {
+		contract.SetNEP5Verifier(v)
+	}
+
 	genesis := common.GenesisBlock()
 	ctx := vmstore.NewVMContext(l)
 	err := ctx.SetStorage(types.MintageAddress[:], genesis.Token[:], genesis.Data)
@@ -102,6 +134,13 @@ func (ls *LedgerService) Init() error {
 		}
 	}
 
+	// commit the storage of the chain contracts to their storage tries
+	for _, address := range types.ChainContractAddressList {
+		if err := vmstore.NewVMContext(l).InitContractRoot(address); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -111,15 +150,79 @@ func (ls *LedgerService) Start() error {
 	}
 	defer ls.PostStart()
 
+	l := ls.Ledger
+	metrics.NewGaugeFunc(uncheckedMetric, "count of the unchecked blocks waiting for their previous or source",
+		func() float64 {
+			c, err := l.CountUncheckedBlocks()
+			if err != nil {
+				ls.logger.Error(err)
+			}
+			return float64(c)
+		})
+	metrics.NewGaugeVecFunc(dbSizeMetric, "size of the ledger database files by type", "type",
+		func() map[string]float64 {
+			lsm, vlog := l.Store.Size()
+			return map[string]float64{"lsm": float64(lsm), "vlog": float64(vlog)}
+		})
+
+	if p := ls.cfg.Pruning; p != nil && p.Enabled {
+		ls.wg.Add(1)
+		go ls.pruneLoop(p.Depth, time.Duration(p.Interval)*time.Second)
+	}
 	return nil
 }
 
+// nep5Verifier returns the verifier of the NEP5 lock transactions configured, it is nil if nothing is verified
+func nep5Verifier(cfg *config.Config) (contract.NEP5Verifier, error) {
+	if cfg.NEP5 == nil {
+		return nil, nil
+	}
+	switch cfg.NEP5.Verifier {
+	case "":
+		return nil, nil
+	case "rpc":
+		return contract.NewRPCNEP5Verifier(cfg.NEP5.Endpoint, cfg.NEP5.Method), nil
+	case "file":
+		return contract.NewFileNEP5Verifier(cfg.NEP5File()), nil
+	default:
+		return nil, fmt.Errorf("unknown nep5 verifier %s", cfg.NEP5.Verifier)
+	}
+}
+
+// pruneLoop prunes the history of the ledger every interval until the service is stopped
+func (ls *LedgerService) pruneLoop(depth int, interval time.Duration) {
+	defer ls.wg.Done()
+	if interval <= 0 {
+		interval = 10 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ls.quitCh:
+			return
+		case <-ticker.C:
+			start := time.Now()
+			n, err := ls.Ledger.Prune(depth)
+			if err != nil {
+				ls.logger.Errorf("prune ledger error: %s", err)
+				continue
+			}
+			ls.logger.Infof("prune %d blocks in %s", n, time.Since(start))
+		}
+	}
+}
+
 func (ls *LedgerService) Stop() error {
 	if !ls.PreStop() {
 		return errors.New("pre stop fail")
 	}
 	defer ls.PostStop()
 
+	metrics.Unregister(uncheckedMetric)
+	metrics.Unregister(dbSizeMetric)
+	ls.quitCh <- true
+	ls.wg.Wait()
 	ls.Ledger.Close()
 	// close all ledger
 	ledger.CloseLedger()
@@ -112,6 +112,9 @@ func addcommands() {
 	walletCreate()
 	walletList()
 	walletRemove()
+	walletExport()
+	walletImport()
+	sign()
 	mintage()
 	generateTestLedger()
 	pledge()
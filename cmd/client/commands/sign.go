@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/abiosoft/ishell"
+	"github.com/qlcchain/go-qlc/cmd/util"
+	"github.com/qlcchain/go-qlc/common/types"
+	cutil "github.com/qlcchain/go-qlc/common/util"
+	"github.com/qlcchain/go-qlc/rpc/api"
+	"github.com/qlcchain/go-qlc/wallet"
+	"github.com/spf13/cobra"
+)
+
+func sign() {
+	var keystoreP string
+	var pwdP string
+	var blockP string
+	var outP string
+	if interactive {
+		keystore := util.Flag{
+			Name:  "keystore",
+			Must:  true,
+			Usage: "keystore file of the wallet",
+			Value: "",
+		}
+		pwd := util.Flag{
+			Name:  "password",
+			Must:  false,
+			Usage: "password of the keystore",
+			Value: "",
+		}
+		block := util.Flag{
+			Name:  "block",
+			Must:  true,
+			Usage: "unsigned block file",
+			Value: "",
+		}
+		out := util.Flag{
+			Name:  "out",
+			Must:  false,
+			Usage: "file to write the signed block, print it if not set",
+			Value: "",
+		}
+		c := &ishell.Cmd{
+			Name: "sign",
+			Help: "sign an unsigned block offline with the key in keystore file",
+			Func: func(c *ishell.Context) {
+				args := []util.Flag{keystore, pwd, block, out}
+				if util.HelpText(c, args) {
+					return
+				}
+				if err := util.CheckArgs(c, args); err != nil {
+					util.Warn(err)
+					return
+				}
+				keystoreP = util.StringVar(c.Args, keystore)
+				pwdP = util.StringVar(c.Args, pwd)
+				blockP = util.StringVar(c.Args, block)
+				outP = util.StringVar(c.Args, out)
+
+				err := signBlock(keystoreP, pwdP, blockP, outP)
+				if err != nil {
+					util.Warn(err)
+					return
+				}
+			},
+		}
+		shell.AddCmd(c)
+	} else {
+		var signCmd = &cobra.Command{
+			Use:   "sign",
+			Short: "sign an unsigned block offline with the key in keystore file",
+			Run: func(cmd *cobra.Command, args []string) {
+				err := signBlock(keystoreP, pwdP, blockP, outP)
+				if err != nil {
+					cmd.Println(err)
+					return
+				}
+			},
+		}
+		signCmd.Flags().StringVarP(&keystoreP, "keystore", "k", "", "keystore file of the wallet")
+		signCmd.Flags().StringVarP(&pwdP, "password", "p", "", "password of the keystore")
+		signCmd.Flags().StringVarP(&blockP, "block", "b", "", "unsigned block file")
+		signCmd.Flags().StringVarP(&outP, "out", "o", "", "file to write the signed block, print it if not set")
+		rootCmd.AddCommand(signCmd)
+	}
+}
+
+// signBlock never connects to a node, the unsigned block is the result of ledger_generateUnsigned*Block and
+// the signed block can be submitted by ledger_process
+func signBlock(keystoreP, pwdP, blockP, outP string) error {
+	if keystoreP == "" || blockP == "" {
+		return errors.New("invalid keystore or block file")
+	}
+	content, err := ioutil.ReadFile(keystoreP)
+	if err != nil {
+		return err
+	}
+	ks, err := wallet.ParseKeystore(content)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(blockP)
+	if err != nil {
+		return err
+	}
+	block, err := parseUnsignedBlock(data)
+	if err != nil {
+		return err
+	}
+	acc, err := ks.Account(pwdP, block.Address)
+	if err != nil {
+		return err
+	}
+	block.Signature = acc.Sign(block.GetHash())
+
+	s := cutil.ToIndentString(block)
+	if outP == "" {
+		fmt.Println(s)
+		return nil
+	}
+	if err := ioutil.WriteFile(outP, []byte(s), 0600); err != nil {
+		return err
+	}
+	s = fmt.Sprintf("sign block %s to %s success", block.GetHash(), outP)
+	if interactive {
+		util.Info(s)
+	} else {
+		fmt.Println(s)
+	}
+	return nil
+}
+
+// parseUnsignedBlock accepts both the unsigned block with hash returned by rpc and a bare block
+func parseUnsignedBlock(data []byte) (*types.StateBlock, error) {
+	var unsigned api.APIUnsignedBlock
+	if err := json.Unmarshal(data, &unsigned); err == nil && unsigned.Block != nil {
+		if !unsigned.Hash.IsZero() && unsigned.Hash != unsigned.Block.GetHash() {
+			return nil, fmt.Errorf("block hash %s does not match %s", unsigned.Block.GetHash(), unsigned.Hash)
+		}
+		return unsigned.Block, nil
+	}
+	block := new(types.StateBlock)
+	if err := json.Unmarshal(data, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/qlcchain/go-qlc/cmd/util"
+
+	"github.com/abiosoft/ishell"
+	"github.com/qlcchain/go-qlc/rpc"
+	"github.com/spf13/cobra"
+)
+
+func walletExport() {
+	var accountP string
+	var pwdP string
+	var fileP string
+	if interactive {
+		account := util.Flag{
+			Name:  "account",
+			Must:  true,
+			Usage: "account for wallet",
+			Value: "",
+		}
+		pwd := util.Flag{
+			Name:  "password",
+			Must:  false,
+			Usage: "password for wallet",
+			Value: "",
+		}
+		file := util.Flag{
+			Name:  "file",
+			Must:  true,
+			Usage: "keystore file to write",
+			Value: "",
+		}
+		c := &ishell.Cmd{
+			Name: "walletexport",
+			Help: "export a wallet to keystore file",
+			Func: func(c *ishell.Context) {
+				args := []util.Flag{account, pwd, file}
+				if util.HelpText(c, args) {
+					return
+				}
+				if err := util.CheckArgs(c, args); err != nil {
+					util.Warn(err)
+					return
+				}
+				accountP = util.StringVar(c.Args, account)
+				pwdP = util.StringVar(c.Args, pwd)
+				fileP = util.StringVar(c.Args, file)
+
+				err := exportWallet(accountP, pwdP, fileP)
+				if err != nil {
+					util.Warn(err)
+					return
+				}
+			},
+		}
+		shell.AddCmd(c)
+	} else {
+		var weCmd = &cobra.Command{
+			Use:   "walletexport",
+			Short: "export a wallet to keystore file",
+			Run: func(cmd *cobra.Command, args []string) {
+				err := exportWallet(accountP, pwdP, fileP)
+				if err != nil {
+					cmd.Println(err)
+					return
+				}
+			},
+		}
+		weCmd.Flags().StringVarP(&accountP, "account", "a", "", "wallet address")
+		weCmd.Flags().StringVarP(&pwdP, "password", "p", "", "password for wallet")
+		weCmd.Flags().StringVarP(&fileP, "file", "f", "", "keystore file to write")
+		rootCmd.AddCommand(weCmd)
+	}
+}
+
+func exportWallet(accountP, pwdP, fileP string) error {
+	if fileP == "" {
+		return errors.New("invalid keystore file")
+	}
+	client, err := rpc.Dial(endpointP)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	var content string
+	err = client.Call(&content, "wallet_export", accountP, pwdP)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(fileP, []byte(content), 0600); err != nil {
+		return err
+	}
+	s := fmt.Sprintf("export wallet %s to %s success", accountP, fileP)
+	if interactive {
+		util.Info(s)
+	} else {
+		fmt.Println(s)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/qlcchain/go-qlc/cmd/util"
+
+	"github.com/abiosoft/ishell"
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/rpc"
+	"github.com/spf13/cobra"
+)
+
+func walletImport() {
+	var pwdP string
+	var fileP string
+	if interactive {
+		pwd := util.Flag{
+			Name:  "password",
+			Must:  false,
+			Usage: "password of the keystore",
+			Value: "",
+		}
+		file := util.Flag{
+			Name:  "file",
+			Must:  true,
+			Usage: "keystore file to import",
+			Value: "",
+		}
+		c := &ishell.Cmd{
+			Name: "walletimport",
+			Help: "import a wallet from keystore file",
+			Func: func(c *ishell.Context) {
+				args := []util.Flag{pwd, file}
+				if util.HelpText(c, args) {
+					return
+				}
+				if err := util.CheckArgs(c, args); err != nil {
+					util.Warn(err)
+					return
+				}
+				pwdP = util.StringVar(c.Args, pwd)
+				fileP = util.StringVar(c.Args, file)
+
+				err := importWallet(pwdP, fileP)
+				if err != nil {
+					util.Warn(err)
+					return
+				}
+			},
+		}
+		shell.AddCmd(c)
+	} else {
+		var wiCmd = &cobra.Command{
+			Use:   "walletimport",
+			Short: "import a wallet from keystore file",
+			Run: func(cmd *cobra.Command, args []string) {
+				err := importWallet(pwdP, fileP)
+				if err != nil {
+					cmd.Println(err)
+					return
+				}
+			},
+		}
+		wiCmd.Flags().StringVarP(&pwdP, "password", "p", "", "password of the keystore")
+		wiCmd.Flags().StringVarP(&fileP, "file", "f", "", "keystore file to import")
+		rootCmd.AddCommand(wiCmd)
+	}
+}
+
+func importWallet(pwdP, fileP string) error {
+	if fileP == "" {
+		return errors.New("invalid keystore file")
+	}
+	content, err := ioutil.ReadFile(fileP)
+	if err != nil {
+		return err
+	}
+	client, err := rpc.Dial(endpointP)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	var addr types.Address
+	err = client.Call(&addr, "wallet_import", string(content), pwdP)
+	if err != nil {
+		return err
+	}
+	s := fmt.Sprintf("import wallet %s from %s success", addr.String(), fileP)
+	if interactive {
+		util.Info(s)
+	} else {
+		fmt.Println(s)
+	}
+	return nil
+}
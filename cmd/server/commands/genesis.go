@@ -0,0 +1,282 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package commands
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/abiosoft/ishell"
+	"github.com/qlcchain/go-qlc/cmd/util"
+	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/types"
+	cutil "github.com/qlcchain/go-qlc/common/util"
+	"github.com/qlcchain/go-qlc/config"
+	cabi "github.com/qlcchain/go-qlc/vm/contract/abi"
+	"github.com/spf13/cobra"
+)
+
+type genesisTokenParam struct {
+	name        string
+	totalSupply string
+	decimals    int
+}
+
+func genesisCmd() {
+	var fileP string
+	var networkIdP int
+	chainP := genesisTokenParam{name: "QLC", totalSupply: "60000000000000000", decimals: 8}
+	gasP := genesisTokenParam{name: "QGAS", totalSupply: "10000000000000000", decimals: 8}
+
+	if interactive {
+		file := util.Flag{
+			Name:  "file",
+			Must:  false,
+			Usage: "genesis file",
+			Value: "genesis.json",
+		}
+		networkId := util.Flag{
+			Name:  "networkId",
+			Must:  false,
+			Usage: "network id of the private network",
+			Value: 100,
+		}
+		chain := util.Flag{
+			Name:  "chainToken",
+			Must:  false,
+			Usage: "name and symbol of the chain token",
+			Value: chainP.name,
+		}
+		chainSupply := util.Flag{
+			Name:  "chainSupply",
+			Must:  false,
+			Usage: "total supply of the chain token in raw",
+			Value: chainP.totalSupply,
+		}
+		gas := util.Flag{
+			Name:  "gasToken",
+			Must:  false,
+			Usage: "name and symbol of the gas token",
+			Value: gasP.name,
+		}
+		gasSupply := util.Flag{
+			Name:  "gasSupply",
+			Must:  false,
+			Usage: "total supply of the gas token in raw",
+			Value: gasP.totalSupply,
+		}
+		c := &ishell.Cmd{
+			Name: "genesis",
+			Help: "genesis tools for private networks",
+		}
+		c.AddCmd(&ishell.Cmd{
+			Name: "new",
+			Help: "create and sign a genesis file for a new network",
+			Func: func(c *ishell.Context) {
+				args := []util.Flag{seed, file, networkId, chain, chainSupply, gas, gasSupply}
+				if util.HelpText(c, args) {
+					return
+				}
+				if err := util.CheckArgs(c, args); err != nil {
+					util.Warn(err)
+					return
+				}
+				var err error
+				seedP = util.StringVar(c.Args, seed)
+				fileP = util.StringVar(c.Args, file)
+				if networkIdP, err = util.IntVar(c.Args, networkId); err != nil {
+					util.Warn(err)
+					return
+				}
+				chainP.name = util.StringVar(c.Args, chain)
+				chainP.totalSupply = util.StringVar(c.Args, chainSupply)
+				gasP.name = util.StringVar(c.Args, gas)
+				gasP.totalSupply = util.StringVar(c.Args, gasSupply)
+				if err := createGenesis(fileP, seedP, networkIdP, chainP, gasP); err != nil {
+					util.Warn(err)
+				}
+			},
+		})
+		shell.AddCmd(c)
+	} else {
+		var gCmd = &cobra.Command{
+			Use:   "genesis",
+			Short: "genesis tools for private networks",
+		}
+		var newCmd = &cobra.Command{
+			Use:   "new",
+			Short: "create and sign a genesis file for a new network",
+			Run: func(cmd *cobra.Command, args []string) {
+				if err := createGenesis(fileP, seedP, networkIdP, chainP, gasP); err != nil {
+					cmd.Println(err)
+				}
+			},
+		}
+		newCmd.Flags().StringVarP(&fileP, "file", "f", "genesis.json", "genesis file")
+		newCmd.Flags().IntVar(&networkIdP, "networkId", 100, "network id of the private network")
+		newCmd.Flags().StringVar(&chainP.name, "chainToken", chainP.name, "name and symbol of the chain token")
+		newCmd.Flags().StringVar(&chainP.totalSupply, "chainSupply", chainP.totalSupply, "total supply of the chain token in raw")
+		newCmd.Flags().StringVar(&gasP.name, "gasToken", gasP.name, "name and symbol of the gas token")
+		newCmd.Flags().StringVar(&gasP.totalSupply, "gasSupply", gasP.totalSupply, "total supply of the gas token in raw")
+		gCmd.AddCommand(newCmd)
+		rootCmd.AddCommand(gCmd)
+	}
+}
+
+// createGenesis writes a genesis file, the chain token is owned by the account 0 of the seed and the gas token by
+// the account 1, a new seed is generated if seedStr is empty
+func createGenesis(file, seedStr string, networkId int, chain, gas genesisTokenParam) error {
+	if len(file) == 0 {
+		return errors.New("invalid genesis file")
+	}
+	var seed *types.Seed
+	var err error
+	if len(seedStr) == 0 {
+		if seed, err = types.NewSeed(); err != nil {
+			return err
+		}
+	} else {
+		b, err := hex.DecodeString(seedStr)
+		if err != nil {
+			return err
+		}
+		if seed, err = types.BytesToSeed(b); err != nil {
+			return err
+		}
+	}
+	g, err := newGenesis(seed, uint32(networkId), chain, gas)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(file, []byte(cutil.ToIndentString(g)), 0644); err != nil {
+		return err
+	}
+
+	s := fmt.Sprintf("create genesis of network %d to %s success, seed: %s\nrun the genesis representative with --seed and set \"genesis\" of the config to the file",
+		g.NetworkID, file, seed.String())
+	if interactive {
+		util.Info(s)
+	} else {
+		fmt.Println(s)
+	}
+	return nil
+}
+
+func newGenesis(seed *types.Seed, networkId uint32, chain, gas genesisTokenParam) (*common.Genesis, error) {
+	chainAccount, err := seed.Account(0)
+	if err != nil {
+		return nil, err
+	}
+	gasAccount, err := seed.Account(1)
+	if err != nil {
+		return nil, err
+	}
+	g := &common.Genesis{NetworkID: networkId}
+	if g.Chain, err = newGenesisToken(chainAccount, chain); err != nil {
+		return nil, err
+	}
+	if g.Gas, err = newGenesisToken(gasAccount, gas); err != nil {
+		return nil, err
+	}
+	if err := g.Verify(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func newGenesisToken(account *types.Account, param genesisTokenParam) (common.GenesisToken, error) {
+	var token common.GenesisToken
+	if len(param.name) == 0 || param.decimals < 0 || param.decimals > 255 {
+		return token, fmt.Errorf("invalid token %s", param.name)
+	}
+	totalSupply, ok := new(big.Int).SetString(param.totalSupply, 10)
+	if !ok || totalSupply.Sign() <= 0 {
+		return token, fmt.Errorf("invalid total supply %s of token %s", param.totalSupply, param.name)
+	}
+	address := account.Address()
+	tokenId := cabi.NewTokenHash(address, types.ZeroHash, param.name)
+	timestamp := common.TimeNow().UTC().Unix()
+
+	data, err := cabi.MintageABI.PackMethod(cabi.MethodNameMintage, tokenId, param.name, param.name, totalSupply,
+		uint8(param.decimals), address, "")
+	if err != nil {
+		return token, err
+	}
+	token.Mintage = types.StateBlock{
+		Type:           types.ContractSend,
+		Token:          tokenId,
+		Address:        types.MintageAddress,
+		Balance:        types.ZeroBalance,
+		Vote:           types.ZeroBalance,
+		Network:        types.ZeroBalance,
+		Storage:        types.ZeroBalance,
+		Oracle:         types.ZeroBalance,
+		Link:           types.Hash(address),
+		Representative: address,
+		Data:           data,
+		Timestamp:      timestamp,
+	}
+	token.Mintage.Signature = account.Sign(token.Mintage.GetHash())
+
+	info, err := cabi.MintageABI.PackVariable(cabi.VariableNameGenesisToken, tokenId, param.name, param.name,
+		totalSupply, uint8(param.decimals), address, big.NewInt(0), int64(0), address)
+	if err != nil {
+		return token, err
+	}
+	token.Genesis = types.StateBlock{
+		Type:           types.ContractReward,
+		Token:          tokenId,
+		Address:        address,
+		Balance:        types.Balance{Int: totalSupply},
+		Vote:           types.ZeroBalance,
+		Network:        types.ZeroBalance,
+		Storage:        types.ZeroBalance,
+		Oracle:         types.ZeroBalance,
+		Link:           token.Mintage.GetHash(),
+		Representative: address,
+		Data:           info,
+		Timestamp:      timestamp,
+	}
+	token.Genesis.Signature = account.Sign(token.Genesis.GetHash())
+
+	if token.Token, err = cabi.ParseGenesisTokenInfo(info); err != nil {
+		return token, err
+	}
+	return token, nil
+}
+
+// loadGenesis replaces the built-in genesis with the genesis file of the config, the token info of the file is
+// checked against the genesis blocks
+func loadGenesis(cfg *config.Config) error {
+	file := cfg.GenesisFile()
+	if len(file) == 0 {
+		return nil
+	}
+	g, err := common.LoadGenesis(file)
+	if err != nil {
+		return err
+	}
+	for _, t := range []*common.GenesisToken{&g.Chain, &g.Gas} {
+		info, err := cabi.ParseGenesisTokenInfo(t.Genesis.Data)
+		if err != nil {
+			return fmt.Errorf("%s: %s", common.ErrInvalidGenesis, err)
+		}
+		if t.Token == nil || info.TokenName != t.Token.TokenName || info.TokenSymbol != t.Token.TokenSymbol ||
+			info.Decimals != t.Token.Decimals || info.TotalSupply.Cmp(t.Token.TotalSupply) != 0 {
+			return fmt.Errorf("%s: token info of %s does not match the genesis block", common.ErrInvalidGenesis, t.Genesis.Token)
+		}
+	}
+	if err := common.SetGenesis(g); err != nil {
+		return err
+	}
+	fmt.Printf("run private network %d from genesis %s\n", g.NetworkID, file)
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	ss "github.com/qlcchain/go-qlc/chain/services"
+	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/config"
+)
+
+func TestCreateGenesis(t *testing.T) {
+	dir := filepath.Join(config.QlcTestDataDir(), uuid.New().String())
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	current := common.CurrentGenesis()
+	defer func() {
+		if err := common.SetGenesis(current); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	seed, err := types.NewSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.DefaultConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Genesis = "genesis.json"
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	chain := genesisTokenParam{name: "DEV", totalSupply: "1000000000", decimals: 8}
+	gas := genesisTokenParam{name: "DGAS", totalSupply: "2000000000", decimals: 8}
+	if err := createGenesis(cfg.GenesisFile(), seed.String(), 7, chain, gas); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadGenesis(cfg); err != nil {
+		t.Fatal(err)
+	}
+	account, _ := seed.Account(0)
+	if common.NetworkID() != 7 || common.GenesisAddress() != account.Address() {
+		t.Fatal("invalid genesis", common.NetworkID(), common.GenesisAddress())
+	}
+
+	ls := ss.NewLedgerService(cfg)
+	if err := ls.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = ls.Stop()
+	}()
+	tm, err := ls.Ledger.GetTokenMeta(account.Address(), common.ChainToken())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tm.Balance.String() != chain.totalSupply {
+		t.Fatal("invalid genesis balance", tm.Balance)
+	}
+
+	if _, err := newGenesis(seed, 7, chain, genesisTokenParam{name: "DGAS", totalSupply: "-1", decimals: 8}); err == nil {
+		t.Fatal("invalid total supply should be rejected")
+	}
+}
@@ -0,0 +1,338 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/abiosoft/ishell"
+	"github.com/qlcchain/go-qlc/cmd/util"
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/config"
+	"github.com/qlcchain/go-qlc/ledger"
+	"github.com/qlcchain/go-qlc/ledger/relation"
+	"github.com/spf13/cobra"
+)
+
+func ledgerCmd() {
+	var fileP string
+	var repairP bool
+	if interactive {
+		file := util.Flag{
+			Name:  "file",
+			Must:  true,
+			Usage: "snapshot archive file",
+			Value: "",
+		}
+		repair := util.Flag{
+			Name:  "repair",
+			Must:  false,
+			Usage: "repair the inconsistencies which can be rebuilt from the blocks",
+			Value: false,
+		}
+		c := &ishell.Cmd{
+			Name: "ledger",
+			Help: "ledger snapshot and consistency tools",
+		}
+		c.AddCmd(&ishell.Cmd{
+			Name: "export",
+			Help: "export the ledger to a snapshot archive",
+			Func: func(c *ishell.Context) {
+				args := []util.Flag{file, cfgPath}
+				if util.HelpText(c, args) {
+					return
+				}
+				if err := util.CheckArgs(c, args); err != nil {
+					util.Warn(err)
+					return
+				}
+				fileP = util.StringVar(c.Args, file)
+				cfgPathP = util.StringVar(c.Args, cfgPath)
+				if err := exportLedger(fileP); err != nil {
+					util.Warn(err)
+				}
+			},
+		})
+		c.AddCmd(&ishell.Cmd{
+			Name: "import",
+			Help: "import a snapshot archive into an empty ledger",
+			Func: func(c *ishell.Context) {
+				args := []util.Flag{file, cfgPath}
+				if util.HelpText(c, args) {
+					return
+				}
+				if err := util.CheckArgs(c, args); err != nil {
+					util.Warn(err)
+					return
+				}
+				fileP = util.StringVar(c.Args, file)
+				cfgPathP = util.StringVar(c.Args, cfgPath)
+				if err := importLedger(fileP); err != nil {
+					util.Warn(err)
+				}
+			},
+		})
+		c.AddCmd(&ishell.Cmd{
+			Name: "verify",
+			Help: "check the consistency of the ledger",
+			Func: func(c *ishell.Context) {
+				args := []util.Flag{repair, cfgPath}
+				if util.HelpText(c, args) {
+					return
+				}
+				if err := util.CheckArgs(c, args); err != nil {
+					util.Warn(err)
+					return
+				}
+				repairP = util.BoolVar(c.Args, repair)
+				cfgPathP = util.StringVar(c.Args, cfgPath)
+				if err := verifyLedger(repairP); err != nil {
+					util.Warn(err)
+				}
+			},
+		})
+		shell.AddCmd(c)
+	} else {
+		var lCmd = &cobra.Command{
+			Use:   "ledger",
+			Short: "ledger snapshot and consistency tools",
+		}
+		var exportCmd = &cobra.Command{
+			Use:   "export",
+			Short: "export the ledger to a snapshot archive",
+			// the errors of the ledger commands are printed by the root command, which exits with a non-zero status
+			SilenceErrors: true,
+			SilenceUsage:  true,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return exportLedger(fileP)
+			},
+		}
+		exportCmd.Flags().StringVarP(&fileP, "file", "f", "", "snapshot archive file")
+		var importCmd = &cobra.Command{
+			Use:   "import",
+			Short: "import a snapshot archive into an empty ledger",
+			SilenceErrors: true,
+			SilenceUsage:  true,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return importLedger(fileP)
+			},
+		}
+		importCmd.Flags().StringVarP(&fileP, "file", "f", "", "snapshot archive file")
+		var verifyCmd = &cobra.Command{
+			Use:   "verify",
+			Short: "check the consistency of the ledger",
+			SilenceErrors: true,
+			SilenceUsage:  true,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return verifyLedger(repairP)
+			},
+		}
+		verifyCmd.Flags().BoolVar(&repairP, "repair", false, "repair the inconsistencies which can be rebuilt from the blocks")
+		lCmd.AddCommand(exportCmd, importCmd, verifyCmd)
+		rootCmd.AddCommand(lCmd)
+	}
+}
+
+func loadLedgerConfig() (*config.Config, error) {
+	var cfg *config.Config
+	var err error
+	if cfgPathP == "" {
+		cfgPathP = config.DefaultDataDir()
+		cm := config.NewCfgManager(cfgPathP)
+		cfg, err = cm.Load(config.NewMigrationV1ToV2(), config.NewMigrationV2ToV3(), config.NewMigrationV3ToV4())
+	} else {
+		cfg, err = loadConfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+	// snapshots are bound to the genesis of the network
+	if err := loadGenesis(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func exportLedger(file string) error {
+	if len(file) == 0 {
+		return errors.New("invalid snapshot file")
+	}
+	cfg, err := loadLedgerConfig()
+	if err != nil {
+		return err
+	}
+	l := ledger.NewLedger(cfg.LedgerDir())
+	defer l.Close()
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	count, err := l.ExportSnapshot(f)
+	if err != nil {
+		return err
+	}
+	s := fmt.Sprintf("export %d records to %s success", count, file)
+	if interactive {
+		util.Info(s)
+	} else {
+		fmt.Println(s)
+	}
+	return nil
+}
+
+func importLedger(file string) error {
+	if len(file) == 0 {
+		return errors.New("invalid snapshot file")
+	}
+	cfg, err := loadLedgerConfig()
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	l := ledger.NewLedger(cfg.LedgerDir())
+	defer l.Close()
+
+	// rebuild the relation rows from the imported blocks
+	r, err := relation.NewRelation(cfg)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if err := r.SetEvent(); err != nil {
+		return err
+	}
+	defer r.UnsubscribeEvent()
+
+	count, err := l.ImportSnapshot(f)
+	if err != nil {
+		return err
+	}
+	s := fmt.Sprintf("import %d records from %s success", count, file)
+	if interactive {
+		util.Info(s)
+	} else {
+		fmt.Println(s)
+	}
+	return nil
+}
+
+func verifyLedger(repair bool) error {
+	cfg, err := loadLedgerConfig()
+	if err != nil {
+		return err
+	}
+	l := ledger.NewLedger(cfg.LedgerDir())
+	defer l.Close()
+	r, err := relation.NewRelation(cfg)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	report, err := l.Verify(repair)
+	if err != nil {
+		return err
+	}
+	if err := verifyRelation(l, r, repair, report); err != nil {
+		return err
+	}
+
+	repaired := 0
+	for _, i := range report.Inconsistencies {
+		if i.Repaired {
+			repaired++
+		}
+		if interactive {
+			util.Warn(i.String())
+		} else {
+			fmt.Println(i.String())
+		}
+	}
+	s := fmt.Sprintf("verify %d blocks of %d accounts, %d inconsistencies, %d repaired", report.Blocks,
+		report.Accounts, len(report.Inconsistencies), repaired)
+	if interactive {
+		util.Info(s)
+	} else {
+		fmt.Println(s)
+	}
+	if n := len(report.Inconsistencies) - repaired; n > 0 {
+		return fmt.Errorf("ledger has %d inconsistencies which are not repaired", n)
+	}
+	return nil
+}
+
+// verifyRelation checks that every block has a relation row and every row has a block
+func verifyRelation(l *ledger.Ledger, r *relation.Relation, repair bool, report *ledger.VerifyReport) error {
+	const pageSize = 1000
+	rows := make(map[types.Hash]bool)
+	for offset := 0; ; offset += pageSize {
+		hashes, err := r.Blocks(pageSize, offset)
+		if err != nil {
+			return err
+		}
+		for _, h := range hashes {
+			rows[h] = true
+		}
+		if len(hashes) < pageSize {
+			break
+		}
+	}
+
+	var missing []*types.StateBlock
+	err := l.GetStateBlocks(func(blk *types.StateBlock) error {
+		hash := blk.GetHash()
+		if rows[hash] {
+			delete(rows, hash)
+		} else {
+			missing = append(missing, blk)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, blk := range missing {
+		if repair {
+			if err := r.AddBlock(blk); err != nil {
+				return err
+			}
+		}
+		report.Inconsistencies = append(report.Inconsistencies, &ledger.Inconsistency{
+			Kind:     ledger.InconsistentRelation,
+			Hash:     blk.GetHash(),
+			Address:  blk.Address,
+			Detail:   fmt.Sprintf("relation of block %s not found", blk.GetHash()),
+			Repaired: repair,
+		})
+	}
+	for hash := range rows {
+		if repair {
+			if err := r.DeleteBlock(hash); err != nil {
+				return err
+			}
+		}
+		report.Inconsistencies = append(report.Inconsistencies, &ledger.Inconsistency{
+			Kind:     ledger.InconsistentRelation,
+			Hash:     hash,
+			Detail:   fmt.Sprintf("block %s of relation not found", hash),
+			Repaired: repair,
+		})
+	}
+	return nil
+}
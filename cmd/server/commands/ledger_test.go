@@ -0,0 +1,68 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/qlcchain/go-qlc/config"
+	"github.com/qlcchain/go-qlc/ledger"
+	"github.com/qlcchain/go-qlc/ledger/relation"
+	"github.com/qlcchain/go-qlc/test/mock"
+)
+
+func TestVerifyRelation(t *testing.T) {
+	dir := filepath.Join(config.QlcTestDataDir(), uuid.New().String())
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	cfg, err := config.DefaultConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := ledger.NewLedger(cfg.LedgerDir())
+	defer l.Close()
+	r, err := relation.NewRelation(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	blk := mock.StateBlockWithoutWork()
+	if err := l.AddStateBlock(blk); err != nil {
+		t.Fatal(err)
+	}
+	row := mock.StateBlockWithoutWork()
+	if err := r.AddBlock(row); err != nil {
+		t.Fatal(err)
+	}
+
+	report := new(ledger.VerifyReport)
+	if err := verifyRelation(l, r, false, report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Inconsistencies) != 2 || report.Inconsistencies[0].Hash != blk.GetHash() ||
+		report.Inconsistencies[1].Hash != row.GetHash() {
+		t.Fatal("invalid inconsistencies", report.Inconsistencies)
+	}
+
+	report = new(ledger.VerifyReport)
+	if err := verifyRelation(l, r, true, report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Inconsistencies) != 2 || !report.Inconsistencies[0].Repaired || !report.Inconsistencies[1].Repaired {
+		t.Fatal("inconsistencies should be repaired", report.Inconsistencies)
+	}
+	report = new(ledger.VerifyReport)
+	if err := verifyRelation(l, r, false, report); err != nil || len(report.Inconsistencies) != 0 {
+		t.Fatal("relation should be repaired", report.Inconsistencies, err)
+	}
+}
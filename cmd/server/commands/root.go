@@ -129,6 +129,8 @@ func addCommand() {
 		run()
 	}
 	walletimport()
+	ledgerCmd()
+	genesisCmd()
 	version()
 }
 
@@ -139,7 +141,7 @@ func start() error {
 	if cfgPathP == "" {
 		cfgPathP = config.DefaultDataDir()
 		cm := config.NewCfgManager(cfgPathP)
-		cfg, err = cm.Load(config.NewMigrationV1ToV2(), config.NewMigrationV2ToV3())
+		cfg, err = cm.Load(config.NewMigrationV1ToV2(), config.NewMigrationV2ToV3(), config.NewMigrationV3ToV4())
 		if err != nil {
 			return err
 		}
@@ -156,6 +158,9 @@ func start() error {
 			return err
 		}
 	}
+	if err := loadGenesis(cfg); err != nil {
+		return err
+	}
 	if len(seedP) > 0 {
 		fmt.Println("run node SEED mode")
 		sByte, _ := hex.DecodeString(seedP)
@@ -20,6 +20,8 @@ import (
 	"github.com/qlcchain/go-qlc/config"
 	"github.com/qlcchain/go-qlc/ledger"
 	"github.com/qlcchain/go-qlc/log"
+	"github.com/qlcchain/go-qlc/vm/contract"
+	"github.com/qlcchain/go-qlc/vm/vmstore"
 	cmn "github.com/tendermint/tmlibs/common"
 )
 
@@ -101,6 +103,13 @@ func initNode(accounts []*types.Account, cfg *config.Config) error {
 							break
 						}
 					}
+					//refund the contract send of the account if the contract can not receive it
+					if blk.Type == types.ContractSend && blk.Address == addr {
+						if err := refund(blk, value); err != nil {
+							fmt.Printf("err[%s] when generate refund block.\n", err)
+						}
+						break
+					}
 				}
 			}(accounts)
 		})
@@ -192,6 +201,55 @@ func receive(sendBlock *types.StateBlock, account *types.Account) error {
 	return nil
 }
 
+func refund(sendBlock *types.StateBlock, account *types.Account) error {
+	if rPCService.State() != int32(common.Started) || ledgerService.State() != int32(common.Started) {
+		return errors.New("rpc or ledger service not started")
+	}
+
+	//only the send which the chain contract fails to receive is refunded
+	address := types.Address(sendBlock.GetLink())
+	c, ok, err := contract.GetChainContract(address, sendBlock.GetData())
+	if err != nil || !ok {
+		return err
+	}
+	if _, err := c.DoReceive(vmstore.NewVMContext(ledgerService.Ledger), &types.StateBlock{}, sendBlock); err == nil {
+		return nil
+	} else if contract.IsNEP5TxUnverified(err) {
+		return err
+	}
+
+	client, err := rPCService.RPC().Attach()
+	if err != nil {
+		fmt.Println("create rpc client error:", err)
+		return err
+	}
+	defer func() {
+		if client != nil {
+			client.Close()
+		}
+	}()
+
+	var refundBlock types.StateBlock
+	if err := client.Call(&refundBlock, "contract_getRefundBlock", sendBlock); err != nil {
+		return err
+	}
+	refundBlock.Signature = account.Sign(refundBlock.GetHash())
+	var w types.Work
+	worker, _ := types.NewWorker(w, refundBlock.Root())
+	refundBlock.Work = worker.NewWork()
+	fmt.Println(util.ToIndentString(&refundBlock))
+
+	var h types.Hash
+	err = client.Call(&h, "ledger_process", &refundBlock)
+	if err != nil {
+		fmt.Println(util.ToString(&refundBlock))
+		fmt.Println("process block error: ", err)
+		return err
+	}
+
+	return nil
+}
+
 func initDb() error {
 	relation := sqliteService.Relation
 	c, err := relation.BlocksCount()
@@ -99,6 +99,7 @@ var (
         }`
 
 	//main net
+	networkID           = uint32(1)
 	chainToken, _       = types.NewHash("18ceb6779a31caa2948323ef1a57ec59ee5a182939761ae101f5c4e6163efa1a")
 	genesisAddress, _   = types.HexToAddress("qlc_33nsrz5kqojh6j4pt34bwpgdgfan13ehudwos9wi1obshtuhti1z7wodfb7p")
 	genesisMintageBlock types.StateBlock
@@ -127,6 +128,28 @@ func init() {
 	gasBlockHash = gasBlock.GetHash()
 }
 
+//setGenesis replaces the genesis, g has been verified
+func setGenesis(g *Genesis) {
+	networkID = g.NetworkID
+	chainToken = g.Chain.Genesis.Token
+	genesisAddress = g.Chain.Genesis.Address
+	genesisMintageBlock = g.Chain.Mintage
+	genesisMintageHash = genesisMintageBlock.GetHash()
+	genesisBlock = g.Chain.Genesis
+	genesisBlockHash = genesisBlock.GetHash()
+
+	gasToken = g.Gas.Genesis.Token
+	gasAddress = g.Gas.Genesis.Address
+	gasMintageBlock = g.Gas.Mintage
+	gasMintageHash = gasMintageBlock.GetHash()
+	gasBlock = g.Gas.Genesis
+	gasBlockHash = gasBlock.GetHash()
+}
+
+func NetworkID() uint32 {
+	return networkID
+}
+
 func GenesisAddress() types.Address {
 	return genesisAddress
 }
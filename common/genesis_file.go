@@ -0,0 +1,121 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/qlcchain/go-qlc/common/types"
+)
+
+var ErrInvalidGenesis = errors.New("invalid genesis")
+
+// Genesis is the genesis of a network, a private network is started from a genesis file which holds it as JSON
+//
+//	{
+//	  "networkId": 100,
+//	  "chain": {"token": {...}, "mintage": {...}, "genesis": {...}},
+//	  "gas": {"token": {...}, "mintage": {...}, "genesis": {...}}
+//	}
+//
+// chain is the chain token which is used for voting, gas is the gas token.
+type Genesis struct {
+	NetworkID uint32       `json:"networkId"`
+	Chain     GenesisToken `json:"chain"`
+	Gas       GenesisToken `json:"gas"`
+}
+
+// GenesisToken is a token created by the genesis, Mintage is the ContractSend block to the mintage contract and
+// Genesis is the ContractReward block which receives the total supply, both are signed by the genesis account.
+// Token is the metadata of the token, it is the token info packed in the data of the Genesis block.
+type GenesisToken struct {
+	Token   *types.TokenInfo `json:"token"`
+	Mintage types.StateBlock `json:"mintage"`
+	Genesis types.StateBlock `json:"genesis"`
+}
+
+// LoadGenesis reads and verifies the genesis file
+func LoadGenesis(file string) (*Genesis, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	g := new(Genesis)
+	if err := json.Unmarshal(content, g); err != nil {
+		return nil, fmt.Errorf("%s: %s", ErrInvalidGenesis, err)
+	}
+	if err := g.Verify(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// CurrentGenesis returns the genesis the node is running with
+func CurrentGenesis() *Genesis {
+	return &Genesis{
+		NetworkID: NetworkID(),
+		Chain:     GenesisToken{Mintage: GenesisMintageBlock(), Genesis: GenesisBlock()},
+		Gas:       GenesisToken{Mintage: GasMintageBlock(), Genesis: GasBlock()},
+	}
+}
+
+// SetGenesis replaces the built-in genesis, it must be called before the ledger is opened
+func SetGenesis(g *Genesis) error {
+	if err := g.Verify(); err != nil {
+		return err
+	}
+	setGenesis(g)
+	return nil
+}
+
+// Verify checks the genesis blocks of both tokens
+func (g *Genesis) Verify() error {
+	if g.NetworkID == 0 {
+		return fmt.Errorf("%s: network id is zero", ErrInvalidGenesis)
+	}
+	if err := g.Chain.verify(); err != nil {
+		return fmt.Errorf("%s: chain token, %s", ErrInvalidGenesis, err)
+	}
+	if err := g.Gas.verify(); err != nil {
+		return fmt.Errorf("%s: gas token, %s", ErrInvalidGenesis, err)
+	}
+	if g.Chain.Genesis.Token == g.Gas.Genesis.Token {
+		return fmt.Errorf("%s: chain token and gas token are the same", ErrInvalidGenesis)
+	}
+	return nil
+}
+
+func (t *GenesisToken) verify() error {
+	mintage, genesis := &t.Mintage, &t.Genesis
+	if mintage.Type != types.ContractSend || mintage.Address != types.MintageAddress {
+		return errors.New("mintage block is not sent by the mintage contract")
+	}
+	if genesis.Type != types.ContractReward || genesis.Link != mintage.GetHash() {
+		return errors.New("genesis block does not receive the mintage block")
+	}
+	if genesis.Address != types.Address(mintage.Link) || genesis.Token != mintage.Token {
+		return errors.New("mintage block does not match the genesis block")
+	}
+	if genesis.Balance.Int == nil || genesis.Balance.Sign() <= 0 {
+		return errors.New("invalid total supply")
+	}
+	for _, b := range []*types.StateBlock{mintage, genesis} {
+		hash := b.GetHash()
+		if !genesis.Address.Verify(hash[:], b.Signature[:]) {
+			return fmt.Errorf("bad signature of block %s", hash)
+		}
+	}
+	if t.Token != nil && (t.Token.TokenId != genesis.Token || t.Token.Owner != genesis.Address ||
+		t.Token.TotalSupply == nil || t.Token.TotalSupply.Cmp(genesis.Balance.Int) != 0) {
+		return errors.New("token info does not match the genesis block")
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package common
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qlcchain/go-qlc/common/types"
+)
+
+func testGenesisToken(t *testing.T, account *types.Account, token types.Hash, supply int64) GenesisToken {
+	mintage := types.StateBlock{
+		Type:           types.ContractSend,
+		Token:          token,
+		Address:        types.MintageAddress,
+		Balance:        types.ZeroBalance,
+		Vote:           types.ZeroBalance,
+		Network:        types.ZeroBalance,
+		Storage:        types.ZeroBalance,
+		Oracle:         types.ZeroBalance,
+		Link:           types.Hash(account.Address()),
+		Representative: account.Address(),
+		Timestamp:      1553990401,
+	}
+	mintage.Signature = account.Sign(mintage.GetHash())
+	genesis := types.StateBlock{
+		Type:           types.ContractReward,
+		Token:          token,
+		Address:        account.Address(),
+		Balance:        types.Balance{Int: big.NewInt(supply)},
+		Vote:           types.ZeroBalance,
+		Network:        types.ZeroBalance,
+		Storage:        types.ZeroBalance,
+		Oracle:         types.ZeroBalance,
+		Link:           mintage.GetHash(),
+		Representative: account.Address(),
+		Timestamp:      1553990410,
+	}
+	genesis.Signature = account.Sign(genesis.GetHash())
+	return GenesisToken{Mintage: mintage, Genesis: genesis}
+}
+
+func testGenesis(t *testing.T) *Genesis {
+	seed, err := types.NewSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a1, _ := seed.Account(0)
+	a2, _ := seed.Account(1)
+	return &Genesis{
+		NetworkID: 100,
+		Chain:     testGenesisToken(t, a1, types.HashData([]byte("chain")), 60000000000000000),
+		Gas:       testGenesisToken(t, a2, types.HashData([]byte("gas")), 10000000000000000),
+	}
+}
+
+func TestGenesis_Verify(t *testing.T) {
+	if err := CurrentGenesis().Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	g := testGenesis(t)
+	if err := g.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	g.Chain.Genesis.Balance = types.Balance{Int: big.NewInt(1)}
+	if err := g.Verify(); err == nil {
+		t.Fatal("genesis with a bad signature should be rejected")
+	}
+	g = testGenesis(t)
+	g.Gas = g.Chain
+	if err := g.Verify(); err == nil {
+		t.Fatal("genesis with the same tokens should be rejected")
+	}
+}
+
+func TestSetGenesis(t *testing.T) {
+	current := CurrentGenesis()
+	defer func() {
+		if err := SetGenesis(current); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	g := testGenesis(t)
+	dir, err := ioutil.TempDir("", "genesis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "genesis.json")
+	content, _ := json.Marshal(g)
+	if err := ioutil.WriteFile(file, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadGenesis(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetGenesis(loaded); err != nil {
+		t.Fatal(err)
+	}
+	if NetworkID() != 100 || ChainToken() != g.Chain.Genesis.Token || GasToken() != g.Gas.Genesis.Token {
+		t.Fatal("invalid genesis token")
+	}
+	if GenesisBlockHash() != g.Chain.Genesis.GetHash() || GenesisAddress() != g.Chain.Genesis.Address {
+		t.Fatal("invalid genesis block")
+	}
+	blk := g.Gas.Mintage
+	if !IsGenesisBlock(&blk) || !IsGenesisToken(g.Gas.Genesis.Token) {
+		t.Fatal("invalid gas genesis")
+	}
+}
@@ -101,6 +101,7 @@ var (
         }`
 
 	//test net
+	testNetworkID           = uint32(2)
 	testChainToken, _       = types.NewHash("3339a985301a9ba7c35e1e15b78f306f9cdb03676436d013218099a9007714e1")
 	testGenesisAddress, _   = types.HexToAddress("qlc_3mtg5qax7a9t6zfdjxp5shbshwx17n6x5ujqhjnec1c9ssrwarbjhncxqbrd")
 	testGenesisMintageBlock types.StateBlock
@@ -129,6 +130,28 @@ func init() {
 	testGasBlockHash = testGasBlock.GetHash()
 }
 
+//setGenesis replaces the genesis, g has been verified
+func setGenesis(g *Genesis) {
+	testNetworkID = g.NetworkID
+	testChainToken = g.Chain.Genesis.Token
+	testGenesisAddress = g.Chain.Genesis.Address
+	testGenesisMintageBlock = g.Chain.Mintage
+	testGenesisMintageHash = testGenesisMintageBlock.GetHash()
+	testGenesisBlock = g.Chain.Genesis
+	testGenesisBlockHash = testGenesisBlock.GetHash()
+
+	testGasToken = g.Gas.Genesis.Token
+	testGasAddress = g.Gas.Genesis.Address
+	testGasMintageBlock = g.Gas.Mintage
+	testGasMintageHash = testGasMintageBlock.GetHash()
+	testGasBlock = g.Gas.Genesis
+	testGasBlockHash = testGasBlock.GetHash()
+}
+
+func NetworkID() uint32 {
+	return testNetworkID
+}
+
 func GenesisAddress() types.Address {
 	return testGenesisAddress
 }
@@ -0,0 +1,399 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ContentType is the content type of the prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefBuckets are the default histogram buckets in seconds, they fit the latency of a local call
+var DefBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Collector is a metric family which can be exposed by a Registry
+type Collector interface {
+	Name() string
+	write(w io.Writer)
+}
+
+// Registry holds the metric families exposed by the metrics endpoint, a family registered with the name of an
+// existing one replaces it
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]Collector
+}
+
+// DefaultRegistry is the registry the metrics of the node are registered to
+var DefaultRegistry = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]Collector)}
+}
+
+func (r *Registry) Register(c Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors[c.Name()] = c
+}
+
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.collectors, name)
+}
+
+// WriteText writes all metric families sorted by name in the prometheus text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	collectors := make([]Collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.RUnlock()
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].Name() < collectors[j].Name()
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics of the registry, it is the handler of the /metrics endpoint
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", ContentType)
+	_ = r.WriteText(w)
+}
+
+// Register registers the collector to the DefaultRegistry
+func Register(c Collector) {
+	DefaultRegistry.Register(c)
+}
+
+// Unregister removes the metric family from the DefaultRegistry
+func Unregister(name string) {
+	DefaultRegistry.Unregister(name)
+}
+
+// Handler returns the handler serving the DefaultRegistry
+func Handler() http.Handler {
+	return DefaultRegistry
+}
+
+type desc struct {
+	name string
+	help string
+}
+
+func (d *desc) Name() string {
+	return d.name
+}
+
+func (d *desc) writeHeader(w io.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, kind)
+}
+
+// Counter is a monotonically increasing value
+type Counter struct {
+	desc
+	value uint64
+}
+
+// NewCounter creates a counter and registers it to the DefaultRegistry
+func NewCounter(name, help string) *Counter {
+	c := &Counter{desc: desc{name: name, help: help}}
+	Register(c)
+	return c
+}
+
+func (c *Counter) Inc() {
+	atomic.AddUint64(&c.value, 1)
+}
+
+func (c *Counter) Add(n uint64) {
+	atomic.AddUint64(&c.value, n)
+}
+
+func (c *Counter) Value() uint64 {
+	return atomic.LoadUint64(&c.value)
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.writeHeader(w, "counter")
+	fmt.Fprintf(w, "%s %d\n", c.name, c.Value())
+}
+
+// CounterVec is a family of counters partitioned by the value of a label
+type CounterVec struct {
+	desc
+	label  string
+	mu     sync.RWMutex
+	values map[string]*uint64
+}
+
+// NewCounterVec creates a counter family and registers it to the DefaultRegistry
+func NewCounterVec(name, help, label string) *CounterVec {
+	c := &CounterVec{desc: desc{name: name, help: help}, label: label, values: make(map[string]*uint64)}
+	Register(c)
+	return c
+}
+
+func (c *CounterVec) Inc(value string) {
+	c.Add(value, 1)
+}
+
+func (c *CounterVec) Add(value string, n uint64) {
+	c.mu.RLock()
+	v, ok := c.values[value]
+	c.mu.RUnlock()
+	if !ok {
+		c.mu.Lock()
+		if v, ok = c.values[value]; !ok {
+			v = new(uint64)
+			c.values[value] = v
+		}
+		c.mu.Unlock()
+	}
+	atomic.AddUint64(v, n)
+}
+
+func (c *CounterVec) Value(value string) uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if v, ok := c.values[value]; ok {
+		return atomic.LoadUint64(v)
+	}
+	return 0
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.writeHeader(w, "counter")
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, value := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s{%s} %d\n", c.name, labelPair(c.label, value), atomic.LoadUint64(c.values[value]))
+	}
+}
+
+// GaugeFunc is a value which is read by fn every time the metrics are collected
+type GaugeFunc struct {
+	desc
+	fn func() float64
+}
+
+// NewGaugeFunc creates a gauge and registers it to the DefaultRegistry, the gauges which read the state of a
+// service are registered when the service is started and unregistered when it is stopped
+func NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	g := &GaugeFunc{desc: desc{name: name, help: help}, fn: fn}
+	Register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w io.Writer) {
+	g.writeHeader(w, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.name, formatFloat(g.fn()))
+}
+
+// GaugeVecFunc is a family of gauges partitioned by the value of a label, fn returns the gauges by the label value
+type GaugeVecFunc struct {
+	desc
+	label string
+	fn    func() map[string]float64
+}
+
+// NewGaugeVecFunc creates a gauge family and registers it to the DefaultRegistry
+func NewGaugeVecFunc(name, help, label string, fn func() map[string]float64) *GaugeVecFunc {
+	g := &GaugeVecFunc{desc: desc{name: name, help: help}, label: label, fn: fn}
+	Register(g)
+	return g
+}
+
+func (g *GaugeVecFunc) write(w io.Writer) {
+	g.writeHeader(w, "gauge")
+	values := g.fn()
+	for _, value := range sortedKeys(values) {
+		fmt.Fprintf(w, "%s{%s} %s\n", g.name, labelPair(g.label, value), formatFloat(values[value]))
+	}
+}
+
+// Histogram counts the observations in cumulative buckets, the buckets are the upper bounds sorted ascending
+type Histogram struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+// NewHistogram creates a histogram and registers it to the DefaultRegistry
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	h := newHistogram(name, help, buckets)
+	Register(h)
+	return h
+}
+
+func newHistogram(name, help string, buckets []float64) *Histogram {
+	return &Histogram{desc: desc{name: name, help: help}, buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+}
+
+// Count returns the count and the sum of the observations
+func (h *Histogram) Count() (uint64, float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count, h.sum
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.writeHeader(w, "histogram")
+	h.writeSamples(w, "")
+}
+
+// writeSamples writes the buckets, the sum and the count of the histogram, labels are put before the le label
+func (h *Histogram) writeSamples(w io.Writer, labels string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var cumulative uint64
+	for i, b := range h.buckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket{%s} %d\n", h.name, labels+labelPair("le", formatFloat(b)), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{%s} %d\n", h.name, labels+labelPair("le", "+Inf"), h.count)
+	if labels == "" {
+		fmt.Fprintf(w, "%s_sum %s\n", h.name, formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count %d\n", h.name, h.count)
+	} else {
+		labels = strings.TrimSuffix(labels, ",")
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", h.name, labels, formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", h.name, labels, h.count)
+	}
+}
+
+// HistogramVec is a family of histograms partitioned by the value of a label
+type HistogramVec struct {
+	desc
+	label      string
+	buckets    []float64
+	mu         sync.RWMutex
+	histograms map[string]*Histogram
+}
+
+// NewHistogramVec creates a histogram family and registers it to the DefaultRegistry
+func NewHistogramVec(name, help, label string, buckets []float64) *HistogramVec {
+	h := &HistogramVec{desc: desc{name: name, help: help}, label: label, buckets: buckets,
+		histograms: make(map[string]*Histogram)}
+	Register(h)
+	return h
+}
+
+func (h *HistogramVec) Observe(value string, v float64) {
+	h.With(value).Observe(v)
+}
+
+// With returns the histogram of the label value, it is created at the first use
+func (h *HistogramVec) With(value string) *Histogram {
+	h.mu.RLock()
+	hg, ok := h.histograms[value]
+	h.mu.RUnlock()
+	if ok {
+		return hg
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if hg, ok = h.histograms[value]; !ok {
+		hg = newHistogram(h.name, h.help, h.buckets)
+		h.histograms[value] = hg
+	}
+	return hg
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.writeHeader(w, "histogram")
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, value := range sortedKeys(h.histograms) {
+		h.histograms[value].writeSamples(w, labelPair(h.label, value)+",")
+	}
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch v := m.(type) {
+	case map[string]*uint64:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]float64:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]*Histogram:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func labelPair(label, value string) string {
+	return label + `="` + escapeLabel(value) + `"`
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelReplacer.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
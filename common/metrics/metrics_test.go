@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := NewRegistry()
+
+	c := NewCounter("test_total", "count of the tests")
+	c.Inc()
+	c.Add(2)
+	r.Register(c)
+
+	cv := NewCounterVec("test_requests_total", "requests by \"method\"", "method")
+	cv.Inc("b")
+	cv.Inc("a")
+	cv.Inc("a")
+	r.Register(cv)
+
+	r.Register(NewGaugeFunc("test_peers", "peers\nconnected", func() float64 { return 3 }))
+	r.Register(NewGaugeVecFunc("test_queue", "queue depth", "queue", func() map[string]float64 {
+		return map[string]float64{"x\"y": 1.5}
+	}))
+
+	h := NewHistogram("test_seconds", "latency", []float64{0.1, 1})
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(2)
+	r.Register(h)
+
+	hv := NewHistogramVec("test_method_seconds", "latency by method", "method", []float64{1})
+	hv.Observe("m", 0.5)
+	r.Register(hv)
+
+	defer func() {
+		for _, name := range []string{"test_total", "test_requests_total", "test_peers", "test_queue", "test_seconds",
+			"test_method_seconds"} {
+			Unregister(name)
+		}
+	}()
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expect := `# HELP test_method_seconds latency by method
+# TYPE test_method_seconds histogram
+test_method_seconds_bucket{method="m",le="1"} 1
+test_method_seconds_bucket{method="m",le="+Inf"} 1
+test_method_seconds_sum{method="m"} 0.5
+test_method_seconds_count{method="m"} 1
+# HELP test_peers peers\nconnected
+# TYPE test_peers gauge
+test_peers 3
+# HELP test_queue queue depth
+# TYPE test_queue gauge
+test_queue{queue="x\"y"} 1.5
+# HELP test_requests_total requests by "method"
+# TYPE test_requests_total counter
+test_requests_total{method="a"} 2
+test_requests_total{method="b"} 1
+# HELP test_seconds latency
+# TYPE test_seconds histogram
+test_seconds_bucket{le="0.1"} 1
+test_seconds_bucket{le="1"} 2
+test_seconds_bucket{le="+Inf"} 3
+test_seconds_sum 2.55
+test_seconds_count 3
+# HELP test_total count of the tests
+# TYPE test_total counter
+test_total 3
+`
+	if buf.String() != expect {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+
+	r.Unregister("test_total")
+	buf.Reset()
+	_ = r.WriteText(&buf)
+	if strings.Contains(buf.String(), "test_total ") {
+		t.Fatal("unregistered metric is exposed")
+	}
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	r.Register(NewGaugeFunc("test_http", "http gauge", func() float64 { return 1 }))
+	defer Unregister("test_http")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != ContentType {
+		t.Fatal(rec.Code, rec.Header())
+	}
+	if !strings.Contains(rec.Body.String(), "test_http 1\n") {
+		t.Fatal(rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatal(rec.Code)
+	}
+}
@@ -4,13 +4,21 @@ type TopicType string
 
 //Topic type
 const (
-	EventPublish        TopicType = "publish"
-	EventConfirmReq     TopicType = "confirmReq"
-	EventConfirmAck     TopicType = "confirmAck"
-	EventSyncBlock      TopicType = "syncBlock"
-	EventConfirmedBlock TopicType = "confirmedBlock"
-	EventBroadcast      TopicType = "broadcast"
-	EventSendMsgToPeers TopicType = "sendMsgToPeers"
-	EventAddRelation    TopicType = "addRelation"
-	EventDeleteRelation TopicType = "deleteRelation"
+	EventPublish         TopicType = "publish"
+	EventConfirmReq      TopicType = "confirmReq"
+	EventConfirmAck      TopicType = "confirmAck"
+	EventSyncBlock       TopicType = "syncBlock"
+	EventConfirmedBlock  TopicType = "confirmedBlock"
+	EventBroadcast       TopicType = "broadcast"
+	EventSendMsgToPeers  TopicType = "sendMsgToPeers"
+	EventAddRelation     TopicType = "addRelation"
+	EventDeleteRelation  TopicType = "deleteRelation"
+	EventAddVmLogs       TopicType = "addVmLogs"
+	EventPeersInfo       TopicType = "peersInfo"
+	EventConnectPeer     TopicType = "connectPeer"
+	EventDisconnectPeer  TopicType = "disconnectPeer"
+	EventPenalizePeer    TopicType = "penalizePeer"
+	EventDeliveryStats   TopicType = "deliveryStats"
+	EventActiveElections TopicType = "activeElections"
+	EventPublishContract TopicType = "publishContract"
 )
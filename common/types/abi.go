@@ -40,8 +40,8 @@ func (c *ContractAbi) MarshalBinaryTo(text []byte) error {
 	var bytes [8]byte
 	binary.BigEndian.PutUint64(bytes[:], c.AbiLength)
 	copy(text, bytes[:])
-	copy(text, c.AbiHash[:])
-	copy(text, c.Abi)
+	copy(text[8:], c.AbiHash[:])
+	copy(text[8+HashSize:], c.Abi)
 	return nil
 }
 
@@ -54,6 +54,7 @@ func (c *ContractAbi) UnmarshalBinary(text []byte) error {
 		return err
 	}
 
+	c.Abi = make([]byte, len(text)-8-HashSize)
 	copy(c.Abi, text[8+HashSize:])
 
 	return nil
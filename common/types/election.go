@@ -0,0 +1,38 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package types
+
+import (
+	"encoding/json"
+)
+
+// ElectionVote is the latest vote of a representative in an election
+type ElectionVote struct {
+	Representative Address `json:"representative"`
+	Hash           Hash    `json:"hash"`   //block hash the representative voted for
+	Weight         Balance `json:"weight"` //current weight of the representative, the tally is counted with it
+}
+
+// ElectionInfo is the state of an election between blocks which have the same previous block
+type ElectionInfo struct {
+	Root          Hash            `json:"root"` //previous block of the fork
+	Winner        Hash            `json:"winner"`
+	Losers        []Hash          `json:"losers"`
+	Tally         Balance         `json:"tally"`  //total weight voted for the winner
+	Quorum        Balance         `json:"quorum"` //weight the winner must exceed to be confirmed
+	Votes         []*ElectionVote `json:"votes"`
+	Announcements uint            `json:"announcements"`
+	Confirmed     bool            `json:"confirmed"`
+	ConfirmedTime int64           `json:"confirmedTime"` //unix time in seconds, zero if the election is still active
+}
+
+// String implements the fmt.Stringer interface.
+func (e *ElectionInfo) String() string {
+	b, _ := json.Marshal(e)
+	return string(b)
+}
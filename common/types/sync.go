@@ -0,0 +1,40 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package types
+
+// SyncRange is a range of accounts synced from one peer, the accounts whose open block is in [Start, End)
+type SyncRange struct {
+	Index   int    `json:"index"`
+	Start   Hash   `json:"start"`
+	End     Hash   `json:"end"`  //zero means the end of the hash space
+	Peer    string `json:"peer"` //peer the range is requested from, empty if the range is waiting for a peer
+	Done    bool   `json:"done"`
+	Retries int    `json:"retries"` //times the range was requested from a peer which did not answer
+}
+
+// SyncProgress is the progress of a sync round, a round is finished when all the ranges are done
+type SyncProgress struct {
+	StartTime int64        `json:"startTime"` //unix time in seconds
+	Ranges    []*SyncRange `json:"ranges"`
+}
+
+// DoneCount returns the count of the ranges which are done
+func (p *SyncProgress) DoneCount() int {
+	count := 0
+	for _, r := range p.Ranges {
+		if r.Done {
+			count++
+		}
+	}
+	return count
+}
+
+// Finished checks whether all the ranges are done
+func (p *SyncProgress) Finished() bool {
+	return p.DoneCount() == len(p.Ranges)
+}
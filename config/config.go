@@ -8,14 +8,14 @@ import (
 	ic "github.com/libp2p/go-libp2p-crypto"
 )
 
-type Config ConfigV3
+type Config ConfigV4
 
 func DefaultConfig(dir string) (*Config, error) {
-	v3, err := DefaultConfigV3(dir)
+	v4, err := DefaultConfigV4(dir)
 	if err != nil {
 		return &Config{}, err
 	}
-	cfg := Config(*v3)
+	cfg := Config(*v4)
 
 	return &cfg, nil
 }
@@ -44,6 +44,25 @@ func (c *Config) WalletDir() string {
 	return filepath.Join(c.DataDir, "wallet")
 }
 
+// GenesisFile returns the path of the genesis file, it is empty if the built-in genesis is used
+func (c *Config) GenesisFile() string {
+	if c.Genesis == "" || filepath.IsAbs(c.Genesis) {
+		return c.Genesis
+	}
+	return filepath.Join(c.DataDir, c.Genesis)
+}
+
+// NEP5File returns the path of the JSON file of the file NEP5 verifier
+func (c *Config) NEP5File() string {
+	if c.NEP5 == nil {
+		return ""
+	}
+	if c.NEP5.File == "" || filepath.IsAbs(c.NEP5.File) {
+		return c.NEP5.File
+	}
+	return filepath.Join(c.DataDir, c.NEP5.File)
+}
+
 func (c *Config) SqliteDir() string {
 	return filepath.Join(c.LedgerDir(), relationDir)
 }
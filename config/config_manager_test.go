@@ -148,7 +148,7 @@ func TestCfgManager_Load(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	cfg3, err := manager.Load(NewMigrationV1ToV2(), NewMigrationV2ToV3())
+	cfg3, err := manager.Load(NewMigrationV1ToV2(), NewMigrationV2ToV3(), NewMigrationV3ToV4())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -164,3 +164,38 @@ func TestCfgManager_Load(t *testing.T) {
 		t.Fatal("invalid HttpVirtualHosts")
 	}
 }
+
+func TestMigrationV3ToV4_Migration(t *testing.T) {
+	manager := NewCfgManager(cfgFile)
+	cfg, err := DefaultConfigV3(manager.cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bytes, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMigrationV3ToV4()
+	data, v4, err := m.Migration(bytes, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v4 != 4 {
+		t.Fatal("invalid version")
+	}
+
+	var cfg4 ConfigV4
+	err = json.Unmarshal(data, &cfg4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg4.Consensus == nil || cfg4.Consensus.QuorumPercent != 50 || cfg4.Wallet == nil ||
+		cfg4.Pruning == nil || cfg4.Pruning.Enabled || cfg4.REST == nil || cfg4.REST.Enabled ||
+		cfg4.Metrics == nil || cfg4.Metrics.Enabled || cfg4.NEP5 == nil || cfg4.NEP5.Verifier != "" {
+		t.Fatal("migration consensus error")
+	}
+	if cfg4.DB == nil || cfg4.DataDir != cfg.DataDir {
+		t.Fatal("migration failed.")
+	}
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package config
+
+type ConfigV4 struct {
+	ConfigV3  `mapstructure:",squash"`
+	Consensus *ConsensusConfig `json:"consensus"`
+	Wallet    *WalletConfig    `json:"wallet"`
+	Pruning   *PruningConfig   `json:"pruning"`
+	REST      *RESTConfig      `json:"rest"`
+	Metrics   *MetricsConfig   `json:"metrics"`
+	NEP5      *NEP5Config      `json:"nep5"`
+	// genesis file of a private network, relative to the data dir, the built-in genesis is used if it is empty
+	Genesis string `json:"genesis"`
+}
+
+type ConsensusConfig struct {
+	// percentage of the online representative weight a block needs to be confirmed
+	QuorumPercent int `json:"quorumPercent"`
+	// minimum online weight in raw, used as the quorum base when fewer representatives are seen online
+	MinOnlineWeight string `json:"minOnlineWeight"`
+}
+
+type WalletConfig struct {
+	// number of consecutive unused accounts after which account discovery stops
+	GapLimit int `json:"gapLimit"`
+}
+
+type PruningConfig struct {
+	// prune the history of the accounts, a pruned node only keeps the latest blocks of every account and does not
+	// serve the full history to the other peers
+	Enabled bool `json:"enabled"`
+	// number of the latest blocks of every account chain which are kept for rollback
+	Depth int `json:"depth"`
+	// interval of the pruning job in seconds
+	Interval int `json:"interval"`
+}
+
+type RESTConfig struct {
+	// serve the block explorer REST api, it is started with the rpc service
+	Enabled  bool     `json:"enabled"`
+	Endpoint string   `json:"endpoint"`
+	Cors     []string `json:"cors"`
+}
+
+type MetricsConfig struct {
+	// serve the metrics of the node in the prometheus text format on the /metrics path of the endpoint
+	Enabled  bool   `json:"enabled"`
+	Endpoint string `json:"endpoint"`
+}
+
+type NEP5Config struct {
+	// verifier of the NEP5 lock transactions referenced by the mintage and the pledge contracts, "rpc" queries a
+	// JSON-RPC service, "file" reads them from a JSON file, nothing is verified if it is empty
+	Verifier string `json:"verifier"`
+	// JSON-RPC endpoint and method of the rpc verifier, the method takes the tx id and returns the lock transaction
+	Endpoint string `json:"endpoint"`
+	Method   string `json:"method"`
+	// JSON file of the file verifier, relative to the data dir
+	File string `json:"file"`
+}
+
+func DefaultConfigV4(dir string) (*ConfigV4, error) {
+	var cfg ConfigV4
+	cfg3, _ := DefaultConfigV3(dir)
+	cfg.ConfigV3 = *cfg3
+	cfg.Version = 4
+	cfg.Consensus = defaultConsensus()
+	cfg.Wallet = defaultWallet()
+	cfg.Pruning = defaultPruning()
+	cfg.REST = defaultREST()
+	cfg.Metrics = defaultMetrics()
+	cfg.NEP5 = defaultNEP5()
+
+	return &cfg, nil
+}
+
+func defaultConsensus() *ConsensusConfig {
+	return &ConsensusConfig{
+		QuorumPercent:   50,
+		MinOnlineWeight: "6000000000000000",
+	}
+}
+
+func defaultWallet() *WalletConfig {
+	return &WalletConfig{
+		GapLimit: 20,
+	}
+}
+
+func defaultPruning() *PruningConfig {
+	return &PruningConfig{
+		Enabled:  false,
+		Depth:    64,
+		Interval: 600,
+	}
+}
+
+func defaultREST() *RESTConfig {
+	return &RESTConfig{
+		Enabled:  false,
+		Endpoint: "tcp4://0.0.0.0:29737",
+		Cors:     []string{"*"},
+	}
+}
+
+func defaultMetrics() *MetricsConfig {
+	return &MetricsConfig{
+		Enabled:  false,
+		Endpoint: "tcp4://0.0.0.0:29738",
+	}
+}
+
+func defaultNEP5() *NEP5Config {
+	return &NEP5Config{
+		Verifier: "",
+		Endpoint: "http://127.0.0.1:10332",
+		Method:   "nep5_getLockTransaction",
+		File:     "nep5.json",
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/qlcchain/go-qlc/p2p"
 
 	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/metrics"
 
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/p2p/protos"
@@ -41,6 +42,9 @@ func (act *ActiveTrx) SetDposService(dps *DPoS) {
 func (act *ActiveTrx) start() {
 	timer2 := time.NewTicker(announceIntervalSecond)
 	timer3 := time.NewTicker(refreshPriInterval)
+	metrics.NewGaugeFunc(activeElectionsMetric, "count of the elections in progress", func() float64 {
+		return float64(act.count())
+	})
 	for {
 		select {
 		case <-timer2.C:
@@ -135,6 +139,9 @@ func (act *ActiveTrx) announceVotes() {
 			act.inactive = append(act.inactive, value.(*Election).vote.id)
 			act.rollBack(value.(*Election).status.loser)
 			act.addWinner2Ledger(block)
+			if err := act.dps.ledger.AddElectionInfo(value.(*Election).info()); err != nil {
+				act.dps.logger.Errorf("save election of block [%s] error: %s", hash, err)
+			}
 		} else {
 			localRepAccount.Range(func(k, v interface{}) bool {
 				count++
@@ -175,7 +182,7 @@ func (act *ActiveTrx) announceVotes() {
 					}
 				}
 			}
-			value.(*Election).announcements++
+			value.(*Election).increaseAnnouncements()
 		}
 		if value.(*Election).announcements == announcementMax {
 			if _, ok := act.roots.Load(value); !ok {
@@ -193,6 +200,14 @@ func (act *ActiveTrx) announceVotes() {
 	act.inactive = act.inactive[:0:0]
 }
 
+// activeElections fills infos with the elections in progress, it is the callback of EventActiveElections
+func (act *ActiveTrx) activeElections(infos *[]*types.ElectionInfo) {
+	act.roots.Range(func(key, value interface{}) bool {
+		*infos = append(*infos, value.(*Election).info())
+		return true
+	})
+}
+
 func (act *ActiveTrx) addWinner2Ledger(block *types.StateBlock) {
 	hash := block.GetHash()
 	if exist, err := act.dps.ledger.HasStateBlock(hash); !exist && err == nil {
@@ -231,6 +246,17 @@ func (act *ActiveTrx) vote(va *protos.ConfirmAckBlock) {
 	}
 }
 
+// count returns the count of the elections in progress
+func (act *ActiveTrx) count() int {
+	n := 0
+	act.roots.Range(func(key, value interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
 func (act *ActiveTrx) stop() {
+	metrics.Unregister(activeElectionsMetric)
 	act.quitCh <- true
 }
@@ -19,6 +19,8 @@ import (
 type blockSource struct {
 	block     *types.StateBlock
 	blockFrom types.SynchronizedKind
+	// msgFrom is the peer which sent the block, it is empty for local blocks
+	msgFrom string
 }
 
 type BlockProcessor struct {
@@ -64,10 +66,14 @@ func (bp *BlockProcessor) processBlocks() {
 		//case <-timer1.C:
 		//	go bp.searchUncheckedCache()
 		case bs := <-bp.blocks:
+			start := time.Now()
 			result, err := bp.dp.verifier.Process(bs.block)
+			blockProcessSeconds.Observe(time.Since(start).Seconds())
 			if err != nil {
+				blocksProcessed.Inc("Error")
 				bp.dp.logger.Errorf("error: [%s] when verify block:[%s]", err, bs.block.GetHash())
 			} else {
+				blocksProcessed.Inc(result.String())
 				err = bp.processResult(result, bs)
 				if err != nil {
 					bp.dp.logger.Error(err)
@@ -123,8 +129,10 @@ func (bp *BlockProcessor) processResult(result process.ProcessResult, bs blockSo
 		bp.queueUnchecked(hash)
 	case process.BadSignature:
 		bp.dp.logger.Errorf("Bad signature for block: %s", hash)
+		bp.dp.penalizePeer(bs.msgFrom, p2p.BadBlock)
 	case process.BadWork:
 		bp.dp.logger.Errorf("Bad work for block: %s", hash)
+		bp.dp.penalizePeer(bs.msgFrom, p2p.BadBlock)
 	case process.BalanceMismatch:
 		bp.dp.logger.Errorf("Balance mismatch for block: %s", hash)
 	case process.Old:
@@ -102,6 +102,14 @@ func (dps *DPoS) Stop() error {
 	if err != nil {
 		return err
 	}
+	err = dps.eb.Unsubscribe(string(common.EventActiveElections), dps.acTrx.activeElections)
+	if err != nil {
+		return err
+	}
+	err = dps.eb.Unsubscribe(string(common.EventPublishContract), dps.ReceivePublishContract)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -143,6 +151,14 @@ func (dps *DPoS) setEvent() error {
 	if err != nil {
 		return err
 	}
+	err = dps.eb.Subscribe(string(common.EventActiveElections), dps.acTrx.activeElections)
+	if err != nil {
+		return err
+	}
+	err = dps.eb.SubscribeAsync(string(common.EventPublishContract), dps.ReceivePublishContract, false)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -151,6 +167,7 @@ func (dps *DPoS) ReceivePublish(blk *types.StateBlock, hash types.Hash, msgFrom
 	bs := blockSource{
 		block:     blk,
 		blockFrom: types.UnSynchronized,
+		msgFrom:   msgFrom,
 	}
 	dps.onReceivePublish(hash, bs, msgFrom)
 }
@@ -170,6 +187,28 @@ func (dps *DPoS) onReceivePublish(hash types.Hash, bs blockSource, msgFrom strin
 	}
 }
 
+// ReceivePublishContract saves the smart contract block published by a peer, a smart contract block is not voted,
+// it is relayed to the other peers once it is saved
+func (dps *DPoS) ReceivePublishContract(blk *types.SmartContractBlock, hash types.Hash, msgFrom string) {
+	dps.logger.Infof("receive publish smart contract block [%s] from [%s]", blk.GetHash(), msgFrom)
+	if dps.cache.Has(hash) {
+		return
+	}
+	if err := dps.cache.Set(hash, ""); err != nil {
+		dps.logger.Errorf("Set cache error [%s] for smart contract block [%s] with publish message", err, blk.GetHash())
+	}
+	result, err := dps.verifier.Process(blk)
+	if err != nil {
+		dps.logger.Errorf("process smart contract block [%s] error: %s", blk.GetHash(), err)
+		return
+	}
+	if result != process.Progress {
+		dps.logger.Infof("smart contract block [%s] is not saved: %s", blk.GetHash(), result.String())
+		return
+	}
+	dps.eb.Publish(string(common.EventSendMsgToPeers), p2p.PublishReq, blk, msgFrom)
+}
+
 func (dps *DPoS) ReceiveConfirmReq(blk *types.StateBlock, hash types.Hash, msgFrom string) {
 	//dps.logger.Infof("receive ConfirmReq block [%s] from [%s]", blk.GetHash(), msgFrom)
 	var address types.Address
@@ -177,6 +216,7 @@ func (dps *DPoS) ReceiveConfirmReq(blk *types.StateBlock, hash types.Hash, msgFr
 	bs := blockSource{
 		block:     blk,
 		blockFrom: types.UnSynchronized,
+		msgFrom:   msgFrom,
 	}
 	blkHash := bs.block.GetHash()
 	if !dps.cache.Has(hash) {
@@ -223,10 +263,12 @@ func (dps *DPoS) ReceiveConfirmAck(ack *protos.ConfirmAckBlock, hash types.Hash,
 	bs := blockSource{
 		block:     ack.Blk,
 		blockFrom: types.UnSynchronized,
+		msgFrom:   msgFrom,
 	}
 	blkHash := bs.block.GetHash()
 	valid := IsAckSignValidate(ack)
 	if !valid {
+		dps.penalizePeer(msgFrom, p2p.BadVote)
 		return
 	}
 
@@ -329,11 +371,12 @@ func (dps *DPoS) ReceiveConfirmAck(ack *protos.ConfirmAckBlock, hash types.Hash,
 	}
 }
 
-func (dps *DPoS) ReceiveSyncBlock(blk *types.StateBlock) {
+func (dps *DPoS) ReceiveSyncBlock(blk *types.StateBlock, msgFrom string) {
 	//	dps.logger.Info("Sync Event")
 	bs := blockSource{
 		block:     blk,
 		blockFrom: types.Synchronized,
+		msgFrom:   msgFrom,
 	}
 	dps.logger.Infof("Sync Event for block:[%s]", bs.block.GetHash())
 	hash := bs.block.GetHash()
@@ -343,6 +386,13 @@ func (dps *DPoS) ReceiveSyncBlock(blk *types.StateBlock) {
 	}
 }
 
+// penalizePeer reports the misbehavior of the peer which sent an invalid block or vote to the p2p layer
+func (dps *DPoS) penalizePeer(peerID string, m p2p.Misbehavior) {
+	if peerID != "" {
+		dps.eb.Publish(string(common.EventPenalizePeer), peerID, m)
+	}
+}
+
 func (dps *DPoS) sendConfirmAck(block *types.StateBlock, account types.Address, acc *types.Account) error {
 	va, err := dps.voteGenerate(block, account, acc)
 	if err != nil {
@@ -1,11 +1,18 @@
 package consensus
 
 import (
+	"math/big"
+	"sync"
+	"time"
+
 	"github.com/qlcchain/go-qlc/common"
 	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/config"
 	"github.com/qlcchain/go-qlc/p2p/protos"
 )
 
+const defaultQuorumPercent = 50
+
 type BlockReceivedVotes struct {
 	block   *types.StateBlock
 	balance types.Balance
@@ -23,6 +30,9 @@ type Election struct {
 	confirmed     bool
 	dps           *DPoS
 	announcements uint
+	confirmedTime int64
+	startTime     time.Time
+	lock          sync.RWMutex //guards status, confirmed and announcements for readers outside the consensus goroutines
 }
 
 func NewElection(dps *DPoS, block *types.StateBlock) (*Election, error) {
@@ -35,6 +45,7 @@ func NewElection(dps *DPoS, block *types.StateBlock) (*Election, error) {
 		confirmed:     false,
 		dps:           dps,
 		announcements: 0,
+		startTime:     time.Now(),
 	}, nil
 }
 
@@ -51,6 +62,8 @@ func (el *Election) voteAction(va *protos.ConfirmAckBlock) {
 }
 
 func (el *Election) haveQuorum() {
+	el.lock.Lock()
+	defer el.lock.Unlock()
 	t := el.tally()
 	if !(len(t) > 0) {
 		return
@@ -63,16 +76,7 @@ func (el *Election) haveQuorum() {
 			blk = value.block
 		}
 	}
-	//supply := el.getOnlineRepresentativesBalance()
-	supply := common.GenesisBlock().Balance
-	//supply, err := el.getGenesisBalance()
-	//if err != nil {
-	//	return
-	//}
-	b, err := supply.Div(2)
-	if err != nil {
-		return
-	}
+	b := el.quorum()
 	confirmedHash := blk.GetHash()
 	if balance.Compare(b) == types.BalanceCompBigger {
 		el.dps.logger.Infof("hash:%s block has confirmed,total vote is [%s]", confirmedHash, balance.String())
@@ -81,7 +85,11 @@ func (el *Election) haveQuorum() {
 			el.status.loser = append(el.status.loser, el.status.winner)
 		}
 		el.status.winner = blk
+		if !el.confirmed {
+			electionConfirmSeconds.Observe(time.Since(el.startTime).Seconds())
+		}
 		el.confirmed = true
+		el.confirmedTime = time.Now().Unix()
 		el.status.tally = balance
 		for _, value := range t {
 			if value.block.GetHash().String() != confirmedHash.String() {
@@ -111,17 +119,88 @@ func (el *Election) tally() map[types.Hash]*BlockReceivedVotes {
 	return totals
 }
 
+func (el *Election) quorum() types.Balance {
+	var cfg *config.ConsensusConfig
+	if el.dps.cfg != nil {
+		cfg = el.dps.cfg.Consensus
+	}
+	return quorumWeight(el.getOnlineRepresentativesBalance(), cfg)
+}
+
+// info returns the current state of the election with the latest vote of every representative
+func (el *Election) info() *types.ElectionInfo {
+	el.lock.RLock()
+	defer el.lock.RUnlock()
+	winner := el.status.winner.GetHash()
+	info := &types.ElectionInfo{
+		Root:          el.vote.id,
+		Winner:        winner,
+		Losers:        make([]types.Hash, 0),
+		Tally:         types.ZeroBalance,
+		Quorum:        el.quorum(),
+		Votes:         make([]*types.ElectionVote, 0),
+		Announcements: el.announcements,
+		Confirmed:     el.confirmed,
+		ConfirmedTime: el.confirmedTime,
+	}
+	losers := make(map[types.Hash]bool)
+	for _, blk := range el.status.loser {
+		hash := blk.GetHash()
+		if hash != winner && !losers[hash] {
+			losers[hash] = true
+			info.Losers = append(info.Losers, hash)
+		}
+	}
+	el.vote.repVotes.Range(func(key, value interface{}) bool {
+		address := key.(types.Address)
+		hash := value.(*protos.ConfirmAckBlock).Blk.GetHash()
+		weight := el.dps.ledger.Weight(address)
+		info.Votes = append(info.Votes, &types.ElectionVote{Representative: address, Hash: hash, Weight: weight})
+		if hash == winner {
+			info.Tally = info.Tally.Add(weight)
+		}
+		return true
+	})
+	return info
+}
+
+func (el *Election) increaseAnnouncements() {
+	el.lock.Lock()
+	defer el.lock.Unlock()
+	el.announcements++
+}
+
 func (el *Election) getOnlineRepresentativesBalance() types.Balance {
 	b := types.ZeroBalance
 	reps := el.dps.GetOnlineRepresentatives()
 	for _, addr := range reps {
-		if b1, err := el.dps.ledger.GetRepresentation(addr); err != nil {
+		if b1, err := el.dps.ledger.GetRepresentation(addr); err == nil {
 			b = b.Add(b1.Total)
 		}
 	}
 	return b
 }
 
+// quorumWeight returns the weight a block must exceed to be confirmed, it is the configured percentage of the online
+// representative weight, and the online weight never counts less than the configured floor
+func quorumWeight(online types.Balance, cfg *config.ConsensusConfig) types.Balance {
+	percent := int64(defaultQuorumPercent)
+	floor := types.ZeroBalance
+	if cfg != nil {
+		if cfg.QuorumPercent > 0 && cfg.QuorumPercent <= 100 {
+			percent = int64(cfg.QuorumPercent)
+		}
+		if w, ok := new(big.Int).SetString(cfg.MinOnlineWeight, 10); ok {
+			floor = types.Balance{Int: w}
+		}
+	}
+	if online.Compare(floor) == types.BalanceCompSmaller {
+		online = floor
+	}
+	q := new(big.Int).Mul(online.Int, big.NewInt(percent))
+	return types.Balance{Int: q.Div(q, big.NewInt(100))}
+}
+
 func (el *Election) getGenesisBalance() (types.Balance, error) {
 	genesis := common.GenesisBlock()
 	return genesis.Balance, nil
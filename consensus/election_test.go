@@ -0,0 +1,81 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/config"
+	"github.com/qlcchain/go-qlc/ledger"
+	"github.com/qlcchain/go-qlc/ledger/db"
+	"github.com/qlcchain/go-qlc/log"
+	"github.com/qlcchain/go-qlc/p2p/protos"
+	"github.com/qlcchain/go-qlc/test/mock"
+)
+
+func TestQuorumWeight(t *testing.T) {
+	tests := []struct {
+		name   string
+		online types.Balance
+		cfg    *config.ConsensusConfig
+		want   types.Balance
+	}{
+		{"default", types.StringToBalance("1000"), nil, types.StringToBalance("500")},
+		{"percent", types.StringToBalance("1000"), &config.ConsensusConfig{QuorumPercent: 67}, types.StringToBalance("670")},
+		{"floor", types.StringToBalance("1000"), &config.ConsensusConfig{QuorumPercent: 50, MinOnlineWeight: "4000"}, types.StringToBalance("2000")},
+		{"above floor", types.StringToBalance("8000"), &config.ConsensusConfig{QuorumPercent: 50, MinOnlineWeight: "4000"}, types.StringToBalance("4000")},
+		{"invalid", types.StringToBalance("1000"), &config.ConsensusConfig{QuorumPercent: 101, MinOnlineWeight: "abc"}, types.StringToBalance("500")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quorumWeight(tt.online, tt.cfg); !got.Equal(tt.want) {
+				t.Fatalf("quorumWeight() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestElection_Info(t *testing.T) {
+	l := ledger.NewLedgerWithStore(db.NewMemoryStore())
+	defer l.Close()
+	dps := &DPoS{ledger: l, logger: log.NewLogger("consensus_test")}
+
+	blk := mock.StateBlockWithoutWork()
+	fork := mock.StateBlockWithoutWork()
+	fork.Previous = blk.Previous
+	el, err := NewElection(dps, blk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	el.vote.voteStatus(&protos.ConfirmAckBlock{Account: mock.Address(), Blk: blk})
+	el.vote.voteStatus(&protos.ConfirmAckBlock{Account: mock.Address(), Blk: fork})
+	el.status.loser = append(el.status.loser, fork, fork, blk)
+
+	info := el.info()
+	if info.Root != blk.Parent() || info.Winner != blk.GetHash() || info.Confirmed {
+		t.Fatal("invalid election info", info)
+	}
+	if len(info.Losers) != 1 || info.Losers[0] != fork.GetHash() {
+		t.Fatal("invalid losers", info.Losers)
+	}
+	if len(info.Votes) != 2 {
+		t.Fatal("invalid votes", info.Votes)
+	}
+}
+
+func TestActiveTrx_ActiveElections(t *testing.T) {
+	l := ledger.NewLedgerWithStore(db.NewMemoryStore())
+	defer l.Close()
+	dps := &DPoS{ledger: l, logger: log.NewLogger("consensus_test")}
+	act := NewActiveTrx()
+	act.SetDposService(dps)
+
+	blk := mock.StateBlockWithoutWork()
+	if !act.addToRoots(blk) {
+		t.Fatal("block should be added to roots")
+	}
+	infos := make([]*types.ElectionInfo, 0)
+	act.activeElections(&infos)
+	if len(infos) != 1 || infos[0].Winner != blk.GetHash() {
+		t.Fatal("invalid active elections", infos)
+	}
+}
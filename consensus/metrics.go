@@ -0,0 +1,17 @@
+package consensus
+
+import (
+	"github.com/qlcchain/go-qlc/common/metrics"
+)
+
+const activeElectionsMetric = "qlc_active_elections"
+
+var (
+	blocksProcessed = metrics.NewCounterVec("qlc_blocks_processed_total",
+		"count of the blocks processed by the block processor by the process result", "result")
+	blockProcessSeconds = metrics.NewHistogram("qlc_block_process_seconds",
+		"latency of the block processor verifying and saving a block", metrics.DefBuckets)
+	electionConfirmSeconds = metrics.NewHistogram("qlc_election_confirmation_seconds",
+		"time from the start of an election to the confirmation of its winner",
+		[]float64{.5, 1, 2, 4, 8, 16, 32, 64, 128, 256})
+)
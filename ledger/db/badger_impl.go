@@ -57,7 +57,7 @@ func (s *BadgerStore) Erase() error {
 	})
 }
 
-func (s *BadgerStore) NewTransaction(update bool) *BadgerStoreTxn {
+func (s *BadgerStore) NewTransaction(update bool) StoreTxn {
 	txn := &BadgerStoreTxn{txn: s.db.NewTransaction(update), db: s.db}
 	return txn
 }
@@ -72,6 +72,11 @@ func (s *BadgerStore) Purge() error {
 	return s.db.RunValueLogGC(0.5)
 }
 
+// Size returns the size of the LSM files and the value log files, badger refreshes them every minute
+func (s *BadgerStore) Size() (lsm, vlog int64) {
+	return s.db.Size()
+}
+
 func (s *BadgerStore) ViewInTx(fn func(txn StoreTxn) error) error {
 	return s.db.View(func(txn *badger.Txn) error {
 		return fn(&BadgerStoreTxn{txn: txn, db: s.db})
@@ -102,6 +107,9 @@ func (t *BadgerStoreTxn) SetWithMeta(key, val []byte, meta byte) error {
 func (t *BadgerStoreTxn) Get(key []byte, fn func([]byte, byte) error) error {
 	item, err := t.txn.Get(key)
 	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return ErrKeyNotFound
+		}
 		return err
 	}
 	err = item.Value(func(val []byte) error {
@@ -184,13 +192,17 @@ func (t *BadgerStoreTxn) Count(prefix []byte) (uint64, error) {
 	return i, nil
 }
 
-func (t *BadgerStoreTxn) Stream(prefix []byte, filter func(item *badger.Item) bool, callback func(list *pb.KVList) error) error {
+func (t *BadgerStoreTxn) Stream(prefix []byte, filter func(key []byte) bool, callback func(list []*KeyValue) error) error {
 	stream := t.db.NewStream()
 	stream.Prefix = prefix // Leave nil for iteration over the whole DB.
 	//stream.LogPrefix = "Badger.Streaming" // For identifying stream logs. Outputs to Logger.
 
 	// ChooseKey is called concurrently for every key. If left nil, assumes true by default.
-	stream.ChooseKey = filter
+	if filter != nil {
+		stream.ChooseKey = func(item *badger.Item) bool {
+			return filter(item.Key())
+		}
+	}
 
 	// KeyToList is called concurrently for chosen keys. This can be used to convert
 	// Badger data into custom key-values. If nil, uses stream.ToList, a default
@@ -200,7 +212,17 @@ func (t *BadgerStoreTxn) Stream(prefix []byte, filter func(item *badger.Item) bo
 	// -- End of optional settings.
 
 	// Send is called serially, while Stream.Orchestrate is running.
-	stream.Send = callback
+	stream.Send = func(list *pb.KVList) error {
+		kvs := make([]*KeyValue, 0, len(list.Kv))
+		for _, kv := range list.Kv {
+			var meta byte
+			if len(kv.UserMeta) > 0 {
+				meta = kv.UserMeta[0]
+			}
+			kvs = append(kvs, &KeyValue{Key: kv.Key, Value: kv.Value, Meta: meta})
+		}
+		return callback(kvs)
+	}
 
 	// Run the stream
 	if err := stream.Orchestrate(context.Background()); err != nil {
@@ -5,7 +5,6 @@ import (
 	"path/filepath"
 	"testing"
 
-	"github.com/dgraph-io/badger"
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/config"
 )
@@ -163,7 +162,7 @@ func TestBadgerStoreTxn_Drop(t *testing.T) {
 	err := txn.Get(key[:], func(bytes []byte, b byte) error {
 		return nil
 	})
-	if err != badger.ErrKeyNotFound {
+	if err != ErrKeyNotFound {
 		t.Fatal(err)
 	}
 
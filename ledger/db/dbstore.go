@@ -1,12 +1,13 @@
 package db
 
 import (
+	"errors"
 	"io"
-
-	"github.com/dgraph-io/badger"
-	"github.com/dgraph-io/badger/pb"
 )
 
+// ErrKeyNotFound is returned by StoreTxn.Get when the key does not exist, every backend must return it unwrapped
+var ErrKeyNotFound = errors.New("key not found")
+
 // Store is an interface that all stores need to implement.
 type Store interface {
 	io.Closer
@@ -14,7 +15,16 @@ type Store interface {
 	Erase() error
 	ViewInTx(fn func(txn StoreTxn) error) error
 	UpdateInTx(fn func(txn StoreTxn) error) error
-	NewTransaction(update bool) *BadgerStoreTxn
+	NewTransaction(update bool) StoreTxn
+	// Size returns the size in bytes of the LSM tree and the value log
+	Size() (lsm, vlog int64)
+}
+
+// KeyValue is a key-value pair delivered by StoreTxn.Stream
+type KeyValue struct {
+	Key   []byte
+	Value []byte
+	Meta  byte
 }
 
 type StoreTxn interface {
@@ -28,5 +38,7 @@ type StoreTxn interface {
 	Drop(prefix []byte) error
 	Upgrade(migrations []Migration) error
 	Count(prefix []byte) (uint64, error)
-	Stream(prefix []byte, filter func(item *badger.Item) bool, callback func(list *pb.KVList) error) error
+	// Stream walks committed keys with the prefix, filter chooses keys to deliver (nil chooses all)
+	// and callback receives the chosen key-value pairs in batches
+	Stream(prefix []byte, filter func(key []byte) bool, callback func(list []*KeyValue) error) error
 }
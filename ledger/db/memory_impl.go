@@ -0,0 +1,291 @@
+package db
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrReadOnlyTxn  = errors.New("no sets or deletes are allowed in a read-only transaction")
+	ErrDiscardedTxn = errors.New("this transaction has been discarded")
+)
+
+// MemoryStore represents a block lattice store backed by an ordered in-memory map,
+// nothing is written to disk and all data is lost when the store is closed.
+type MemoryStore struct {
+	lock  sync.RWMutex
+	keys  []string
+	items map[string]*memoryItem
+}
+
+type memoryItem struct {
+	value []byte
+	meta  byte
+}
+
+// MemoryStoreTxn buffers writes until commit, reads see the buffered writes on top of the committed data.
+type MemoryStoreTxn struct {
+	store     *MemoryStore
+	update    bool
+	discarded bool
+	writes    map[string]*memoryItem // nil item marks a deleted key
+}
+
+// NewMemoryStore initializes an empty in-memory store.
+func NewMemoryStore() Store {
+	return &MemoryStore{items: make(map[string]*memoryItem)}
+}
+
+func (s *MemoryStore) Erase() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.keys = nil
+	s.items = make(map[string]*memoryItem)
+	return nil
+}
+
+func (s *MemoryStore) NewTransaction(update bool) StoreTxn {
+	return &MemoryStoreTxn{store: s, update: update, writes: make(map[string]*memoryItem)}
+}
+
+// Close releases all data held by the store
+func (s *MemoryStore) Close() error {
+	return s.Erase()
+}
+
+// Purge is a no-op, deleted keys are released immediately.
+func (s *MemoryStore) Purge() error {
+	return nil
+}
+
+// Size returns the size of the keys and the values held by the store as the LSM size, there is no value log.
+func (s *MemoryStore) Size() (lsm, vlog int64) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for k, item := range s.items {
+		lsm += int64(len(k) + len(item.value) + 1)
+	}
+	return lsm, 0
+}
+
+func (s *MemoryStore) ViewInTx(fn func(txn StoreTxn) error) error {
+	txn := s.NewTransaction(false)
+	defer txn.Discard()
+	return fn(txn)
+}
+
+func (s *MemoryStore) UpdateInTx(fn func(txn StoreTxn) error) error {
+	txn := s.NewTransaction(true)
+	defer txn.Discard()
+	if err := fn(txn); err != nil {
+		return err
+	}
+	return txn.Commit(nil)
+}
+
+// get returns the committed item of the key, caller must hold the lock
+func (s *MemoryStore) get(key string) (*memoryItem, bool) {
+	item, ok := s.items[key]
+	return item, ok
+}
+
+// set inserts or replaces a committed item, caller must hold the write lock
+func (s *MemoryStore) set(key string, item *memoryItem) {
+	if _, ok := s.items[key]; !ok {
+		i := sort.SearchStrings(s.keys, key)
+		s.keys = append(s.keys, "")
+		copy(s.keys[i+1:], s.keys[i:])
+		s.keys[i] = key
+	}
+	s.items[key] = item
+}
+
+// delete removes a committed item, caller must hold the write lock
+func (s *MemoryStore) delete(key string) {
+	if _, ok := s.items[key]; !ok {
+		return
+	}
+	i := sort.SearchStrings(s.keys, key)
+	s.keys = append(s.keys[:i], s.keys[i+1:]...)
+	delete(s.items, key)
+}
+
+// prefixKeys returns the sorted committed keys with the prefix, caller must hold the lock
+func (s *MemoryStore) prefixKeys(prefix string) []string {
+	start := sort.SearchStrings(s.keys, prefix)
+	end := start
+	for end < len(s.keys) && strings.HasPrefix(s.keys[end], prefix) {
+		end++
+	}
+	keys := make([]string, end-start)
+	copy(keys, s.keys[start:end])
+	return keys
+}
+
+func (t *MemoryStoreTxn) Set(key []byte, val []byte) error {
+	return t.SetWithMeta(key, val, 0)
+}
+
+func (t *MemoryStoreTxn) SetWithMeta(key, val []byte, meta byte) error {
+	if err := t.checkWritable(); err != nil {
+		return err
+	}
+	t.writes[string(key)] = &memoryItem{value: copyBytes(val), meta: meta}
+	return nil
+}
+
+func (t *MemoryStoreTxn) Get(key []byte, fn func([]byte, byte) error) error {
+	if t.discarded {
+		return ErrDiscardedTxn
+	}
+	item, ok := t.writes[string(key)]
+	if !ok {
+		t.store.lock.RLock()
+		item, ok = t.store.get(string(key))
+		t.store.lock.RUnlock()
+	}
+	if !ok || item == nil {
+		return ErrKeyNotFound
+	}
+	return fn(item.value, item.meta)
+}
+
+func (t *MemoryStoreTxn) Delete(key []byte) error {
+	if err := t.checkWritable(); err != nil {
+		return err
+	}
+	t.writes[string(key)] = nil
+	return nil
+}
+
+func (t *MemoryStoreTxn) Iterator(pre byte, fn func([]byte, []byte, byte) error) error {
+	if t.discarded {
+		return ErrDiscardedTxn
+	}
+	prefix := string([]byte{pre})
+
+	t.store.lock.RLock()
+	keys := t.store.prefixKeys(prefix)
+	items := make(map[string]*memoryItem, len(keys))
+	for _, k := range keys {
+		items[k], _ = t.store.get(k)
+	}
+	t.store.lock.RUnlock()
+
+	for k, item := range t.writes {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		if _, ok := items[k]; !ok {
+			keys = append(keys, k)
+		}
+		items[k] = item
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		item := items[k]
+		if item == nil {
+			continue
+		}
+		if err := fn([]byte(k), item.value, item.meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *MemoryStoreTxn) Drop(prefix []byte) error {
+	t.store.lock.Lock()
+	defer t.store.lock.Unlock()
+	if prefix == nil {
+		t.store.keys = nil
+		t.store.items = make(map[string]*memoryItem)
+	} else {
+		for _, k := range t.store.prefixKeys(string(prefix)) {
+			t.store.delete(k)
+		}
+	}
+	return nil
+}
+
+func (t *MemoryStoreTxn) Upgrade(migrations []Migration) error {
+	sort.Sort(Migrations(migrations))
+	for _, m := range migrations {
+		err := m.Migrate(t)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *MemoryStoreTxn) Commit(callback func(error)) error {
+	if t.discarded {
+		return ErrDiscardedTxn
+	}
+	if t.update && len(t.writes) > 0 {
+		t.store.lock.Lock()
+		for k, item := range t.writes {
+			if item == nil {
+				t.store.delete(k)
+			} else {
+				t.store.set(k, item)
+			}
+		}
+		t.store.lock.Unlock()
+	}
+	t.Discard()
+	if callback != nil {
+		callback(nil)
+	}
+	return nil
+}
+
+func (t *MemoryStoreTxn) Discard() {
+	t.discarded = true
+	t.writes = nil
+}
+
+func (t *MemoryStoreTxn) Count(prefix []byte) (uint64, error) {
+	t.store.lock.RLock()
+	defer t.store.lock.RUnlock()
+	return uint64(len(t.store.prefixKeys(string(prefix)))), nil
+}
+
+func (t *MemoryStoreTxn) Stream(prefix []byte, filter func(key []byte) bool, callback func(list []*KeyValue) error) error {
+	t.store.lock.RLock()
+	var list []*KeyValue
+	for _, k := range t.store.prefixKeys(string(prefix)) {
+		key := []byte(k)
+		if filter != nil && !filter(key) {
+			continue
+		}
+		item, _ := t.store.get(k)
+		list = append(list, &KeyValue{Key: key, Value: copyBytes(item.value), Meta: item.meta})
+	}
+	t.store.lock.RUnlock()
+
+	if len(list) == 0 {
+		return nil
+	}
+	return callback(list)
+}
+
+func (t *MemoryStoreTxn) checkWritable() error {
+	if t.discarded {
+		return ErrDiscardedTxn
+	}
+	if !t.update {
+		return ErrReadOnlyTxn
+	}
+	return nil
+}
+
+func copyBytes(b []byte) []byte {
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
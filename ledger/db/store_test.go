@@ -0,0 +1,318 @@
+package db
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/qlcchain/go-qlc/config"
+)
+
+// storeConformance is the behaviour every Store backend must provide
+var storeConformance = []struct {
+	name string
+	fn   func(t *testing.T, store Store)
+}{
+	{"SetGet", testStoreSetGet},
+	{"KeyNotFound", testStoreKeyNotFound},
+	{"Delete", testStoreDelete},
+	{"Isolation", testStoreIsolation},
+	{"Discard", testStoreDiscard},
+	{"Iterator", testStoreIterator},
+	{"Count", testStoreCount},
+	{"Drop", testStoreDrop},
+	{"Stream", testStoreStream},
+	{"Upgrade", testStoreUpgrade},
+}
+
+func runStoreConformance(t *testing.T, newStore func(t *testing.T) (Store, func(t *testing.T))) {
+	for _, c := range storeConformance {
+		t.Run(c.name, func(t *testing.T) {
+			store, teardown := newStore(t)
+			defer teardown(t)
+			c.fn(t, store)
+		})
+	}
+}
+
+func TestBadgerStore_Conformance(t *testing.T) {
+	runStoreConformance(t, func(t *testing.T) (Store, func(t *testing.T)) {
+		dir := filepath.Join(config.QlcTestDataDir(), "store", uuid.New().String())
+		store, err := NewBadgerStore(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store, func(t *testing.T) {
+			if err := store.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.RemoveAll(dir); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestMemoryStore_Conformance(t *testing.T) {
+	runStoreConformance(t, func(t *testing.T) (Store, func(t *testing.T)) {
+		store := NewMemoryStore()
+		return store, func(t *testing.T) {
+			if err := store.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+}
+
+func putKeys(t *testing.T, store Store, kvs map[string]string) {
+	err := store.UpdateInTx(func(txn StoreTxn) error {
+		for k, v := range kvs {
+			if err := txn.Set([]byte(k), []byte(v)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func getKey(txn StoreTxn, key string) (string, byte, error) {
+	var val []byte
+	var meta byte
+	err := txn.Get([]byte(key), func(v []byte, m byte) error {
+		val = make([]byte, len(v))
+		copy(val, v)
+		meta = m
+		return nil
+	})
+	return string(val), meta, err
+}
+
+func testStoreSetGet(t *testing.T, store Store) {
+	err := store.UpdateInTx(func(txn StoreTxn) error {
+		if err := txn.Set([]byte("a"), []byte("1")); err != nil {
+			return err
+		}
+		return txn.SetWithMeta([]byte("b"), []byte("2"), 7)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.ViewInTx(func(txn StoreTxn) error {
+		if v, m, err := getKey(txn, "a"); err != nil || v != "1" || m != 0 {
+			t.Fatalf("get a: %s, %d, %v", v, m, err)
+		}
+		if v, m, err := getKey(txn, "b"); err != nil || v != "2" || m != 7 {
+			t.Fatalf("get b: %s, %d, %v", v, m, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testStoreKeyNotFound(t *testing.T, store Store) {
+	txn := store.NewTransaction(false)
+	defer txn.Discard()
+	if _, _, err := getKey(txn, "missing"); err != ErrKeyNotFound {
+		t.Fatal(err)
+	}
+}
+
+func testStoreDelete(t *testing.T, store Store) {
+	putKeys(t, store, map[string]string{"a": "1"})
+	txn := store.NewTransaction(true)
+	if err := txn.Delete([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := getKey(txn, "a"); err != ErrKeyNotFound {
+		t.Fatal("deleted key is visible in the txn", err)
+	}
+	if err := txn.Commit(nil); err != nil {
+		t.Fatal(err)
+	}
+	txn.Discard()
+
+	txn = store.NewTransaction(false)
+	defer txn.Discard()
+	if _, _, err := getKey(txn, "a"); err != ErrKeyNotFound {
+		t.Fatal("deleted key is visible after commit", err)
+	}
+}
+
+func testStoreIsolation(t *testing.T, store Store) {
+	txn := store.NewTransaction(true)
+	defer txn.Discard()
+	if err := txn.Set([]byte("a"), []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if v, _, err := getKey(txn, "a"); err != nil || v != "1" {
+		t.Fatal("txn can not read its own write", err)
+	}
+
+	other := store.NewTransaction(false)
+	if _, _, err := getKey(other, "a"); err != ErrKeyNotFound {
+		t.Fatal("uncommitted write is visible to other txn", err)
+	}
+	other.Discard()
+
+	if err := txn.Commit(nil); err != nil {
+		t.Fatal(err)
+	}
+	other = store.NewTransaction(false)
+	defer other.Discard()
+	if v, _, err := getKey(other, "a"); err != nil || v != "1" {
+		t.Fatal("committed write is invisible", err)
+	}
+}
+
+func testStoreDiscard(t *testing.T, store Store) {
+	txn := store.NewTransaction(true)
+	if err := txn.Set([]byte("a"), []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	txn.Discard()
+
+	txn = store.NewTransaction(false)
+	defer txn.Discard()
+	if _, _, err := getKey(txn, "a"); err != ErrKeyNotFound {
+		t.Fatal("discarded write is visible", err)
+	}
+}
+
+func testStoreIterator(t *testing.T, store Store) {
+	putKeys(t, store, map[string]string{"\x01c": "3", "\x01a": "1", "\x02a": "x"})
+
+	txn := store.NewTransaction(true)
+	defer txn.Discard()
+	if err := txn.Set([]byte("\x01b"), []byte("2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Delete([]byte("\x01c")); err != nil {
+		t.Fatal(err)
+	}
+
+	var keys, values []string
+	err := txn.Iterator(1, func(key []byte, val []byte, b byte) error {
+		keys = append(keys, string(key))
+		values = append(values, string(val))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0] != "\x01a" || keys[1] != "\x01b" || values[0] != "1" || values[1] != "2" {
+		t.Fatalf("invalid iterator result, keys: %q, values: %q", keys, values)
+	}
+}
+
+func testStoreCount(t *testing.T, store Store) {
+	putKeys(t, store, map[string]string{"\x01a": "1", "\x01b": "2", "\x02a": "3"})
+	txn := store.NewTransaction(false)
+	defer txn.Discard()
+	c, err := txn.Count([]byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != 2 {
+		t.Fatal("invalid count", c)
+	}
+}
+
+func testStoreDrop(t *testing.T, store Store) {
+	putKeys(t, store, map[string]string{"\x01a": "1", "\x01b": "2", "\x02a": "3"})
+
+	txn := store.NewTransaction(true)
+	if err := txn.Drop([]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	txn.Discard()
+
+	txn = store.NewTransaction(false)
+	if _, _, err := getKey(txn, "\x01a"); err != ErrKeyNotFound {
+		t.Fatal("dropped key is visible", err)
+	}
+	if v, _, err := getKey(txn, "\x02a"); err != nil || v != "3" {
+		t.Fatal("key out of prefix is dropped", err)
+	}
+	txn.Discard()
+
+	txn = store.NewTransaction(true)
+	if err := txn.Drop(nil); err != nil {
+		t.Fatal(err)
+	}
+	txn.Discard()
+
+	txn = store.NewTransaction(false)
+	defer txn.Discard()
+	if _, _, err := getKey(txn, "\x02a"); err != ErrKeyNotFound {
+		t.Fatal("dropped key is visible", err)
+	}
+}
+
+func testStoreStream(t *testing.T, store Store) {
+	putKeys(t, store, map[string]string{"\x01a": "1", "\x01b": "2", "\x01c": "3", "\x02a": "4"})
+	txn := store.NewTransaction(false)
+	defer txn.Discard()
+
+	result := make(map[string]string)
+	err := txn.Stream([]byte{1}, func(key []byte) bool {
+		return !bytes.Equal(key, []byte("\x01b"))
+	}, func(list []*KeyValue) error {
+		for _, kv := range list {
+			result[string(kv.Key)] = string(kv.Value)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result["\x01a"] != "1" || result["\x01c"] != "3" {
+		t.Fatalf("invalid stream result, %q", result)
+	}
+}
+
+type testMigration struct {
+	start, end int
+	applied    *[]int
+}
+
+func (m testMigration) Migrate(txn StoreTxn) error {
+	*m.applied = append(*m.applied, m.start)
+	return txn.Set([]byte("version"), []byte{byte(m.end)})
+}
+
+func (m testMigration) StartVersion() int {
+	return m.start
+}
+
+func (m testMigration) EndVersion() int {
+	return m.end
+}
+
+func testStoreUpgrade(t *testing.T, store Store) {
+	var applied []int
+	err := store.UpdateInTx(func(txn StoreTxn) error {
+		return txn.Upgrade([]Migration{testMigration{2, 3, &applied}, testMigration{1, 2, &applied}})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 2 || applied[0] != 1 || applied[1] != 2 {
+		t.Fatal("invalid migration order", applied)
+	}
+	err = store.ViewInTx(func(txn StoreTxn) error {
+		if v, _, err := getKey(txn, "version"); err != nil || v != "\x03" {
+			t.Fatal("invalid version", v, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
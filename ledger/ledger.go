@@ -11,8 +11,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/dgraph-io/badger"
-	"github.com/dgraph-io/badger/pb"
 	"github.com/qlcchain/go-qlc/common"
 	"github.com/qlcchain/go-qlc/common/event"
 	"github.com/qlcchain/go-qlc/common/types"
@@ -49,6 +47,7 @@ var (
 	ErrFrontierNotFound       = errors.New("frontier not found")
 	ErrRepresentationNotFound = errors.New("representation not found")
 	ErrPerformanceNotFound    = errors.New("performance not found")
+	ErrElectionNotFound       = errors.New("election not found")
 	//ErrChildExists            = errors.New("child already exists")
 	//ErrChildNotFound          = errors.New("child not found")
 	ErrVersionNotFound = errors.New("version not found")
@@ -74,6 +73,17 @@ const (
 	idPrefixMessage  //discard
 	idPrefixMessageInfo
 	idPrefixOnlineReps
+	idPrefixElection
+	idPrefixContractAddress
+	idPrefixBannedPeer
+	idPrefixSyncProgress
+	idPrefixPruned
+	idPrefixPrunedPending
+	idPrefixRefund
+	idPrefixReward
+	idPrefixVmLogs
+	idPrefixContractRoot
+	idPrefixContractRootPrevious
 )
 
 var (
@@ -81,7 +91,7 @@ var (
 	lock  = sync.RWMutex{}
 )
 
-const version = 5
+const version = 6
 
 func NewLedger(dir string) *Ledger {
 	lock.Lock()
@@ -103,6 +113,17 @@ func NewLedger(dir string) *Ledger {
 	return cache[dir]
 }
 
+//NewLedgerWithStore creates a ledger on top of the given store, the ledger is not cached by dir,
+//e.g. NewLedgerWithStore(db.NewMemoryStore()) for a ledger which never touches disk
+func NewLedgerWithStore(store db.Store) *Ledger {
+	l := &Ledger{Store: store, eb: event.GetEventBus("")}
+	l.logger = log.NewLogger("ledger")
+	if err := l.upgrade(); err != nil {
+		l.logger.Error(err)
+	}
+	return l
+}
+
 //CloseLedger force release all ledger instance
 func CloseLedger() {
 	for k, v := range cache {
@@ -136,7 +157,7 @@ func (l *Ledger) upgrade() error {
 				return err
 			}
 		}
-		ms := []db.Migration{new(MigrationV1ToV2), new(MigrationV2ToV3), new(MigrationV3ToV4), new(MigrationV4ToV5)}
+		ms := []db.Migration{new(MigrationV1ToV2), new(MigrationV2ToV3), new(MigrationV3ToV4), new(MigrationV4ToV5), new(MigrationV5ToV6)}
 		err = txn.Upgrade(ms)
 		if err != nil {
 			l.logger.Error(err)
@@ -185,7 +206,7 @@ func (l *Ledger) AddStateBlock(blk *types.StateBlock, txns ...db.StoreTxn) error
 	})
 	if err == nil {
 		return ErrBlockExists
-	} else if err != nil && err != badger.ErrKeyNotFound {
+	} else if err != nil && err != db.ErrKeyNotFound {
 		return err
 	}
 
@@ -202,9 +223,16 @@ func (l *Ledger) AddStateBlock(blk *types.StateBlock, txns ...db.StoreTxn) error
 	//if err := addToken(blk, txn); err != nil {
 	//	return err
 	//}
+	address, logs, err := l.contractLogs(blk, txn)
+	if err != nil {
+		return err
+	}
 	l.releaseTxn(txn, flag)
 	l.logger.Info("publish addRelation,", blk.GetHash())
 	l.eb.Publish(string(common.EventAddRelation), blk)
+	if logs != nil {
+		l.eb.Publish(string(common.EventAddVmLogs), address, blk, logs)
+	}
 	return nil
 }
 
@@ -230,7 +258,7 @@ func addChild(cBlock *types.StateBlock, txn db.StoreTxn) error {
 		err = txn.Get(pKey, func(val []byte, b byte) error {
 			return json.Unmarshal(val, &children)
 		})
-		if err != nil && err != badger.ErrKeyNotFound {
+		if err != nil && err != db.ErrKeyNotFound {
 			return err
 		}
 		if len(children) >= 2 {
@@ -273,7 +301,7 @@ func addChild(cBlock *types.StateBlock, txn db.StoreTxn) error {
 //		})
 //		if err == nil {
 //			return ErrTokenInfoExists
-//		} else if err != nil && err != badger.ErrKeyNotFound {
+//		} else if err != nil && err != db.ErrKeyNotFound {
 //			return err
 //		}
 //		val, err := json.Marshal(token)
@@ -297,7 +325,7 @@ func (l *Ledger) GetStateBlock(hash types.Hash, txns ...db.StoreTxn) (*types.Sta
 		return nil
 	})
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			return nil, ErrBlockNotFound
 		}
 		return nil, err
@@ -407,7 +435,7 @@ func (l *Ledger) HasStateBlock(hash types.Hash, txns ...db.StoreTxn) (bool, erro
 	})
 
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			return false, nil
 		}
 		return false, err
@@ -477,7 +505,7 @@ func (l *Ledger) AddSmartContractBlock(blk *types.SmartContractBlock, txns ...db
 	})
 	if err == nil {
 		return ErrBlockExists
-	} else if err != nil && err != badger.ErrKeyNotFound {
+	} else if err != nil && err != db.ErrKeyNotFound {
 		return err
 	}
 
@@ -485,7 +513,12 @@ func (l *Ledger) AddSmartContractBlock(blk *types.SmartContractBlock, txns ...db
 	if err != nil {
 		return err
 	}
-	return txn.Set(key, blockBytes)
+	if err := txn.Set(key, blockBytes); err != nil {
+		return err
+	}
+	//index the contract block by the contract address
+	hash := blk.GetHash()
+	return txn.Set(getKeyOfHash(blk.Address.ToHash(), idPrefixContractAddress), hash[:])
 }
 
 func (l *Ledger) GetSmartContractBlock(hash types.Hash, txns ...db.StoreTxn) (*types.SmartContractBlock, error) {
@@ -501,7 +534,7 @@ func (l *Ledger) GetSmartContractBlock(hash types.Hash, txns ...db.StoreTxn) (*t
 	})
 
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			return nil, ErrBlockNotFound
 		}
 		return nil, err
@@ -530,6 +563,25 @@ func (l *Ledger) GetSmartContractBlocks(fn func(block *types.SmartContractBlock)
 	return nil
 }
 
+// GetSmartContractBlockByAddress returns the smart contract block which deployed the contract of address
+func (l *Ledger) GetSmartContractBlockByAddress(address types.Address, txns ...db.StoreTxn) (*types.SmartContractBlock, error) {
+	key := getKeyOfHash(address.ToHash(), idPrefixContractAddress)
+	txn, flag := l.getTxn(false, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	var hash types.Hash
+	err := txn.Get(key, func(val []byte, b byte) error {
+		return hash.UnmarshalBinary(val)
+	})
+	if err != nil {
+		if err == db.ErrKeyNotFound {
+			return nil, ErrBlockNotFound
+		}
+		return nil, err
+	}
+	return l.GetSmartContractBlock(hash, txn)
+}
+
 func (l *Ledger) HasSmartContractBlock(hash types.Hash, txns ...db.StoreTxn) (bool, error) {
 	key := getKeyOfHash(hash, idPrefixSmartContractBlock)
 	txn, flag := l.getTxn(false, txns...)
@@ -540,7 +592,7 @@ func (l *Ledger) HasSmartContractBlock(hash types.Hash, txns ...db.StoreTxn) (bo
 	})
 
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			return false, nil
 		}
 		return false, err
@@ -588,7 +640,7 @@ func (l *Ledger) AddUncheckedBlock(parentHash types.Hash, blk *types.StateBlock,
 	})
 	if err == nil {
 		return ErrUncheckedBlockExists
-	} else if err != nil && err != badger.ErrKeyNotFound {
+	} else if err != nil && err != db.ErrKeyNotFound {
 		return err
 	}
 
@@ -611,7 +663,7 @@ func (l *Ledger) GetUncheckedBlock(parentHash types.Hash, kind types.UncheckedKi
 		return nil
 	})
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			return nil, 0, ErrUncheckedBlockNotFound
 		}
 		return nil, 0, err
@@ -636,7 +688,7 @@ func (l *Ledger) HasUncheckedBlock(hash types.Hash, kind types.UncheckedKind, tx
 		return nil
 	})
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			return false, nil
 		}
 		return false, err
@@ -714,7 +766,7 @@ func (l *Ledger) AddAccountMeta(meta *types.AccountMeta, txns ...db.StoreTxn) er
 	})
 	if err == nil {
 		return ErrAccountExists
-	} else if err != nil && err != badger.ErrKeyNotFound {
+	} else if err != nil && err != db.ErrKeyNotFound {
 		return err
 	}
 	return txn.Set(key, metaBytes)
@@ -735,7 +787,7 @@ func (l *Ledger) GetAccountMeta(address types.Address, txns ...db.StoreTxn) (*ty
 	})
 
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			return nil, ErrAccountNotFound
 		}
 		return nil, err
@@ -785,7 +837,7 @@ func (l *Ledger) UpdateAccountMeta(meta *types.AccountMeta, txns ...db.StoreTxn)
 		return nil
 	})
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			return ErrAccountNotFound
 		}
 		return err
@@ -823,7 +875,7 @@ func (l *Ledger) HasAccountMeta(address types.Address, txns ...db.StoreTxn) (boo
 	})
 
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			return false, nil
 		}
 		return false, err
@@ -1003,7 +1055,7 @@ func (l *Ledger) GetRepresentation(address types.Address, txns ...db.StoreTxn) (
 		return nil
 	})
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			return &types.Benefit{
 				Vote:    types.ZeroBalance,
 				Network: types.ZeroBalance,
@@ -1042,17 +1094,36 @@ func (l *Ledger) GetRepresentations(fn func(types.Address, *types.Benefit) error
 	return nil
 }
 
-func (l *Ledger) getPendingKey(pendingKey types.PendingKey) []byte {
-	//key := []byte{idPrefixPending}
-	//_, _ = pendingKey.MarshalMsg(key[1:])
-	//return key[:]
+//getPendingKey returns the key of a pending block, address first so all pending blocks of an address share a prefix
+func getPendingKey(pendingKey types.PendingKey) []byte {
+	var key [1 + types.AddressSize + types.HashSize]byte
+	key[0] = idPrefixPending
+	copy(key[1:], pendingKey.Address[:])
+	copy(key[1+types.AddressSize:], pendingKey.Hash[:])
+	return key[:]
+}
 
-	msg, _ := pendingKey.MarshalMsg(nil)
-	key := make([]byte, 1+len(msg))
+func getPendingPrefix(address types.Address) []byte {
+	var key [1 + types.AddressSize]byte
 	key[0] = idPrefixPending
-	copy(key[1:], msg)
+	copy(key[1:], address[:])
 	return key[:]
+}
 
+//getPendingKeyOfBytes decodes the key of a pending block built by getPendingKey
+func getPendingKeyOfBytes(key []byte) (*types.PendingKey, error) {
+	if len(key) != 1+types.AddressSize+types.HashSize {
+		return nil, fmt.Errorf("invalid pending key length %d", len(key))
+	}
+	address, err := types.BytesToAddress(key[1 : 1+types.AddressSize])
+	if err != nil {
+		return nil, err
+	}
+	hash, err := types.BytesToHash(key[1+types.AddressSize:])
+	if err != nil {
+		return nil, err
+	}
+	return &types.PendingKey{Address: address, Hash: hash}, nil
 }
 
 func (l *Ledger) AddPending(pendingKey *types.PendingKey, pending *types.PendingInfo, txns ...db.StoreTxn) error {
@@ -1060,7 +1131,7 @@ func (l *Ledger) AddPending(pendingKey *types.PendingKey, pending *types.Pending
 	if err != nil {
 		return err
 	}
-	key := l.getPendingKey(*pendingKey)
+	key := getPendingKey(*pendingKey)
 	txn, flag := l.getTxn(true, txns...)
 	defer l.releaseTxn(txn, flag)
 
@@ -1070,14 +1141,14 @@ func (l *Ledger) AddPending(pendingKey *types.PendingKey, pending *types.Pending
 	})
 	if err == nil {
 		return ErrPendingExists
-	} else if err != nil && err != badger.ErrKeyNotFound {
+	} else if err != nil && err != db.ErrKeyNotFound {
 		return err
 	}
 	return txn.Set(key, pendingBytes)
 }
 
 func (l *Ledger) GetPending(pendingKey types.PendingKey, txns ...db.StoreTxn) (*types.PendingInfo, error) {
-	key := l.getPendingKey(pendingKey)
+	key := getPendingKey(pendingKey)
 	var pending types.PendingInfo
 	txn, flag := l.getTxn(true, txns...)
 	defer l.releaseTxn(txn, flag)
@@ -1089,7 +1160,7 @@ func (l *Ledger) GetPending(pendingKey types.PendingKey, txns ...db.StoreTxn) (*
 		return nil
 	})
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			return nil, ErrPendingNotFound
 		}
 		return nil, err
@@ -1103,8 +1174,8 @@ func (l *Ledger) GetPendings(fn func(pendingKey *types.PendingKey, pendingInfo *
 	defer l.releaseTxn(txn, flag)
 
 	err := txn.Iterator(idPrefixPending, func(key []byte, val []byte, b byte) error {
-		pendingKey := new(types.PendingKey)
-		if _, err := pendingKey.UnmarshalMsg(key[1:]); err != nil {
+		pendingKey, err := getPendingKeyOfBytes(key)
+		if err != nil {
 			return err
 		}
 		pendingInfo := new(types.PendingInfo)
@@ -1126,27 +1197,18 @@ func (l *Ledger) SearchPending(address types.Address, fn func(key *types.Pending
 	txn, flag := l.getTxn(false, txns...)
 	defer l.releaseTxn(txn, flag)
 
-	return txn.Stream([]byte{idPrefixPending}, func(item *badger.Item) bool {
-		key := &types.PendingKey{}
-		if _, err := key.UnmarshalMsg(item.Key()[1:]); err == nil {
-			return key.Address == address
-		} else {
-			l.logger.Error(util.ToString(key), err)
-		}
-		return false
-	}, func(list *pb.KVList) error {
-		for _, v := range list.Kv {
-			pk := &types.PendingKey{}
+	return txn.Stream(getPendingPrefix(address), nil, func(list []*db.KeyValue) error {
+		for _, v := range list {
 			pi := &types.PendingInfo{}
 
-			if _, err := pk.UnmarshalMsg(v.Key[1:]); err != nil {
+			pk, err := getPendingKeyOfBytes(v.Key)
+			if err != nil {
 				continue
 			}
 			if _, err := pi.UnmarshalMsg(v.Value); err != nil {
 				continue
 			}
-			err := fn(pk, pi)
-			if err != nil {
+			if err := fn(pk, pi); err != nil {
 				l.logger.Error(err)
 			}
 		}
@@ -1155,7 +1217,7 @@ func (l *Ledger) SearchPending(address types.Address, fn func(key *types.Pending
 }
 
 func (l *Ledger) DeletePending(pendingKey *types.PendingKey, txns ...db.StoreTxn) error {
-	key := l.getPendingKey(*pendingKey)
+	key := getPendingKey(*pendingKey)
 	txn, flag := l.getTxn(true, txns...)
 	defer l.releaseTxn(txn, flag)
 
@@ -1173,7 +1235,7 @@ func (l *Ledger) AddFrontier(frontier *types.Frontier, txns ...db.StoreTxn) erro
 	})
 	if err == nil {
 		return ErrFrontierExists
-	} else if err != nil && err != badger.ErrKeyNotFound {
+	} else if err != nil && err != db.ErrKeyNotFound {
 		return err
 	}
 	return txn.Set(key, frontier.OpenBlock[:])
@@ -1190,7 +1252,7 @@ func (l *Ledger) GetFrontier(hash types.Hash, txns ...db.StoreTxn) (*types.Front
 		return nil
 	})
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			return nil, ErrFrontierNotFound
 		}
 		return nil, err
@@ -1283,7 +1345,7 @@ func getVersion(txn db.StoreTxn) (int64, error) {
 		return nil
 	})
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			return 0, ErrVersionNotFound
 		}
 		return i, err
@@ -1383,13 +1445,12 @@ func (l *Ledger) Pending(account types.Address, txns ...db.StoreTxn) ([]*types.P
 	defer l.releaseTxn(txn, flag)
 
 	err := txn.Iterator(idPrefixPending, func(key []byte, val []byte, b byte) error {
-		pendingKey := types.PendingKey{}
-		_, err := pendingKey.UnmarshalMsg(key[1:])
+		pendingKey, err := getPendingKeyOfBytes(key)
 		if err != nil {
 			return err
 		}
 		if pendingKey.Address == account {
-			cache = append(cache, &pendingKey)
+			cache = append(cache, pendingKey)
 		}
 		return nil
 	})
@@ -1424,8 +1485,7 @@ func (l *Ledger) TokenPending(account types.Address, token types.Hash, txns ...d
 	defer l.releaseTxn(txn, flag)
 
 	err := txn.Iterator(idPrefixPending, func(key []byte, val []byte, b byte) error {
-		pendingKey := types.PendingKey{}
-		_, err := pendingKey.UnmarshalMsg(key[1:])
+		pendingKey, err := getPendingKeyOfBytes(key)
 		if err != nil {
 			return nil
 		}
@@ -1436,7 +1496,7 @@ func (l *Ledger) TokenPending(account types.Address, token types.Hash, txns ...d
 				return err
 			}
 			if pending.Type == token {
-				cache = append(cache, &pendingKey)
+				cache = append(cache, pendingKey)
 			}
 		}
 		return nil
@@ -1455,8 +1515,7 @@ func (l *Ledger) TokenPendingInfo(account types.Address, token types.Hash, txns
 	defer l.releaseTxn(txn, flag)
 
 	err := txn.Iterator(idPrefixPending, func(key []byte, val []byte, b byte) error {
-		pendingKey := types.PendingKey{}
-		_, err := pendingKey.UnmarshalMsg(key[1:])
+		pendingKey, err := getPendingKeyOfBytes(key)
 		if err != nil {
 			return nil
 		}
@@ -1530,7 +1589,7 @@ func (l *Ledger) processRollback(hash types.Hash, blockLink *types.StateBlock, i
 		blockType := blockCur.GetType()
 
 		blockPre := new(types.StateBlock)
-		if blockType != types.Open {
+		if !blockCur.Previous.IsZero() {
 			blockPre, err = l.GetStateBlock(blockCur.Previous, txn)
 			if err != nil {
 				return fmt.Errorf("get previous block %s : %s", blockCur.Previous.String(), err)
@@ -1613,6 +1672,63 @@ func (l *Ledger) processRollback(hash types.Hash, blockLink *types.StateBlock, i
 					return err
 				}
 			}
+		case types.ContractReward:
+			l.logger.Debug("---delete contract reward block, ", hashCur)
+			if err := l.DeleteStateBlock(hashCur, txn); err != nil {
+				return fmt.Errorf("delete state block fail(%s), reward(%s)", err, hashCur)
+			}
+			//the reward of a contract send may open the account
+			if blockCur.Previous.IsZero() {
+				if err := l.rollBackTokenDel(tm, txn); err != nil {
+					return fmt.Errorf("rollback token fail(%s), reward(%s)", err, hashCur)
+				}
+				if err := l.rollBackFrontier(types.Hash{}, blockCur.GetHash(), txn); err != nil {
+					return fmt.Errorf("rollback frontier fail(%s), reward(%s)", err, hashCur)
+				}
+				if err := l.rollBackRep(blockCur.GetRepresentative(), blockCur, nil, false, blockCur.GetToken(), txn); err != nil {
+					return fmt.Errorf("rollback representative fail(%s), reward(%s)", err, hashCur)
+				}
+			} else {
+				if err := l.rollBackToken(tm, blockPre, txn); err != nil {
+					return fmt.Errorf("rollback token fail(%s), reward(%s)", err, hashCur)
+				}
+				if err := l.rollBackFrontier(blockPre.GetHash(), blockCur.GetHash(), txn); err != nil {
+					return fmt.Errorf("rollback frontier fail(%s), reward(%s)", err, hashCur)
+				}
+				if err := l.rollBackRep(blockCur.GetRepresentative(), blockCur, blockPre, false, blockCur.GetToken(), txn); err != nil {
+					return fmt.Errorf("rollback representative fail(%s), reward(%s)", err, hashCur)
+				}
+			}
+			if err := l.DeleteReward(blockCur.GetLink(), txn); err != nil {
+				return fmt.Errorf("rollback reward fail(%s), reward(%s)", err, hashCur)
+			}
+			if err := l.DeleteVmLogs(hashCur, txn); err != nil {
+				return fmt.Errorf("rollback vm logs fail(%s), reward(%s)", err, hashCur)
+			}
+			send, err := l.GetStateBlock(blockCur.GetLink(), txn)
+			if err != nil {
+				return fmt.Errorf("get contract send fail(%s), reward(%s)", err, hashCur)
+			}
+			if err := l.rollBackContractRoot(types.Address(send.GetLink()), hashCur, txn); err != nil {
+				return fmt.Errorf("rollback contract root fail(%s), reward(%s)", err, hashCur)
+			}
+		case types.ContractRefund:
+			l.logger.Debug("---delete contract refund block, ", hashCur)
+			if err := l.DeleteStateBlock(hashCur, txn); err != nil {
+				return fmt.Errorf("delete state block fail(%s), refund(%s)", err, hashCur)
+			}
+			if err := l.rollBackToken(tm, blockPre, txn); err != nil {
+				return fmt.Errorf("rollback token fail(%s), refund(%s)", err, hashCur)
+			}
+			if err := l.rollBackFrontier(blockPre.GetHash(), blockCur.GetHash(), txn); err != nil {
+				return fmt.Errorf("rollback frontier fail(%s), refund(%s)", err, hashCur)
+			}
+			if err := l.rollBackRep(blockCur.GetRepresentative(), blockCur, blockPre, false, blockCur.GetToken(), txn); err != nil {
+				return fmt.Errorf("rollback representative fail(%s), refund(%s)", err, hashCur)
+			}
+			if err := l.DeleteRefund(blockCur.GetLink(), txn); err != nil {
+				return fmt.Errorf("rollback refund fail(%s), refund(%s)", err, hashCur)
+			}
 		case types.Change:
 			l.logger.Debug("---delete change block, ", hashCur)
 			if err := l.DeleteStateBlock(hashCur, txn); err != nil {
@@ -1875,7 +1991,7 @@ func (l *Ledger) GetPerformanceTime(hash types.Hash, txns ...db.StoreTxn) (*type
 	})
 
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			return nil, ErrPerformanceNotFound
 		}
 		return nil, err
@@ -1919,7 +2035,7 @@ func (l *Ledger) CalculateAmount(block *types.StateBlock, txns ...db.StoreTxn) (
 			return types.ZeroBalance, err
 		}
 		return prev.TotalBalance().Sub(block.TotalBalance()), nil
-	case types.Receive:
+	case types.Receive, types.ContractRefund:
 		if prev, err = l.GetStateBlock(block.GetPrevious(), txn); err != nil {
 			return types.ZeroBalance, err
 		}
@@ -1983,7 +2099,7 @@ func (l *Ledger) CalculateAmount(block *types.StateBlock, txns ...db.StoreTxn) (
 //		return nil
 //	})
 //	if err != nil {
-//		if err == badger.ErrKeyNotFound {
+//		if err == db.ErrKeyNotFound {
 //			return nil, ErrTokenInfoNotFound
 //		}
 //		return nil, err
@@ -2052,6 +2168,15 @@ func (l *Ledger) generateWork(hash types.Hash) types.Work {
 }
 
 func (l *Ledger) GenerateSendBlock(block *types.StateBlock, amount types.Balance, prk ed25519.PrivateKey) (*types.StateBlock, error) {
+	blk, err := l.GenerateUnsignedSendBlock(block, amount)
+	if err != nil {
+		return nil, err
+	}
+	return signBlock(blk, prk), nil
+}
+
+// GenerateUnsignedSendBlock generates a send block with work but without signature, the block can be signed offline
+func (l *Ledger) GenerateUnsignedSendBlock(block *types.StateBlock, amount types.Balance) (*types.StateBlock, error) {
 	tm, err := l.GetTokenMeta(block.GetAddress(), block.GetToken())
 	if err != nil {
 		return nil, errors.New("token not found")
@@ -2070,9 +2195,6 @@ func (l *Ledger) GenerateSendBlock(block *types.StateBlock, amount types.Balance
 		block.Network = prev.GetNetwork()
 		block.Oracle = prev.GetOracle()
 		block.Storage = prev.GetStorage()
-
-		acc := types.NewAccount(prk)
-		block.Signature = acc.Sign(block.GetHash())
 		block.Work = l.generateWork(block.Root())
 		return block, nil
 	} else {
@@ -2081,6 +2203,16 @@ func (l *Ledger) GenerateSendBlock(block *types.StateBlock, amount types.Balance
 }
 
 func (l *Ledger) GenerateReceiveBlock(sendBlock *types.StateBlock, prk ed25519.PrivateKey) (*types.StateBlock, error) {
+	blk, err := l.GenerateUnsignedReceiveBlock(sendBlock)
+	if err != nil {
+		return nil, err
+	}
+	return signBlock(blk, prk), nil
+}
+
+// GenerateUnsignedReceiveBlock generates a receive or open block with work but without signature,
+// the block can be signed offline
+func (l *Ledger) GenerateUnsignedReceiveBlock(sendBlock *types.StateBlock) (*types.StateBlock, error) {
 	hash := sendBlock.GetHash()
 	if !sendBlock.GetType().Equal(types.Send) {
 		return nil, fmt.Errorf("(%s) is not send block", hash.String())
@@ -2088,7 +2220,6 @@ func (l *Ledger) GenerateReceiveBlock(sendBlock *types.StateBlock, prk ed25519.P
 	if exist, err := l.HasStateBlock(hash); !exist || err != nil {
 		return nil, fmt.Errorf("send block(%s) does not exist", hash.String())
 	}
-	acc := types.NewAccount(prk)
 	rxAccount := types.Address(sendBlock.Link)
 	info, err := l.GetPending(types.PendingKey{Address: rxAccount, Hash: hash})
 	if err != nil {
@@ -2123,7 +2254,6 @@ func (l *Ledger) GenerateReceiveBlock(sendBlock *types.StateBlock, prk ed25519.P
 				Extra:          types.ZeroHash,
 				Timestamp:      time.Now().Unix(),
 			}
-			sb.Signature = acc.Sign(sb.GetHash())
 			sb.Work = l.generateWork(sb.Root())
 			return &sb, nil
 		}
@@ -2143,12 +2273,20 @@ func (l *Ledger) GenerateReceiveBlock(sendBlock *types.StateBlock, prk ed25519.P
 		Extra:          types.ZeroHash,
 		Timestamp:      time.Now().Unix(),
 	}
-	sb.Signature = acc.Sign(sb.GetHash())
 	sb.Work = l.generateWork(sb.Root())
 	return &sb, nil
 }
 
 func (l *Ledger) GenerateChangeBlock(account types.Address, representative types.Address, prk ed25519.PrivateKey) (*types.StateBlock, error) {
+	blk, err := l.GenerateUnsignedChangeBlock(account, representative)
+	if err != nil {
+		return nil, err
+	}
+	return signBlock(blk, prk), nil
+}
+
+// GenerateUnsignedChangeBlock generates a change block with work but without signature, the block can be signed offline
+func (l *Ledger) GenerateUnsignedChangeBlock(account types.Address, representative types.Address) (*types.StateBlock, error) {
 	if _, err := l.GetAccountMeta(representative); err != nil {
 		return nil, fmt.Errorf("invalid representative[%s]", representative.String())
 	}
@@ -2181,12 +2319,16 @@ func (l *Ledger) GenerateChangeBlock(account types.Address, representative types
 		Extra:          types.ZeroHash,
 		Timestamp:      time.Now().Unix(),
 	}
-	acc := types.NewAccount(prk)
-	sb.Signature = acc.Sign(sb.GetHash())
 	sb.Work = l.generateWork(sb.Root())
 	return &sb, nil
 }
 
+func signBlock(block *types.StateBlock, prk ed25519.PrivateKey) *types.StateBlock {
+	acc := types.NewAccount(prk)
+	block.Signature = acc.Sign(block.GetHash())
+	return block
+}
+
 func (l *Ledger) GetOnlineRepresentations(txns ...db.StoreTxn) ([]types.Address, error) {
 	key := []byte{idPrefixOnlineReps}
 	txn, flag := l.getTxn(true, txns...)
@@ -2215,6 +2357,129 @@ func (l *Ledger) SetOnlineRepresentations(addresses []*types.Address, txns ...db
 	return txn.Set(key, bytes)
 }
 
+// AddElectionInfo saves the result of a finished election, it can be queried by the hash of the winner or any loser
+func (l *Ledger) AddElectionInfo(info *types.ElectionInfo, txns ...db.StoreTxn) error {
+	bytes, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	txn, flag := l.getTxn(true, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	hashes := append([]types.Hash{info.Winner}, info.Losers...)
+	for _, hash := range hashes {
+		if err := txn.Set(getKeyOfHash(hash, idPrefixElection), bytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Ledger) GetElectionInfo(hash types.Hash, txns ...db.StoreTxn) (*types.ElectionInfo, error) {
+	txn, flag := l.getTxn(false, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	info := new(types.ElectionInfo)
+	err := txn.Get(getKeyOfHash(hash, idPrefixElection), func(val []byte, b byte) error {
+		return json.Unmarshal(val, info)
+	})
+	if err != nil {
+		if err == db.ErrKeyNotFound {
+			return nil, ErrElectionNotFound
+		}
+		return nil, err
+	}
+	return info, nil
+}
+
+// AddBannedPeer adds the peer to the ban list, the p2p layer refuses streams of banned peers
+func (l *Ledger) AddBannedPeer(peerID string, txns ...db.StoreTxn) error {
+	txn, flag := l.getTxn(true, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	return txn.Set(getKeyOfBannedPeer(peerID), util.Int64ToBytes(time.Now().Unix()))
+}
+
+func (l *Ledger) DeleteBannedPeer(peerID string, txns ...db.StoreTxn) error {
+	txn, flag := l.getTxn(true, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	return txn.Delete(getKeyOfBannedPeer(peerID))
+}
+
+func (l *Ledger) IsBannedPeer(peerID string, txns ...db.StoreTxn) (bool, error) {
+	txn, flag := l.getTxn(false, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	err := txn.Get(getKeyOfBannedPeer(peerID), func(val []byte, b byte) error {
+		return nil
+	})
+	if err != nil {
+		if err == db.ErrKeyNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetBannedPeers returns the ban list, peer id to the unix time it was banned
+func (l *Ledger) GetBannedPeers(txns ...db.StoreTxn) (map[string]int64, error) {
+	txn, flag := l.getTxn(false, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	peers := make(map[string]int64)
+	err := txn.Iterator(idPrefixBannedPeer, func(key []byte, val []byte, b byte) error {
+		peers[string(key[1:])] = int64(binary.LittleEndian.Uint64(val))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return peers, nil
+}
+
+func getKeyOfBannedPeer(peerID string) []byte {
+	return append([]byte{idPrefixBannedPeer}, []byte(peerID)...)
+}
+
+// GetSyncProgress returns the progress of the unfinished sync round, nil if there is no such round
+func (l *Ledger) GetSyncProgress(txns ...db.StoreTxn) (*types.SyncProgress, error) {
+	txn, flag := l.getTxn(false, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	var progress *types.SyncProgress
+	err := txn.Get([]byte{idPrefixSyncProgress}, func(val []byte, b byte) error {
+		progress = new(types.SyncProgress)
+		return json.Unmarshal(val, progress)
+	})
+	if err != nil {
+		if err == db.ErrKeyNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return progress, nil
+}
+
+func (l *Ledger) SetSyncProgress(progress *types.SyncProgress, txns ...db.StoreTxn) error {
+	val, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	txn, flag := l.getTxn(true, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	return txn.Set([]byte{idPrefixSyncProgress}, val)
+}
+
+func (l *Ledger) DeleteSyncProgress(txns ...db.StoreTxn) error {
+	txn, flag := l.getTxn(true, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	return txn.Delete([]byte{idPrefixSyncProgress})
+}
+
 func (l *Ledger) AddMessageInfo(mHash types.Hash, message []byte, txns ...db.StoreTxn) error {
 	txn, flag := l.getTxn(true, txns...)
 	defer l.releaseTxn(txn, flag)
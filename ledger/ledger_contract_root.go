@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package ledger
+
+import (
+	"errors"
+
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/ledger/db"
+)
+
+var ErrContractRootNotFound = errors.New("contract root not found")
+
+// GetContractRoot returns the root of the storage trie of the contract, it is the root committed by the latest
+// ContractReward block of the contract
+func (l *Ledger) GetContractRoot(address types.Address, txns ...db.StoreTxn) (types.Hash, error) {
+	txn, flag := l.getTxn(false, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	var root types.Hash
+	err := txn.Get(getKeyOfHash(address.ToHash(), idPrefixContractRoot), func(val []byte, b byte) error {
+		return root.UnmarshalBinary(val)
+	})
+	if err != nil {
+		if err == db.ErrKeyNotFound {
+			return types.ZeroHash, ErrContractRootNotFound
+		}
+		return types.ZeroHash, err
+	}
+	return root, nil
+}
+
+func (l *Ledger) SetContractRoot(address types.Address, root types.Hash, txns ...db.StoreTxn) error {
+	txn, flag := l.getTxn(true, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	return txn.Set(getKeyOfHash(address.ToHash(), idPrefixContractRoot), root[:])
+}
+
+// UpdateContractRoot moves the root of the contract to the root after the ContractReward block reward, the previous
+// root is kept by reward so it is restored when reward is rolled back
+func (l *Ledger) UpdateContractRoot(address types.Address, reward, root types.Hash, txns ...db.StoreTxn) error {
+	txn, flag := l.getTxn(true, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	var previous []byte
+	if r, err := l.GetContractRoot(address, txn); err == nil {
+		previous = r[:]
+	} else if err != ErrContractRootNotFound {
+		return err
+	}
+	if err := txn.Set(getKeyOfHash(reward, idPrefixContractRootPrevious), previous); err != nil {
+		return err
+	}
+	return l.SetContractRoot(address, root, txn)
+}
+
+// rollBackContractRoot restores the root of the contract before the ContractReward block reward
+func (l *Ledger) rollBackContractRoot(address types.Address, reward types.Hash, txn db.StoreTxn) error {
+	key := getKeyOfHash(reward, idPrefixContractRootPrevious)
+	var previous []byte
+	err := txn.Get(key, func(val []byte, b byte) error {
+		previous = make([]byte, len(val))
+		copy(previous, val)
+		return nil
+	})
+	if err != nil {
+		if err == db.ErrKeyNotFound {
+			return nil
+		}
+		return err
+	}
+	if err := txn.Delete(key); err != nil {
+		return err
+	}
+	if len(previous) == 0 {
+		return txn.Delete(getKeyOfHash(address.ToHash(), idPrefixContractRoot))
+	}
+	return txn.Set(getKeyOfHash(address.ToHash(), idPrefixContractRoot), previous)
+}
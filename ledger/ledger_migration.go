@@ -3,8 +3,6 @@ package ledger
 import (
 	"fmt"
 
-	"github.com/dgraph-io/badger"
-	"github.com/dgraph-io/badger/pb"
 	"github.com/qlcchain/go-qlc/common"
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/ledger/db"
@@ -59,10 +57,10 @@ func (m MigrationV2ToV3) Migrate(txn db.StoreTxn) error {
 		err := txn.Get(key, func(bytes []byte, b byte) error {
 			return nil
 		})
-		if err != nil && err != badger.ErrKeyNotFound {
+		if err != nil && err != db.ErrKeyNotFound {
 			return err
 		}
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			if err := txn.Drop(nil); err != nil {
 				return err
 			}
@@ -93,10 +91,10 @@ func (m MigrationV3ToV4) Migrate(txn db.StoreTxn) error {
 		deleteTable := []byte{idPrefixSender, idPrefixReceiver, idPrefixMessage}
 		for _, d := range deleteTable {
 			prefix := []byte{d}
-			err := txn.Stream(prefix, func(item *badger.Item) bool {
+			err := txn.Stream(prefix, func(key []byte) bool {
 				return true
-			}, func(list *pb.KVList) error {
-				for _, l := range list.Kv {
+			}, func(list []*db.KeyValue) error {
+				for _, l := range list {
 					if err := txn.Delete(l.Key); err != nil {
 						return err
 					}
@@ -235,3 +233,55 @@ func updateVersion(m db.Migration, txn db.StoreTxn) error {
 	//fmt.Printf("update ledger version %d to %d successfully\n ", m.StartVersion(), m.EndVersion())
 	return nil
 }
+
+type MigrationV5ToV6 struct {
+}
+
+func (m MigrationV5ToV6) Migrate(txn db.StoreTxn) error {
+	b, err := checkVersion(m, txn)
+	if err != nil {
+		return err
+	}
+	if b {
+		fmt.Println("migrating ledger v5 to v6 ... ")
+		//pending keys were msgp encoded, rebuild them as address and hash
+		type pending struct {
+			old []byte
+			key types.PendingKey
+			val []byte
+		}
+		var pendings []pending
+		err = txn.Iterator(idPrefixPending, func(key []byte, val []byte, b byte) error {
+			var pendingKey types.PendingKey
+			if _, err := pendingKey.UnmarshalMsg(key[1:]); err != nil {
+				return err
+			}
+			p := pending{old: make([]byte, len(key)), key: pendingKey, val: make([]byte, len(val))}
+			copy(p.old, key)
+			copy(p.val, val)
+			pendings = append(pendings, p)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, p := range pendings {
+			if err := txn.Delete(p.old); err != nil {
+				return err
+			}
+			if err := txn.Set(getPendingKey(p.key), p.val); err != nil {
+				return err
+			}
+		}
+		return updateVersion(m, txn)
+	}
+	return nil
+}
+
+func (m MigrationV5ToV6) StartVersion() int {
+	return 5
+}
+
+func (m MigrationV5ToV6) EndVersion() int {
+	return 6
+}
@@ -0,0 +1,247 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package ledger
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/common/util"
+	"github.com/qlcchain/go-qlc/ledger/db"
+)
+
+// pruneBatchSize is the max count of the blocks deleted in a transaction
+const pruneBatchSize = 1000
+
+// Prune deletes the history of the token chains of all the accounts, the latest depth blocks of every chain are
+// kept so the chain can be rolled back within depth blocks, and the block before them is kept too as the rollback
+// and the amount of the oldest kept block need it. A send block which is still pending is kept until it is
+// received, the genesis blocks are never deleted. The accounts are pruned one by one and the blocks are deleted in
+// batches, so the memory used does not grow with the ledger. The relation rows of the deleted blocks are deleted
+// by EventDeleteRelation. It returns the count of the deleted blocks.
+func (l *Ledger) Prune(depth int) (int, error) {
+	if depth < 1 {
+		depth = 1
+	}
+	p := &pruner{l: l}
+	err := l.GetAccountMetas(func(am *types.AccountMeta) error {
+		for _, tm := range am.Tokens {
+			if err := p.pruneChain(tm.Header, depth); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return p.count, err
+	}
+	// the pending sends kept by the former runs are not reachable from the headers once they are received
+	if err := p.pruneReceived(); err != nil {
+		return p.count, err
+	}
+	if err := p.flush(); err != nil {
+		return p.count, err
+	}
+	err = l.BatchUpdate(func(txn db.StoreTxn) error {
+		return txn.Set([]byte{idPrefixPruned}, util.Int64ToBytes(time.Now().Unix()))
+	})
+	if err != nil {
+		return p.count, err
+	}
+	return p.count, nil
+}
+
+// pruner collects the blocks to delete and the pending sends to keep, they are written every pruneBatchSize blocks
+type pruner struct {
+	l      *Ledger
+	blocks []*types.StateBlock
+	kept   []types.Hash
+	stale  []types.Hash
+	count  int
+}
+
+// pruneChain walks the chain back from the header, it stops at the first block which is already pruned
+func (p *pruner) pruneChain(header types.Hash, depth int) error {
+	hash := header
+	for i := 0; !hash.IsZero(); i++ {
+		blk, err := p.l.GetStateBlock(hash)
+		if err != nil {
+			if err == ErrBlockNotFound {
+				break
+			}
+			return err
+		}
+		if i > depth && !common.IsGenesisBlock(blk) {
+			pending, err := p.l.isPendingSend(blk)
+			if err != nil {
+				return err
+			}
+			if pending {
+				p.kept = append(p.kept, hash)
+			} else {
+				p.blocks = append(p.blocks, blk)
+			}
+			if len(p.blocks)+len(p.kept) >= pruneBatchSize {
+				if err := p.flush(); err != nil {
+					return err
+				}
+			}
+		}
+		hash = blk.GetPrevious()
+	}
+	return nil
+}
+
+// pruneReceived prunes the kept pending sends which have been received, the kept sends which have been rolled back
+// are dropped from the index
+func (p *pruner) pruneReceived() error {
+	var hashes []types.Hash
+	txn, flag := p.l.getTxn(false)
+	err := txn.Iterator(idPrefixPrunedPending, func(key []byte, val []byte, b byte) error {
+		hash, err := types.BytesToHash(key[1:])
+		if err != nil {
+			return err
+		}
+		hashes = append(hashes, hash)
+		return nil
+	})
+	p.l.releaseTxn(txn, flag)
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		blk, err := p.l.GetStateBlock(hash)
+		if err != nil {
+			if err == ErrBlockNotFound {
+				p.stale = append(p.stale, hash)
+				continue
+			}
+			return err
+		}
+		if pending, err := p.l.isPendingSend(blk); err != nil {
+			return err
+		} else if !pending {
+			p.blocks = append(p.blocks, blk)
+		}
+		if len(p.blocks)+len(p.stale) >= pruneBatchSize {
+			if err := p.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// flush deletes the collected blocks and indexes the kept pending sends in a transaction
+func (p *pruner) flush() error {
+	if len(p.blocks) == 0 && len(p.kept) == 0 && len(p.stale) == 0 {
+		return nil
+	}
+	err := p.l.BatchUpdate(func(txn db.StoreTxn) error {
+		for _, blk := range p.blocks {
+			if err := p.l.pruneStateBlock(blk, txn); err != nil {
+				return err
+			}
+		}
+		for _, hash := range p.kept {
+			if err := txn.Set(getKeyOfHash(hash, idPrefixPrunedPending), nil); err != nil {
+				return err
+			}
+		}
+		for _, hash := range p.stale {
+			if err := txn.Delete(getKeyOfHash(hash, idPrefixPrunedPending)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, blk := range p.blocks {
+		p.l.eb.Publish(string(common.EventDeleteRelation), blk.GetHash())
+	}
+	p.count += len(p.blocks)
+	p.blocks, p.kept, p.stale = p.blocks[:0], p.kept[:0], p.stale[:0]
+	return nil
+}
+
+// isPendingSend checks whether the block is a send which is not received yet
+func (l *Ledger) isPendingSend(blk *types.StateBlock) (bool, error) {
+	if blk.GetType() != types.Send {
+		return false, nil
+	}
+	_, err := l.GetPending(types.PendingKey{Address: types.Address(blk.GetLink()), Hash: blk.GetHash()})
+	if err == nil {
+		return true, nil
+	}
+	if err == ErrPendingNotFound {
+		return false, nil
+	}
+	return false, err
+}
+
+// pruneStateBlock deletes the block with its contract logs, the children index of the block and the block from the
+// children index of its parent, unlike DeleteStateBlock the parent may already be pruned
+func (l *Ledger) pruneStateBlock(blk *types.StateBlock, txn db.StoreTxn) error {
+	hash := blk.GetHash()
+	if err := txn.Delete(getKeyOfHash(hash, idPrefixBlock)); err != nil {
+		return err
+	}
+	if err := txn.Delete(getKeyOfHash(hash, idPrefixChild)); err != nil {
+		return err
+	}
+	if err := txn.Delete(getKeyOfHash(hash, idPrefixPrunedPending)); err != nil {
+		return err
+	}
+	if err := txn.Delete(getKeyOfHash(hash, idPrefixVmLogs)); err != nil {
+		return err
+	}
+	pHash := blk.Parent()
+	if pHash.IsZero() {
+		return nil
+	}
+	children, err := getChildren(pHash, txn)
+	if err != nil {
+		if err == db.ErrKeyNotFound {
+			return nil
+		}
+		return err
+	}
+	delete(children, hash)
+	pKey := getKeyOfHash(pHash, idPrefixChild)
+	if len(children) == 0 {
+		return txn.Delete(pKey)
+	}
+	val, err := json.Marshal(children)
+	if err != nil {
+		return err
+	}
+	return txn.Set(pKey, val)
+}
+
+// PrunedTime returns the unix time of the last pruning, zero if the ledger has never been pruned, a ledger which
+// has been pruned does not have the full history even if pruning is disabled afterwards
+func (l *Ledger) PrunedTime(txns ...db.StoreTxn) (int64, error) {
+	txn, flag := l.getTxn(false, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	var t int64
+	err := txn.Get([]byte{idPrefixPruned}, func(val []byte, b byte) error {
+		t = int64(binary.LittleEndian.Uint64(val))
+		return nil
+	})
+	if err != nil && err != db.ErrKeyNotFound {
+		return 0, err
+	}
+	return t, nil
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package ledger
+
+import (
+	"errors"
+
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/ledger/db"
+)
+
+var ErrRefundNotFound = errors.New("refund not found")
+
+// AddRefund records that the contract send is refunded by the ContractRefund block refund, a contract send has no
+// pending so the record keeps it from being refunded or received twice
+func (l *Ledger) AddRefund(send, refund types.Hash, txns ...db.StoreTxn) error {
+	txn, flag := l.getTxn(true, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	return txn.Set(getKeyOfHash(send, idPrefixRefund), refund[:])
+}
+
+// GetRefund returns the hash of the ContractRefund block of the contract send
+func (l *Ledger) GetRefund(send types.Hash, txns ...db.StoreTxn) (types.Hash, error) {
+	txn, flag := l.getTxn(false, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	var refund types.Hash
+	err := txn.Get(getKeyOfHash(send, idPrefixRefund), func(val []byte, b byte) error {
+		return refund.UnmarshalBinary(val)
+	})
+	if err != nil {
+		if err == db.ErrKeyNotFound {
+			return types.ZeroHash, ErrRefundNotFound
+		}
+		return types.ZeroHash, err
+	}
+	return refund, nil
+}
+
+func (l *Ledger) DeleteRefund(send types.Hash, txns ...db.StoreTxn) error {
+	txn, flag := l.getTxn(true, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	return txn.Delete(getKeyOfHash(send, idPrefixRefund))
+}
+
+var ErrRewardNotFound = errors.New("reward not found")
+
+// AddReward records that the contract send is received by the ContractReward block reward, a received contract send
+// can not be refunded
+func (l *Ledger) AddReward(send, reward types.Hash, txns ...db.StoreTxn) error {
+	txn, flag := l.getTxn(true, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	return txn.Set(getKeyOfHash(send, idPrefixReward), reward[:])
+}
+
+// GetReward returns the hash of the ContractReward block of the contract send
+func (l *Ledger) GetReward(send types.Hash, txns ...db.StoreTxn) (types.Hash, error) {
+	txn, flag := l.getTxn(false, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	var reward types.Hash
+	err := txn.Get(getKeyOfHash(send, idPrefixReward), func(val []byte, b byte) error {
+		return reward.UnmarshalBinary(val)
+	})
+	if err != nil {
+		if err == db.ErrKeyNotFound {
+			return types.ZeroHash, ErrRewardNotFound
+		}
+		return types.ZeroHash, err
+	}
+	return reward, nil
+}
+
+func (l *Ledger) DeleteReward(send types.Hash, txns ...db.StoreTxn) error {
+	txn, flag := l.getTxn(true, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	return txn.Delete(getKeyOfHash(send, idPrefixReward))
+}
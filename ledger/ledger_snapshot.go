@@ -0,0 +1,434 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package ledger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/ledger/db"
+)
+
+// Snapshot archive layout, all integers are big endian:
+//
+//	magic        4 bytes, "QLCS"
+//	version      uint32, archive format version
+//	ledger       int64, ledger schema version of the exported data
+//	genesis      32 bytes, genesis block hash of the exported chain
+//	records      repeated: uvarint key length, key, uvarint value length, value, meta byte
+//	end          uvarint 0 (keys are never empty)
+//	count        uint64, number of records
+//	checksum     32 bytes, sha256 of everything above
+//
+// The key of each record is the raw store key, its first byte is the prefix of the record type.
+const snapshotVersion uint32 = 1
+
+var snapshotMagic = [4]byte{'Q', 'L', 'C', 'S'}
+
+// contract state prefixes owned by vmstore and trie
+const (
+	snapshotPrefixVMStorage byte = 100
+	snapshotPrefixTrie      byte = 101
+)
+
+// snapshotPrefixes are the record types carried by a snapshot, unchecked blocks, performance records,
+// online representatives, election history and the version key are node local and never exported
+var snapshotPrefixes = []byte{
+	idPrefixBlock,
+	idPrefixSmartContractBlock,
+	idPrefixContractAddress,
+	idPrefixAccount,
+	idPrefixFrontier,
+	idPrefixPending,
+	idPrefixRepresentation,
+	idPrefixChild,
+	idPrefixMessageInfo,
+	idPrefixRefund,
+	idPrefixReward,
+	idPrefixVmLogs,
+	idPrefixContractRoot,
+	idPrefixContractRootPrevious,
+	snapshotPrefixVMStorage,
+	snapshotPrefixTrie,
+}
+
+const snapshotBatchSize = 1000
+
+var (
+	ErrSnapshotInvalid  = errors.New("invalid snapshot archive")
+	ErrSnapshotChecksum = errors.New("snapshot checksum mismatch")
+	ErrSnapshotNotEmpty = errors.New("ledger is not empty, snapshot can only be imported into an empty ledger")
+)
+
+// ExportSnapshot writes all ledger state to w as a snapshot archive and returns the number of records written,
+// the archive is taken from a single read transaction so it is consistent even if the ledger is being written
+func (l *Ledger) ExportSnapshot(w io.Writer) (uint64, error) {
+	h := sha256.New()
+	bw := bufio.NewWriter(io.MultiWriter(w, h))
+
+	if err := writeSnapshotHeader(bw); err != nil {
+		return 0, err
+	}
+
+	var count uint64
+	err := l.Store.ViewInTx(func(txn db.StoreTxn) error {
+		for _, prefix := range snapshotPrefixes {
+			err := txn.Iterator(prefix, func(key []byte, val []byte, meta byte) error {
+				count++
+				return writeSnapshotRecord(bw, key, val, meta)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var buf [binary.MaxVarintLen64]byte
+	if _, err := bw.Write(buf[:binary.PutUvarint(buf[:], 0)]); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(bw, binary.BigEndian, count); err != nil {
+		return 0, err
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(h.Sum(nil)); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ImportSnapshot restores a snapshot archive written by ExportSnapshot into an empty ledger and returns the number
+// of records imported. The archive is staged in a temporary file and verified as a whole, every block against its
+// hash and signature, before anything is written; if writing fails the records of the snapshot are deleted again
+func (l *Ledger) ImportSnapshot(r io.Reader) (uint64, error) {
+	if empty, err := l.Empty(); err != nil {
+		return 0, err
+	} else if !empty {
+		return 0, ErrSnapshotNotEmpty
+	}
+
+	f, err := ioutil.TempFile(l.dir, "snapshot")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	_, err = readSnapshot(io.TeeReader(r, f), func(key, val []byte, meta byte) error {
+		switch key[0] {
+		case idPrefixBlock:
+			_, err := verifySnapshotBlock(key, val)
+			return err
+		case idPrefixSmartContractBlock:
+			return verifySnapshotSmartContractBlock(key, val)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	written := make(map[byte]bool)
+	count, blocks, err := l.importSnapshot(f, written)
+	if err != nil {
+		if e := l.deleteSnapshotRecords(written); e != nil {
+			l.logger.Error(e)
+		}
+		return 0, err
+	}
+	for _, blk := range blocks {
+		l.eb.Publish(string(common.EventAddRelation), blk)
+	}
+	return count, nil
+}
+
+// importSnapshot writes the records of the verified archive, the prefixes of the written records are put in written
+func (l *Ledger) importSnapshot(r io.Reader, written map[byte]bool) (uint64, []*types.StateBlock, error) {
+	var count uint64
+	var blocks []*types.StateBlock
+	txn := l.Store.NewTransaction(true)
+	defer func() {
+		txn.Discard()
+	}()
+
+	_, err := readSnapshot(r, func(key, val []byte, meta byte) error {
+		if key[0] == idPrefixBlock {
+			blk := new(types.StateBlock)
+			if err := blk.Deserialize(val); err != nil {
+				return err
+			}
+			blocks = append(blocks, blk)
+		}
+		written[key[0]] = true
+		if err := txn.SetWithMeta(key, val, meta); err != nil {
+			return err
+		}
+		count++
+		if count%snapshotBatchSize == 0 {
+			if err := txn.Commit(nil); err != nil {
+				return err
+			}
+			txn.Discard()
+			txn = l.Store.NewTransaction(true)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := txn.Commit(nil); err != nil {
+		return 0, nil, err
+	}
+	return count, blocks, nil
+}
+
+// deleteSnapshotRecords deletes the records of the prefixes written by a failed import, the rest of the store such
+// as the node local records is kept
+func (l *Ledger) deleteSnapshotRecords(prefixes map[byte]bool) error {
+	for prefix := range prefixes {
+		var keys [][]byte
+		err := l.Store.ViewInTx(func(txn db.StoreTxn) error {
+			return txn.Iterator(prefix, func(key []byte, val []byte, meta byte) error {
+				k := make([]byte, len(key))
+				copy(k, key)
+				keys = append(keys, k)
+				return nil
+			})
+		})
+		if err != nil {
+			return err
+		}
+		for len(keys) > 0 {
+			n := len(keys)
+			if n > snapshotBatchSize {
+				n = snapshotBatchSize
+			}
+			err := l.Store.UpdateInTx(func(txn db.StoreTxn) error {
+				for _, key := range keys[:n] {
+					if err := txn.Delete(key); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			keys = keys[n:]
+		}
+	}
+	return nil
+}
+
+// readSnapshot reads the archive and calls fn with every record, it returns the number of records once the record
+// count and the checksum of the archive are verified
+func readSnapshot(r io.Reader, fn func(key, val []byte, meta byte) error) (uint64, error) {
+	h := sha256.New()
+	br := &snapshotReader{r: bufio.NewReader(r), h: h}
+
+	if err := readSnapshotHeader(br); err != nil {
+		return 0, err
+	}
+
+	prefixes := make(map[byte]bool)
+	for _, p := range snapshotPrefixes {
+		prefixes[p] = true
+	}
+
+	var count uint64
+	for {
+		key, val, meta, err := readSnapshotRecord(br)
+		if err != nil {
+			return 0, err
+		}
+		if key == nil {
+			break
+		}
+		if !prefixes[key[0]] {
+			return 0, fmt.Errorf("%s: unknown record type %d", ErrSnapshotInvalid, key[0])
+		}
+		if err := fn(key, val, meta); err != nil {
+			return 0, err
+		}
+		count++
+	}
+
+	var c uint64
+	if err := binary.Read(br, binary.BigEndian, &c); err != nil {
+		return 0, err
+	}
+	if c != count {
+		return 0, fmt.Errorf("%s: expect %d records, got %d", ErrSnapshotInvalid, c, count)
+	}
+	sum := h.Sum(nil)
+	checksum := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(br.r, checksum); err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(sum, checksum) {
+		return 0, ErrSnapshotChecksum
+	}
+	return count, nil
+}
+
+func verifySnapshotBlock(key, val []byte) (*types.StateBlock, error) {
+	blk := new(types.StateBlock)
+	if err := blk.Deserialize(val); err != nil {
+		return nil, err
+	}
+	hash := blk.GetHash()
+	if !bytes.Equal(key[1:], hash[:]) {
+		return nil, fmt.Errorf("%s: block %s is stored as %x", ErrSnapshotInvalid, hash, key[1:])
+	}
+	address := blk.GetAddress()
+	signature := blk.GetSignature()
+	if !address.Verify(hash[:], signature[:]) {
+		return nil, fmt.Errorf("%s: bad signature of block %s", ErrSnapshotInvalid, hash)
+	}
+	return blk, nil
+}
+
+func verifySnapshotSmartContractBlock(key, val []byte) error {
+	blk := new(types.SmartContractBlock)
+	if err := blk.Deserialize(val); err != nil {
+		return err
+	}
+	hash := blk.GetHash()
+	if !bytes.Equal(key[1:], hash[:]) {
+		return fmt.Errorf("%s: smart contract block %s is stored as %x", ErrSnapshotInvalid, hash, key[1:])
+	}
+	if !blk.InternalAccount.Verify(hash[:], blk.Signature[:]) {
+		return fmt.Errorf("%s: bad signature of smart contract block %s", ErrSnapshotInvalid, hash)
+	}
+	return nil
+}
+
+func writeSnapshotHeader(w io.Writer) error {
+	genesis := common.GenesisBlockHash()
+	for _, f := range []interface{}{snapshotMagic, snapshotVersion, int64(version), genesis} {
+		if err := binary.Write(w, binary.BigEndian, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readSnapshotHeader(r io.Reader) error {
+	var magic [4]byte
+	var v uint32
+	var lv int64
+	var genesis types.Hash
+	for _, f := range []interface{}{&magic, &v, &lv, &genesis} {
+		if err := binary.Read(r, binary.BigEndian, f); err != nil {
+			return err
+		}
+	}
+	if magic != snapshotMagic {
+		return ErrSnapshotInvalid
+	}
+	if v != snapshotVersion {
+		return fmt.Errorf("%s: unsupported archive version %d", ErrSnapshotInvalid, v)
+	}
+	if lv != version {
+		return fmt.Errorf("%s: ledger version %d, expect %d", ErrSnapshotInvalid, lv, version)
+	}
+	if genesis != common.GenesisBlockHash() {
+		return fmt.Errorf("%s: genesis %s does not match %s", ErrSnapshotInvalid, genesis, common.GenesisBlockHash())
+	}
+	return nil
+}
+
+func writeSnapshotRecord(w io.Writer, key, val []byte, meta byte) error {
+	var buf [binary.MaxVarintLen64]byte
+	for _, b := range [][]byte{key, val} {
+		if _, err := w.Write(buf[:binary.PutUvarint(buf[:], uint64(len(b)))]); err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write([]byte{meta})
+	return err
+}
+
+// readSnapshotRecord returns a nil key at the end of records
+func readSnapshotRecord(r *snapshotReader) ([]byte, []byte, byte, error) {
+	key, err := readSnapshotBytes(r)
+	if err != nil || len(key) == 0 {
+		return nil, nil, 0, err
+	}
+	val, err := readSnapshotBytes(r)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	meta, err := r.ReadByte()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return key, val, meta, nil
+}
+
+// maxSnapshotValueSize bounds a single key or value read from an archive
+const maxSnapshotValueSize = 64 << 20
+
+func readSnapshotBytes(r *snapshotReader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxSnapshotValueSize {
+		return nil, fmt.Errorf("%s: record size %d is too large", ErrSnapshotInvalid, size)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// snapshotReader feeds every byte read into the checksum
+type snapshotReader struct {
+	r *bufio.Reader
+	h hash.Hash
+}
+
+func (s *snapshotReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.h.Write(p[:n])
+	return n, err
+}
+
+func (s *snapshotReader) ReadByte() (byte, error) {
+	b, err := s.r.ReadByte()
+	if err == nil {
+		s.h.Write([]byte{b})
+	}
+	return b, err
+}
@@ -0,0 +1,113 @@
+package ledger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/ledger/db"
+	"github.com/qlcchain/go-qlc/test/mock"
+)
+
+func signedOpenBlock() *types.StateBlock {
+	a := mock.Account()
+	blk := mock.StateBlockWithoutWork()
+	blk.Address = a.Address()
+	blk.Signature = a.Sign(blk.GetHash())
+	return blk
+}
+
+func TestLedger_Snapshot(t *testing.T) {
+	teardownTestCase, l := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	blk := signedOpenBlock()
+	if err := l.AddStateBlock(blk); err != nil {
+		t.Fatal(err)
+	}
+	sc := mock.SmartContractBlock()
+	if err := l.AddSmartContractBlock(sc); err != nil {
+		t.Fatal(err)
+	}
+	am := mock.AccountMeta(blk.GetAddress())
+	if err := l.AddAccountMeta(am); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	n, err := l.ExportSnapshot(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//the smart contract block is stored with its address index
+	if n != 4 {
+		t.Fatal("invalid record count", n)
+	}
+
+	l2 := NewLedgerWithStore(db.NewMemoryStore())
+	defer l2.Close()
+	if c, err := l2.ImportSnapshot(bytes.NewReader(buf.Bytes())); err != nil || c != n {
+		t.Fatal(c, err)
+	}
+	if b, err := l2.GetStateBlock(blk.GetHash()); err != nil || b.GetHash() != blk.GetHash() {
+		t.Fatal(err)
+	}
+	if _, err := l2.GetSmartContractBlock(sc.GetHash()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l2.GetSmartContractBlockByAddress(sc.Address); err != nil {
+		t.Fatal(err)
+	}
+	if a, err := l2.GetAccountMeta(blk.GetAddress()); err != nil || a.Address != am.Address {
+		t.Fatal(err)
+	}
+
+	if _, err := l2.ImportSnapshot(bytes.NewReader(buf.Bytes())); err != ErrSnapshotNotEmpty {
+		t.Fatal(err)
+	}
+}
+
+func TestLedger_SnapshotChecksum(t *testing.T) {
+	teardownTestCase, l := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	if err := l.AddStateBlock(signedOpenBlock()); err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := l.ExportSnapshot(buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	data[len(data)-1] ^= 0xff
+
+	l2 := NewLedgerWithStore(db.NewMemoryStore())
+	defer l2.Close()
+	p := &types.PerformanceTime{Hash: mock.Hash(), T0: 1}
+	if err := l2.AddOrUpdatePerformance(p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l2.ImportSnapshot(bytes.NewReader(data)); err != ErrSnapshotChecksum {
+		t.Fatal(err)
+	}
+	if empty, err := l2.Empty(); err != nil || !empty {
+		t.Fatal("nothing should be written by a failed import", err)
+	}
+	if b, err := l2.IsPerformanceTimeExist(p.Hash); err != nil || !b {
+		t.Fatal("node local records should be kept by a failed import", err)
+	}
+
+	// the records written before a failure are deleted again
+	if err := l2.AddStateBlock(signedOpenBlock()); err != nil {
+		t.Fatal(err)
+	}
+	if err := l2.deleteSnapshotRecords(map[byte]bool{idPrefixBlock: true}); err != nil {
+		t.Fatal(err)
+	}
+	if empty, err := l2.Empty(); err != nil || !empty {
+		t.Fatal("records of the snapshot should be deleted", err)
+	}
+	if b, err := l2.IsPerformanceTimeExist(p.Hash); err != nil || !b {
+		t.Fatal("node local records should be kept", err)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/qlcchain/go-qlc/common"
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/common/util"
 	"github.com/qlcchain/go-qlc/config"
@@ -78,6 +79,20 @@ func TestLedger_Instance2(t *testing.T) {
 	}
 }
 
+func TestNewLedgerWithStore(t *testing.T) {
+	l := NewLedgerWithStore(db.NewMemoryStore())
+	defer l.Close()
+
+	block := addStateBlock(t, l)
+	blk, err := l.GetStateBlock(block.GetHash())
+	if err != nil || blk == nil {
+		t.Fatal(err)
+	}
+	if b, err := l.HasStateBlock(mock.Hash()); b || err != nil {
+		t.Fatal("block should not exist", err)
+	}
+}
+
 func TestGetTxn(t *testing.T) {
 	teardownTestCase, l := setupTestCase(t)
 	defer teardownTestCase(t)
@@ -202,6 +217,23 @@ func TestLedger_HasSmartContrantBlock(t *testing.T) {
 	}
 }
 
+func TestLedger_GetSmartContractBlockByAddress(t *testing.T) {
+	teardownTestCase, l := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	block := addSmartContractBlock(t, l)
+	blk, err := l.GetSmartContractBlockByAddress(block.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blk.GetHash() != block.GetHash() {
+		t.Fatal("invalid smart contract block")
+	}
+	if _, err := l.GetSmartContractBlockByAddress(mock.Address()); err != ErrBlockNotFound {
+		t.Fatal(err)
+	}
+}
+
 func TestLedger_GetSmartContrantBlocks(t *testing.T) {
 	teardownTestCase, l := setupTestCase(t)
 	defer teardownTestCase(t)
@@ -732,6 +764,13 @@ func TestLedger_SearchPending(t *testing.T) {
 		//t.Log(idx, util.ToString(k), util.ToString(v))
 	}
 	//t.Log("build cache done")
+	// pending blocks of other accounts are not searched
+	for idx := 0; idx < 5; idx++ {
+		k := &types.PendingKey{Address: mock.Address(), Hash: mock.Hash()}
+		if err := l.AddPending(k, &types.PendingInfo{Source: address, Amount: types.ZeroBalance, Type: mock.Hash()}); err != nil {
+			t.Fatal(err)
+		}
+	}
 
 	counter := 0
 	err := l.SearchPending(address, func(key *types.PendingKey, value *types.PendingInfo) error {
@@ -1058,6 +1097,41 @@ func TestLedger_SetOnlineRepresentations(t *testing.T) {
 	}
 }
 
+func TestLedger_AddElectionInfo(t *testing.T) {
+	teardownTestCase, l := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	winner := mock.Hash()
+	loser := mock.Hash()
+	info := &types.ElectionInfo{
+		Root:   mock.Hash(),
+		Winner: winner,
+		Losers: []types.Hash{loser},
+		Tally:  types.StringToBalance("100"),
+		Quorum: types.StringToBalance("50"),
+		Votes: []*types.ElectionVote{
+			{Representative: mock.Address(), Hash: winner, Weight: types.StringToBalance("100")},
+		},
+		Confirmed:     true,
+		ConfirmedTime: time.Now().Unix(),
+	}
+	if err := l.AddElectionInfo(info); err != nil {
+		t.Fatal(err)
+	}
+	for _, hash := range []types.Hash{winner, loser} {
+		i, err := l.GetElectionInfo(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i.Winner != winner || !i.Tally.Equal(info.Tally) || len(i.Votes) != 1 || !i.Votes[0].Weight.Equal(info.Votes[0].Weight) {
+			t.Fatal("invalid election info", i)
+		}
+	}
+	if _, err := l.GetElectionInfo(mock.Hash()); err != ErrElectionNotFound {
+		t.Fatal(err)
+	}
+}
+
 func TestLedger_BlockChild(t *testing.T) {
 	teardownTestCase, l := setupTestCase(t)
 	defer teardownTestCase(t)
@@ -1144,3 +1218,266 @@ func TestLedger_MessageInfo(t *testing.T) {
 		t.Fatal("wrong result")
 	}
 }
+
+func TestLedger_BannedPeers(t *testing.T) {
+	teardownTestCase, l := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	peerID := "QmdFSukPUMF3t1JxjvTo14SEEb5JV9JBT6PukGRo6A2g4f"
+	if err := l.AddBannedPeer(peerID); err != nil {
+		t.Fatal(err)
+	}
+	if banned, err := l.IsBannedPeer(peerID); err != nil || !banned {
+		t.Fatal("peer should be banned", err)
+	}
+	peers, err := l.GetBannedPeers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 || peers[peerID] == 0 {
+		t.Fatal("invalid banned peers", peers)
+	}
+	if err := l.DeleteBannedPeer(peerID); err != nil {
+		t.Fatal(err)
+	}
+	if banned, err := l.IsBannedPeer(peerID); err != nil || banned {
+		t.Fatal("peer should be unbanned", err)
+	}
+}
+
+func TestLedger_SyncProgress(t *testing.T) {
+	teardownTestCase, l := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	if p, err := l.GetSyncProgress(); err != nil || p != nil {
+		t.Fatal("there should be no sync progress", p, err)
+	}
+	progress := &types.SyncProgress{
+		StartTime: time.Now().Unix(),
+		Ranges:    []*types.SyncRange{{Index: 0, Done: true}, {Index: 1, Peer: "QmdFSukPUMF3t1JxjvTo14SEEb5JV9JBT6PukGRo6A2g4f"}},
+	}
+	progress.Ranges[1].Start[0] = 0x80
+	if err := l.SetSyncProgress(progress); err != nil {
+		t.Fatal(err)
+	}
+	p, err := l.GetSyncProgress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.StartTime != progress.StartTime || len(p.Ranges) != 2 || p.Ranges[1].Start != progress.Ranges[1].Start ||
+		p.Ranges[1].Peer != progress.Ranges[1].Peer || p.DoneCount() != 1 || p.Finished() {
+		t.Fatal("invalid sync progress", p)
+	}
+	if err := l.DeleteSyncProgress(); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := l.GetSyncProgress(); err != nil || p != nil {
+		t.Fatal("sync progress should be deleted", p, err)
+	}
+}
+
+func TestLedger_Prune(t *testing.T) {
+	teardownTestCase, l := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	var blocks []*types.StateBlock
+	for i := 0; i < 6; i++ {
+		blk := mock.StateBlockWithoutWork()
+		if i == 0 {
+			blk.Previous = types.ZeroHash
+		} else {
+			blk.Type = types.Send
+			blk.Address = blocks[0].Address
+			blk.Previous = blocks[i-1].GetHash()
+		}
+		if err := l.AddStateBlock(blk); err != nil {
+			t.Fatal(err)
+		}
+		blocks = append(blocks, blk)
+	}
+	pendingKey := &types.PendingKey{Address: types.Address(blocks[1].Link), Hash: blocks[1].GetHash()}
+	if err := l.AddPending(pendingKey, &types.PendingInfo{Source: blocks[0].Address, Amount: types.ZeroBalance, Type: blocks[1].Token}); err != nil {
+		t.Fatal(err)
+	}
+	am := mock.AccountMeta(blocks[0].Address)
+	am.Tokens = am.Tokens[:1]
+	am.Tokens[0].Header = blocks[5].GetHash()
+	if err := l.AddAccountMeta(am); err != nil {
+		t.Fatal(err)
+	}
+
+	if pt, err := l.PrunedTime(); err != nil || pt != 0 {
+		t.Fatal("ledger should not be pruned", pt, err)
+	}
+	n, err := l.Prune(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the latest 2 blocks and the previous block of the oldest of them are kept
+	if n != 2 {
+		t.Fatal("invalid pruned count", n)
+	}
+	for i, blk := range blocks {
+		exist, err := l.HasStateBlock(blk.GetHash())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keep := i == 1 || i >= 3; exist != keep {
+			t.Fatal("invalid block", i, exist)
+		}
+	}
+	if child, err := l.GetChild(blocks[3].GetHash(), blocks[3].Address); err != nil || child != blocks[4].GetHash() {
+		t.Fatal("invalid child", child, err)
+	}
+	if _, err := l.GetChild(blocks[2].GetHash(), blocks[2].Address); err == nil {
+		t.Fatal("children of the pruned block should be deleted")
+	}
+	if pt, err := l.PrunedTime(); err != nil || pt == 0 {
+		t.Fatal("invalid pruned time", pt, err)
+	}
+
+	// the kept send is pruned once it is received
+	if err := l.DeletePending(pendingKey); err != nil {
+		t.Fatal(err)
+	}
+	if n, err = l.Prune(2); err != nil || n != 1 {
+		t.Fatal("invalid pruned count", n, err)
+	}
+	if exist, err := l.HasStateBlock(blocks[1].GetHash()); err != nil || exist {
+		t.Fatal("received send should be pruned", exist, err)
+	}
+	if n, err = l.Prune(2); err != nil || n != 0 {
+		t.Fatal("nothing should be pruned", n, err)
+	}
+}
+
+func TestLedger_VmLogs(t *testing.T) {
+	teardownTestCase, l := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	send := mock.StateBlockWithoutWork()
+	send.Type = types.ContractSend
+	send.Previous = types.ZeroHash
+	send.Link = types.MintageAddress.ToHash()
+	if err := l.AddStateBlock(send); err != nil {
+		t.Fatal(err)
+	}
+	reward := mock.StateBlockWithoutWork()
+	reward.Type = types.ContractReward
+	reward.Previous = types.ZeroHash
+	reward.Link = send.GetHash()
+	hash := reward.GetHash()
+
+	if _, err := l.GetVmLogs(hash); err != ErrVmLogsNotFound {
+		t.Fatal(err)
+	}
+	logs := &types.VmLogs{Logs: []*types.VmLog{
+		{Topics: []types.Hash{mock.Hash(), mock.Hash()}, Data: []byte{1, 2, 3}},
+		{Topics: []types.Hash{mock.Hash()}},
+	}}
+	if err := l.AddVmLogs(hash, logs); err != nil {
+		t.Fatal(err)
+	}
+	if l2, err := l.GetVmLogs(hash); err != nil || !reflect.DeepEqual(l2.Hash(), logs.Hash()) {
+		t.Fatal("invalid logs", l2, err)
+	}
+
+	// the logs are published when the reward block is added
+	published := make(chan types.Address, 1)
+	handler := func(address types.Address, block *types.StateBlock, logs *types.VmLogs) {
+		if block.GetHash() == hash && len(logs.Logs) == 2 {
+			published <- address
+		}
+	}
+	if err := l.eb.Subscribe(string(common.EventAddVmLogs), handler); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = l.eb.Unsubscribe(string(common.EventAddVmLogs), handler)
+	}()
+	if err := l.AddStateBlock(reward); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case address := <-published:
+		if address != types.MintageAddress {
+			t.Fatal("invalid contract address", address)
+		}
+	default:
+		t.Fatal("logs are not published")
+	}
+
+	if err := l.DeleteVmLogs(hash); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.GetVmLogs(hash); err != ErrVmLogsNotFound {
+		t.Fatal(err)
+	}
+}
+
+func TestLedger_UpdateContractRoot(t *testing.T) {
+	teardownTestCase, l := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	address := mock.Address()
+	previous, reward, root := mock.Hash(), mock.Hash(), mock.Hash()
+	if err := l.SetContractRoot(address, previous); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.UpdateContractRoot(address, reward, root); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := l.GetContractRoot(address); err != nil || r != root {
+		t.Fatal(r, err)
+	}
+
+	err := l.BatchUpdate(func(txn db.StoreTxn) error {
+		return l.rollBackContractRoot(address, reward, txn)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, err := l.GetContractRoot(address); err != nil || r != previous {
+		t.Fatal(r, err)
+	}
+}
+
+func TestMigrationV5ToV6(t *testing.T) {
+	teardownTestCase, l := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	pk := types.PendingKey{Address: mock.Address(), Hash: mock.Hash()}
+	pi := &types.PendingInfo{Source: mock.Address(), Amount: types.Balance{Int: big.NewInt(10)}, Type: mock.Hash()}
+	err := l.BatchUpdate(func(txn db.StoreTxn) error {
+		msg, _ := pk.MarshalMsg(nil)
+		val, _ := pi.MarshalMsg(nil)
+		if err := txn.Set(append([]byte{idPrefixPending}, msg...), val); err != nil {
+			return err
+		}
+		if err := setVersion(5, txn); err != nil {
+			return err
+		}
+		return MigrationV5ToV6{}.Migrate(txn)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := l.GetPending(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Source != pi.Source || !info.Amount.Equal(pi.Amount) {
+		t.Fatal("invalid pending info", util.ToString(info))
+	}
+	count := 0
+	if err := l.GetPendings(func(key *types.PendingKey, info *types.PendingInfo) error {
+		count++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatal("old pending key is not removed", count)
+	}
+}
@@ -0,0 +1,399 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package ledger
+
+import (
+	"fmt"
+
+	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/ledger/db"
+)
+
+// kinds of the inconsistencies found by Verify
+const (
+	InconsistentBlockHash      = "blockHash"
+	InconsistentSignature      = "signature"
+	InconsistentPrevious       = "previous"
+	InconsistentTokenMeta      = "tokenMeta"
+	InconsistentFrontier       = "frontier"
+	InconsistentPending        = "pending"
+	InconsistentRepresentation = "representation"
+	InconsistentRelation       = "relation"
+)
+
+// Inconsistency is a problem found in the ledger, Repaired is set if the problem has been fixed
+type Inconsistency struct {
+	Kind     string        `json:"kind"`
+	Hash     types.Hash    `json:"hash"`
+	Address  types.Address `json:"address"`
+	Detail   string        `json:"detail"`
+	Repaired bool          `json:"repaired"`
+}
+
+func (i *Inconsistency) String() string {
+	s := fmt.Sprintf("[%s] %s", i.Kind, i.Detail)
+	if i.Repaired {
+		s += " (repaired)"
+	}
+	return s
+}
+
+// VerifyReport is the result of Verify
+type VerifyReport struct {
+	Blocks          int              `json:"blocks"`
+	Accounts        int              `json:"accounts"`
+	Inconsistencies []*Inconsistency `json:"inconsistencies"`
+}
+
+func (r *VerifyReport) add(kind string, hash types.Hash, address types.Address, repaired bool, format string, a ...interface{}) {
+	r.Inconsistencies = append(r.Inconsistencies, &Inconsistency{
+		Kind:     kind,
+		Hash:     hash,
+		Address:  address,
+		Detail:   fmt.Sprintf(format, a...),
+		Repaired: repaired,
+	})
+}
+
+// Verify walks all the blocks and the account chains of the ledger and checks the block hashes and signatures, the
+// previous links, the token metas against the frontiers, the pending entries against the unreceived sends and the
+// representations against the account balances. If repair is set the frontiers, the pending entries and the
+// representations are rebuilt from the account chains, the broken blocks are only reported.
+func (l *Ledger) Verify(repair bool) (*VerifyReport, error) {
+	report := new(VerifyReport)
+	prunedTime, err := l.PrunedTime()
+	if err != nil {
+		return nil, err
+	}
+	pruned := prunedTime > 0
+
+	// the sends received by a receive block, the open block of a contract chain receives a contract send
+	received := make(map[types.Hash]bool)
+	var sends []*types.StateBlock
+	txn, flag := l.getTxn(false)
+	err = txn.Iterator(idPrefixBlock, func(key []byte, val []byte, b byte) error {
+		hash, err := types.BytesToHash(key[1:])
+		if err != nil {
+			return err
+		}
+		blk := new(types.StateBlock)
+		if err := blk.Deserialize(val); err != nil {
+			return err
+		}
+		report.Blocks++
+		if h := blk.GetHash(); h != hash {
+			report.add(InconsistentBlockHash, hash, blk.Address, false, "block %s is stored as %s", h, hash)
+			return nil
+		}
+		if !common.IsGenesisBlock(blk) {
+			signature := blk.GetSignature()
+			if !blk.Address.Verify(hash[:], signature[:]) {
+				report.add(InconsistentSignature, hash, blk.Address, false, "invalid signature of block %s", hash)
+			}
+		}
+		switch blk.Type {
+		case types.Open, types.Receive, types.ContractReward, types.ContractRefund:
+			received[blk.Link] = true
+		case types.Send:
+			sends = append(sends, blk)
+		}
+		return nil
+	})
+	l.releaseTxn(txn, flag)
+	if err != nil {
+		return nil, err
+	}
+
+	var metas []*types.AccountMeta
+	err = l.GetAccountMetas(func(am *types.AccountMeta) error {
+		metas = append(metas, am)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Accounts = len(metas)
+
+	headers := make(map[types.Hash]*types.TokenMeta)
+	for _, am := range metas {
+		for _, tm := range am.Tokens {
+			headers[tm.Header] = tm
+			if err := l.verifyTokenChain(am.Address, tm, pruned, report); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := l.verifyFrontiers(headers, repair, report); err != nil {
+		return nil, err
+	}
+	if err := l.verifyPendings(sends, received, pruned, repair, report); err != nil {
+		return nil, err
+	}
+	if err := l.verifyRepresentations(metas, repair, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// verifyTokenChain walks the token chain back from the header to the open block
+func (l *Ledger) verifyTokenChain(address types.Address, tm *types.TokenMeta, pruned bool, report *VerifyReport) error {
+	header, err := l.GetStateBlock(tm.Header)
+	if err != nil {
+		if err == ErrBlockNotFound {
+			report.add(InconsistentTokenMeta, tm.Header, address, false, "header %s of token %s of %s not found",
+				tm.Header, tm.Type, address)
+			return nil
+		}
+		return err
+	}
+	if header.Balance.Compare(tm.Balance) != types.BalanceCompEqual || header.Representative != tm.Representative {
+		report.add(InconsistentTokenMeta, tm.Header, address, false,
+			"token %s of %s does not match header %s, balance %s, representative %s", tm.Type, address, tm.Header,
+			tm.Balance, tm.Representative)
+	}
+
+	var count int64
+	visited := make(map[types.Hash]bool)
+	blk := header
+	for {
+		hash := blk.GetHash()
+		count++
+		visited[hash] = true
+		if blk.Address != address || blk.Token != tm.Type {
+			report.add(InconsistentPrevious, hash, address, false, "block %s of %s token %s is not on chain of %s token %s",
+				hash, blk.Address, blk.Token, address, tm.Type)
+			return nil
+		}
+		previous := blk.GetPrevious()
+		if previous.IsZero() {
+			break
+		}
+		if visited[previous] {
+			report.add(InconsistentPrevious, hash, address, false, "chain of %s token %s has a loop at %s", address,
+				tm.Type, previous)
+			return nil
+		}
+		prev, err := l.GetStateBlock(previous)
+		if err != nil {
+			if err != ErrBlockNotFound {
+				return err
+			}
+			// the history of a pruned ledger ends at the first pruned block
+			if !pruned {
+				report.add(InconsistentPrevious, hash, address, false, "previous %s of block %s not found", previous, hash)
+			}
+			return nil
+		}
+		blk = prev
+	}
+
+	if open := blk.GetHash(); open != tm.OpenBlock {
+		report.add(InconsistentTokenMeta, open, address, false, "open block of token %s of %s is %s, expect %s",
+			tm.Type, address, tm.OpenBlock, open)
+	}
+	if count != tm.BlockCount {
+		report.add(InconsistentTokenMeta, tm.Header, address, false, "token %s of %s has %d blocks, expect %d",
+			tm.Type, address, tm.BlockCount, count)
+	}
+	return nil
+}
+
+// verifyFrontiers checks that every token chain has exactly one frontier pointing to its header
+func (l *Ledger) verifyFrontiers(headers map[types.Hash]*types.TokenMeta, repair bool, report *VerifyReport) error {
+	frontiers, err := l.GetFrontiers()
+	if err != nil {
+		return err
+	}
+	return l.BatchUpdate(func(txn db.StoreTxn) error {
+		found := make(map[types.Hash]bool)
+		for _, f := range frontiers {
+			tm, ok := headers[f.HeaderBlock]
+			if !ok {
+				if repair {
+					if err := l.DeleteFrontier(f.HeaderBlock, txn); err != nil {
+						return err
+					}
+				}
+				report.add(InconsistentFrontier, f.HeaderBlock, types.ZeroAddress, repair, "frontier %s is not a header",
+					f.HeaderBlock)
+				continue
+			}
+			found[f.HeaderBlock] = true
+			if f.OpenBlock != tm.OpenBlock {
+				if repair {
+					if err := txn.Set(getKeyOfHash(f.HeaderBlock, idPrefixFrontier), tm.OpenBlock[:]); err != nil {
+						return err
+					}
+				}
+				report.add(InconsistentFrontier, f.HeaderBlock, tm.BelongTo, repair, "open block of frontier %s is %s, expect %s",
+					f.HeaderBlock, f.OpenBlock, tm.OpenBlock)
+			}
+		}
+		for header, tm := range headers {
+			if found[header] {
+				continue
+			}
+			if repair {
+				if err := l.AddFrontier(&types.Frontier{HeaderBlock: header, OpenBlock: tm.OpenBlock}, txn); err != nil {
+					return err
+				}
+			}
+			report.add(InconsistentFrontier, header, tm.BelongTo, repair, "frontier of header %s not found", header)
+		}
+		return nil
+	})
+}
+
+// verifyPendings checks that the pending entries are exactly the unreceived sends, the receive blocks of a pruned
+// ledger may be gone so the missing pending entries are not checked for it
+func (l *Ledger) verifyPendings(sends []*types.StateBlock, received map[types.Hash]bool, pruned, repair bool, report *VerifyReport) error {
+	type pending struct {
+		key  types.PendingKey
+		info types.PendingInfo
+	}
+	var pendings []pending
+	err := l.GetPendings(func(key *types.PendingKey, info *types.PendingInfo) error {
+		pendings = append(pendings, pending{key: *key, info: *info})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return l.BatchUpdate(func(txn db.StoreTxn) error {
+		found := make(map[types.PendingKey]bool)
+		for _, p := range pendings {
+			found[p.key] = true
+			var detail string
+			send, err := l.GetStateBlock(p.key.Hash, txn)
+			switch {
+			case err == ErrBlockNotFound:
+				detail = fmt.Sprintf("send %s of pending of %s not found", p.key.Hash, p.key.Address)
+			case err != nil:
+				return err
+			case received[p.key.Hash]:
+				detail = fmt.Sprintf("send %s of pending of %s has been received", p.key.Hash, p.key.Address)
+			case send.Type == types.Send && send.Link != p.key.Address.ToHash():
+				detail = fmt.Sprintf("send %s is not sent to %s", p.key.Hash, p.key.Address)
+			default:
+				continue
+			}
+			if repair {
+				if err := l.DeletePending(&p.key, txn); err != nil {
+					return err
+				}
+			}
+			report.add(InconsistentPending, p.key.Hash, p.key.Address, repair, "%s", detail)
+		}
+		if pruned {
+			return nil
+		}
+
+		for _, send := range sends {
+			key := types.PendingKey{Address: types.Address(send.Link), Hash: send.GetHash()}
+			if received[key.Hash] || found[key] {
+				continue
+			}
+			fixed := false
+			if repair {
+				prev, err := l.GetStateBlock(send.Previous, txn)
+				if err != nil && err != ErrBlockNotFound {
+					return err
+				}
+				if prev != nil {
+					info := &types.PendingInfo{Source: send.Address, Type: send.Token, Amount: prev.Balance.Sub(send.Balance)}
+					if err := l.AddPending(&key, info, txn); err != nil {
+						return err
+					}
+					fixed = true
+				}
+			}
+			report.add(InconsistentPending, key.Hash, key.Address, fixed, "pending of unreceived send %s to %s not found",
+				key.Hash, key.Address)
+		}
+		return nil
+	})
+}
+
+// verifyRepresentations sums the chain token balances of the accounts by their representatives
+func (l *Ledger) verifyRepresentations(metas []*types.AccountMeta, repair bool, report *VerifyReport) error {
+	expected := make(map[types.Address]*types.Benefit)
+	for _, am := range metas {
+		tm := am.Token(common.ChainToken())
+		if tm == nil || tm.Representative.IsZero() {
+			continue
+		}
+		b, ok := expected[tm.Representative]
+		if !ok {
+			b = &types.Benefit{
+				Balance: types.ZeroBalance,
+				Vote:    types.ZeroBalance,
+				Network: types.ZeroBalance,
+				Storage: types.ZeroBalance,
+				Oracle:  types.ZeroBalance,
+				Total:   types.ZeroBalance,
+			}
+			expected[tm.Representative] = b
+		}
+		b.Balance = b.Balance.Add(am.GetBalance())
+		b.Vote = b.Vote.Add(am.GetVote())
+		b.Network = b.Network.Add(am.GetNetwork())
+		b.Storage = b.Storage.Add(am.GetStorage())
+		b.Oracle = b.Oracle.Add(am.GetOracle())
+		b.Total = b.Total.Add(am.TotalBalance())
+	}
+
+	stored := make(map[types.Address]*types.Benefit)
+	err := l.GetRepresentations(func(address types.Address, benefit *types.Benefit) error {
+		stored[address] = benefit
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return l.BatchUpdate(func(txn db.StoreTxn) error {
+		for address, b := range stored {
+			if _, ok := expected[address]; ok || b.Total.Compare(types.ZeroBalance) == types.BalanceCompEqual {
+				continue
+			}
+			if repair {
+				if err := txn.Delete(getRepresentationKey(address)); err != nil {
+					return err
+				}
+			}
+			report.add(InconsistentRepresentation, types.ZeroHash, address, repair,
+				"representation of %s is %s, expect 0", address, b.Total)
+		}
+		for address, b := range expected {
+			s, ok := stored[address]
+			if ok && s.Balance.Compare(b.Balance) == types.BalanceCompEqual && s.Vote.Compare(b.Vote) == types.BalanceCompEqual &&
+				s.Network.Compare(b.Network) == types.BalanceCompEqual && s.Storage.Compare(b.Storage) == types.BalanceCompEqual &&
+				s.Oracle.Compare(b.Oracle) == types.BalanceCompEqual && s.Total.Compare(b.Total) == types.BalanceCompEqual {
+				continue
+			}
+			if repair {
+				val, err := b.MarshalMsg(nil)
+				if err != nil {
+					return err
+				}
+				if err := txn.Set(getRepresentationKey(address), val); err != nil {
+					return err
+				}
+			}
+			actual := types.ZeroBalance
+			if ok {
+				actual = s.Total
+			}
+			report.add(InconsistentRepresentation, types.ZeroHash, address, repair, "representation of %s is %s, expect %s",
+				address, actual, b.Total)
+		}
+		return nil
+	})
+}
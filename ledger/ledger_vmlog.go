@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package ledger
+
+import (
+	"errors"
+
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/ledger/db"
+)
+
+var ErrVmLogsNotFound = errors.New("vm logs not found")
+
+// AddVmLogs saves the logs emitted by the contract execution which generates the block of hash, the logs are
+// published with EventAddVmLogs when the block is added to the ledger
+func (l *Ledger) AddVmLogs(hash types.Hash, logs *types.VmLogs, txns ...db.StoreTxn) error {
+	txn, flag := l.getTxn(true, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	val, err := logs.MarshalMsg(nil)
+	if err != nil {
+		return err
+	}
+	return txn.Set(getKeyOfHash(hash, idPrefixVmLogs), val)
+}
+
+func (l *Ledger) GetVmLogs(hash types.Hash, txns ...db.StoreTxn) (*types.VmLogs, error) {
+	txn, flag := l.getTxn(false, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	logs := new(types.VmLogs)
+	err := txn.Get(getKeyOfHash(hash, idPrefixVmLogs), func(val []byte, b byte) error {
+		_, err := logs.UnmarshalMsg(val)
+		return err
+	})
+	if err != nil {
+		if err == db.ErrKeyNotFound {
+			return nil, ErrVmLogsNotFound
+		}
+		return nil, err
+	}
+	return logs, nil
+}
+
+func (l *Ledger) DeleteVmLogs(hash types.Hash, txns ...db.StoreTxn) error {
+	txn, flag := l.getTxn(true, txns...)
+	defer l.releaseTxn(txn, flag)
+
+	return txn.Delete(getKeyOfHash(hash, idPrefixVmLogs))
+}
+
+// contractLogs returns the contract address and the logs of the ContractReward block, the logs are nil if the
+// contract emits nothing
+func (l *Ledger) contractLogs(blk *types.StateBlock, txn db.StoreTxn) (types.Address, *types.VmLogs, error) {
+	if blk.GetType() != types.ContractReward {
+		return types.ZeroAddress, nil, nil
+	}
+	logs, err := l.GetVmLogs(blk.GetHash(), txn)
+	if err != nil {
+		if err == ErrVmLogsNotFound {
+			return types.ZeroAddress, nil, nil
+		}
+		return types.ZeroAddress, nil, err
+	}
+	send, err := l.GetStateBlock(blk.GetLink(), txn)
+	if err != nil {
+		return types.ZeroAddress, nil, err
+	}
+	return types.Address(send.GetLink()), logs, nil
+}
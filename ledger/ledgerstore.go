@@ -42,6 +42,7 @@ type Store interface {
 	AddSmartContractBlock(blk *types.SmartContractBlock, txns ...db.StoreTxn) error
 	GetSmartContractBlock(hash types.Hash, txns ...db.StoreTxn) (*types.SmartContractBlock, error)
 	HasSmartContractBlock(hash types.Hash, txns ...db.StoreTxn) (bool, error)
+	GetSmartContractBlockByAddress(address types.Address, txns ...db.StoreTxn) (*types.SmartContractBlock, error)
 	GetSmartContractBlocks(fn func(block *types.SmartContractBlock) error, txns ...db.StoreTxn) error
 	CountSmartContractBlocks(txns ...db.StoreTxn) (uint64, error)
 	// representation CURD
@@ -77,6 +78,35 @@ type Store interface {
 	PerformanceTimes(fn func(*types.PerformanceTime), txns ...db.StoreTxn) error
 	GetPerformanceTime(hash types.Hash, txns ...db.StoreTxn) (*types.PerformanceTime, error)
 	IsPerformanceTimeExist(hash types.Hash, txns ...db.StoreTxn) (bool, error)
+	// election
+	AddElectionInfo(info *types.ElectionInfo, txns ...db.StoreTxn) error
+	GetElectionInfo(hash types.Hash, txns ...db.StoreTxn) (*types.ElectionInfo, error)
+	// banned peers
+	AddBannedPeer(peerID string, txns ...db.StoreTxn) error
+	DeleteBannedPeer(peerID string, txns ...db.StoreTxn) error
+	IsBannedPeer(peerID string, txns ...db.StoreTxn) (bool, error)
+	GetBannedPeers(txns ...db.StoreTxn) (map[string]int64, error)
+	GetSyncProgress(txns ...db.StoreTxn) (*types.SyncProgress, error)
+	SetSyncProgress(progress *types.SyncProgress, txns ...db.StoreTxn) error
+	DeleteSyncProgress(txns ...db.StoreTxn) error
+	Prune(depth int) (int, error)
+	PrunedTime(txns ...db.StoreTxn) (int64, error)
+	Verify(repair bool) (*VerifyReport, error)
+	// contract refunds
+	AddRefund(send, refund types.Hash, txns ...db.StoreTxn) error
+	GetRefund(send types.Hash, txns ...db.StoreTxn) (types.Hash, error)
+	DeleteRefund(send types.Hash, txns ...db.StoreTxn) error
+	AddReward(send, reward types.Hash, txns ...db.StoreTxn) error
+	GetReward(send types.Hash, txns ...db.StoreTxn) (types.Hash, error)
+	DeleteReward(send types.Hash, txns ...db.StoreTxn) error
+	// contract logs
+	AddVmLogs(hash types.Hash, logs *types.VmLogs, txns ...db.StoreTxn) error
+	GetVmLogs(hash types.Hash, txns ...db.StoreTxn) (*types.VmLogs, error)
+	DeleteVmLogs(hash types.Hash, txns ...db.StoreTxn) error
+	// contract state roots
+	GetContractRoot(address types.Address, txns ...db.StoreTxn) (types.Hash, error)
+	SetContractRoot(address types.Address, root types.Hash, txns ...db.StoreTxn) error
+	UpdateContractRoot(address types.Address, reward, root types.Hash, txns ...db.StoreTxn) error
 
 	//Latest block hash by account and token type, if not exist, return zero hash
 	Latest(account types.Address, token types.Hash, txns ...db.StoreTxn) types.Hash
@@ -101,6 +131,9 @@ type Store interface {
 	GenerateSendBlock(block *types.StateBlock, amount types.Balance, prk ed25519.PrivateKey) (*types.StateBlock, error)
 	GenerateReceiveBlock(sendBlock *types.StateBlock, prk ed25519.PrivateKey) (*types.StateBlock, error)
 	GenerateChangeBlock(account types.Address, representative types.Address, prk ed25519.PrivateKey) (*types.StateBlock, error)
+	GenerateUnsignedSendBlock(block *types.StateBlock, amount types.Balance) (*types.StateBlock, error)
+	GenerateUnsignedReceiveBlock(sendBlock *types.StateBlock) (*types.StateBlock, error)
+	GenerateUnsignedChangeBlock(account types.Address, representative types.Address) (*types.StateBlock, error)
 
 	//Token
 	//ListTokens(txns ...db.StoreTxn) ([]*types.TokenInfo, error)
@@ -0,0 +1,537 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package process
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/common/util"
+	"github.com/qlcchain/go-qlc/ledger"
+	"github.com/qlcchain/go-qlc/test/mock"
+	"github.com/qlcchain/go-qlc/vm/abi"
+	"github.com/qlcchain/go-qlc/vm/contract"
+	cabi "github.com/qlcchain/go-qlc/vm/contract/abi"
+	"github.com/qlcchain/go-qlc/vm/engine"
+	"github.com/qlcchain/go-qlc/vm/vmstore"
+)
+
+// testContractCode is a wasm module which exports `get`, it takes the pointer of the arguments and returns 7
+//
+//	(module
+//	 (memory 1)
+//	 (func $get (param i32) (result i32) (i32.const 7))
+//	 (export "get" (func $get)))
+var testContractCode = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	0x01, 0x06, 0x01, 0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x03, 0x02, 0x01, 0x00,
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	0x07, 0x07, 0x01, 0x03, 0x67, 0x65, 0x74, 0x00, 0x00,
+	0x0a, 0x06, 0x01, 0x04, 0x00, 0x41, 0x07, 0x0b,
+}
+
+// testContractModule builds a module like testContractCode with the body of `get`
+func testContractModule(body ...byte) []byte {
+	code := append([]byte{}, testContractCode[:34]...)
+	code = append(code, 0x0a, byte(len(body)+2), 0x01, byte(len(body)))
+	return append(code, body...)
+}
+
+const testContractSchema = `[{"type":"function","name":"get","inputs":[]}]`
+
+func testContractBlock(t *testing.T, code []byte) *types.SmartContractBlock {
+	account := mock.Account()
+	hash, err := types.HashBytes(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &types.SmartContractBlock{
+		Address:         mock.Address(),
+		InternalAccount: account.Address(),
+		Owner:           account.Address(),
+		Abi:             types.ContractAbi{Abi: code, AbiLength: uint64(len(code)), AbiHash: hash},
+		AbiSchema:       testContractSchema,
+	}
+	h := sc.GetHash()
+	var w types.Work
+	worker, _ := types.NewWorker(w, h)
+	sc.Work = worker.NewWork()
+	sc.Signature = account.Sign(h)
+	return sc
+}
+
+func TestLedgerVerifier_SmartContractBlock(t *testing.T) {
+	teardownTestCase, l, lv := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	sc := testContractBlock(t, testContractCode)
+	if r, err := lv.Process(sc); err != nil || r != Progress {
+		t.Fatal(r, err)
+	}
+	if r, err := lv.BlockCheck(sc); err != nil || r != Old {
+		t.Fatal(r, err)
+	}
+	blk, err := l.GetSmartContractBlockByAddress(sc.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blk.GetHash() != sc.GetHash() {
+		t.Fatal("invalid smart contract block")
+	}
+
+	invalid := testContractBlock(t, []byte{0x00, 0x61, 0x73, 0x6d})
+	if r, err := lv.BlockCheck(invalid); err != nil || r != InvalidData {
+		t.Fatal(r, err)
+	}
+	// a function popping an empty stack fails to compile
+	invalid = testContractBlock(t, testContractModule(0x00, 0x6a, 0x0b))
+	if r, err := lv.BlockCheck(invalid); err != nil || r != InvalidData {
+		t.Fatal(r, err)
+	}
+	invalid = testContractBlock(t, testContractCode)
+	invalid.Signature = mock.Account().Sign(invalid.GetHash())
+	if r, err := lv.BlockCheck(invalid); err != nil || r != BadSignature {
+		t.Fatal(r, err)
+	}
+}
+
+func TestExecuteEngine_Run(t *testing.T) {
+	teardownTestCase, l, _ := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	sc := testContractBlock(t, testContractCode)
+	e, err := engine.NewExecuteEngine(sc.Owner, sc, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := abi.JSONToABIContract(strings.NewReader(testContractSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := schema.PackMethod("get")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := e.Call(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result, util.Int64ToBytes(7)) {
+		t.Fatal("invalid result", result)
+	}
+
+	send := mock.StateBlockWithoutWork()
+	send.Type = types.ContractSend
+	send.Link = sc.Address.ToHash()
+	send.Data = data
+	if _, _, err := e.Run(send, nil); err != nil {
+		t.Fatal(err)
+	}
+	send.Data = []byte{0x01, 0x02, 0x03, 0x04}
+	if _, _, err := e.Run(send, nil); err == nil {
+		t.Fatal("unknown method should be rejected")
+	}
+	send.Link = mock.Hash()
+	send.Data = data
+	if _, _, err := e.Run(send, nil); err == nil {
+		t.Fatal("block to other address should be rejected")
+	}
+
+	// (loop (br 0)) runs until the gas limit is exceeded
+	sc = testContractBlock(t, testContractModule(0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x41, 0x07, 0x0b))
+	e, err = engine.NewExecuteEngine(sc.Owner, sc, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Call(data); err == nil || !strings.Contains(err.Error(), "gas limit exceeded") {
+		t.Fatal("endless contract should exceed the gas limit", err)
+	}
+}
+
+func testStateBlock(account *types.Account, blk *types.StateBlock) *types.StateBlock {
+	blk.Signature = account.Sign(blk.GetHash())
+	var w types.Work
+	worker, _ := types.NewWorker(w, blk.Root())
+	blk.Work = worker.NewWork()
+	return blk
+}
+
+func TestLedgerVerifier_ContractRefund(t *testing.T) {
+	teardownTestCase, l, lv := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	source := mock.StateBlock()
+	source.Previous = types.ZeroHash
+	if err := l.AddStateBlock(source); err != nil {
+		t.Fatal(err)
+	}
+	account := mock.Account()
+	address := account.Address()
+	open := testStateBlock(account, &types.StateBlock{
+		Type:           types.Open,
+		Address:        address,
+		Token:          common.ChainToken(),
+		Balance:        types.Balance{Int: big.NewInt(100000000)},
+		Vote:           types.ZeroBalance,
+		Network:        types.ZeroBalance,
+		Storage:        types.ZeroBalance,
+		Oracle:         types.ZeroBalance,
+		Link:           source.GetHash(),
+		Representative: address,
+	})
+	if err := lv.BlockProcess(open); err != nil {
+		t.Fatal(err)
+	}
+
+	// the pledge is too small to mint the token, the contract can not receive the send
+	data, err := cabi.MintageABI.PackMethod(cabi.MethodNameMintage, cabi.NewTokenHash(address, open.GetHash(), "Refund"),
+		"Refund", "RFD", big.NewInt(1000), uint8(8), address, mock.Hash().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := testStateBlock(account, &types.StateBlock{
+		Type:           types.ContractSend,
+		Address:        address,
+		Token:          common.ChainToken(),
+		Balance:        types.Balance{Int: big.NewInt(99999000)},
+		Vote:           types.ZeroBalance,
+		Network:        types.ZeroBalance,
+		Storage:        types.ZeroBalance,
+		Oracle:         types.ZeroBalance,
+		Previous:       open.GetHash(),
+		Link:           types.Hash(types.MintageAddress),
+		Representative: address,
+		Data:           data,
+	})
+	if err := lv.BlockProcess(send); err != nil {
+		t.Fatal(err)
+	}
+
+	c, _, err := contract.GetChainContract(types.MintageAddress, send.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.DoReceive(vmstore.NewVMContext(l), &types.StateBlock{}, send); err == nil {
+		t.Fatal("mintage with a small pledge should fail")
+	}
+	refund := &types.StateBlock{}
+	if _, err := contract.Refund(vmstore.NewVMContext(l), c, refund, send); err != nil {
+		t.Fatal(err)
+	}
+	if !refund.Balance.Equal(open.Balance) || refund.Type != types.ContractRefund || refund.Link != send.GetHash() {
+		t.Fatal("invalid refund block", refund)
+	}
+
+	invalid := refund.Clone()
+	invalid.Balance = types.Balance{Int: big.NewInt(100000001)}
+	if r, err := lv.BlockCheck(testStateBlock(account, invalid)); err != nil || r != BalanceMismatch {
+		t.Fatal(r, err)
+	}
+	invalid = refund.Clone()
+	invalid.Data = []byte{9}
+	if r, err := lv.BlockCheck(testStateBlock(account, invalid)); err != nil || r != InvalidData {
+		t.Fatal(r, err)
+	}
+
+	refund = testStateBlock(account, refund)
+	if r, err := lv.Process(refund); err != nil || r != Progress {
+		t.Fatal(r, err)
+	}
+	if h, err := l.GetRefund(send.GetHash()); err != nil || h != refund.GetHash() {
+		t.Fatal(h, err)
+	}
+	tm, err := l.GetTokenMeta(address, common.ChainToken())
+	if err != nil || tm.Header != refund.GetHash() || !tm.Balance.Equal(open.Balance) {
+		t.Fatal(tm, err)
+	}
+
+	// the refunded send can not be received by the contract
+	reward := &types.StateBlock{Type: types.ContractReward, Address: mock.Address(), Link: send.GetHash(),
+		Token: common.ChainToken()}
+	if r, err := lv.BlockCheck(testStateBlock(account, reward)); err != nil || r != BadSignature {
+		t.Fatal(r, err)
+	}
+	reward.Address = address
+	if r, err := lv.BlockCheck(testStateBlock(account, reward)); err != nil || r != UnReceivable {
+		t.Fatal(r, err)
+	}
+
+	if err := l.Rollback(refund.GetHash()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.GetRefund(send.GetHash()); err != ledger.ErrRefundNotFound {
+		t.Fatal(err)
+	}
+	tm, err = l.GetTokenMeta(address, common.ChainToken())
+	if err != nil || tm.Header != send.GetHash() || !tm.Balance.Equal(send.Balance) {
+		t.Fatal(tm, err)
+	}
+}
+
+func TestLedgerVerifier_ContractReward(t *testing.T) {
+	teardownTestCase, l, lv := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	source := mock.StateBlock()
+	source.Previous = types.ZeroHash
+	if err := l.AddStateBlock(source); err != nil {
+		t.Fatal(err)
+	}
+	account := mock.Account()
+	address := account.Address()
+	balance := new(big.Int).Mul(contract.MinPledgeAmount, big.NewInt(2))
+	open := testStateBlock(account, &types.StateBlock{
+		Type:           types.Open,
+		Address:        address,
+		Token:          common.ChainToken(),
+		Balance:        types.Balance{Int: balance},
+		Vote:           types.ZeroBalance,
+		Network:        types.ZeroBalance,
+		Storage:        types.ZeroBalance,
+		Oracle:         types.ZeroBalance,
+		Link:           source.GetHash(),
+		Representative: address,
+	})
+	if err := lv.BlockProcess(open); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := cabi.MintageABI.PackMethod(cabi.MethodNameMintage, cabi.NewTokenHash(address, open.GetHash(), "Reward"),
+		"Reward", "RWD", big.NewInt(1000), uint8(8), address, mock.Hash().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := testStateBlock(account, &types.StateBlock{
+		Type:           types.ContractSend,
+		Address:        address,
+		Token:          common.ChainToken(),
+		Balance:        types.Balance{Int: new(big.Int).Sub(balance, contract.MinPledgeAmount)},
+		Vote:           types.ZeroBalance,
+		Network:        types.ZeroBalance,
+		Storage:        types.ZeroBalance,
+		Oracle:         types.ZeroBalance,
+		Previous:       open.GetHash(),
+		Link:           types.Hash(types.MintageAddress),
+		Representative: address,
+		Data:           data,
+	})
+	if err := lv.BlockProcess(send); err != nil {
+		t.Fatal(err)
+	}
+
+	c, _, err := contract.GetChainContract(types.MintageAddress, send.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reward := &types.StateBlock{}
+	g, err := c.DoReceive(vmstore.NewVMContext(l), reward, send)
+	if err != nil || len(g) == 0 {
+		t.Fatal(g, err)
+	}
+	root := g[0].VMContext.ContractRoot(types.MintageAddress)
+	if root.IsZero() {
+		t.Fatal("invalid contract root")
+	}
+
+	// the reward must commit the root of the contract storage with the changes of the receive
+	reward.Timestamp = common.TimeNow().Unix()
+	invalid := reward.Clone()
+	invalid.Extra = mock.Hash()
+	if r, err := lv.BlockCheck(testStateBlock(account, invalid)); err != nil || r != InvalidData {
+		t.Fatal(r, err)
+	}
+	// the root is checked whatever the time of the reward
+	invalid.Timestamp = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	if r, err := lv.BlockCheck(testStateBlock(account, invalid)); err != nil || r != InvalidData {
+		t.Fatal(r, err)
+	}
+
+	reward.Extra = root
+	reward = testStateBlock(account, reward)
+	// the check saves nothing, the contract data and logs are saved with the block
+	if r, err := lv.BlockCheck(reward); err != nil || r != Progress {
+		t.Fatal(r, err)
+	}
+	if _, err := l.GetVmLogs(reward.GetHash()); err != ledger.ErrVmLogsNotFound {
+		t.Fatal(err)
+	}
+	if r, err := lv.Process(reward); err != nil || r != Progress {
+		t.Fatal(r, err)
+	}
+	if logs, err := l.GetVmLogs(reward.GetHash()); err != nil || len(logs.Logs) == 0 {
+		t.Fatal(logs, err)
+	}
+	if h, err := l.GetContractRoot(types.MintageAddress); err != nil || h != root {
+		t.Fatal(h, err)
+	}
+	if h := vmstore.NewVMContext(l).ContractRoot(types.MintageAddress); h != root {
+		t.Fatal("invalid reloaded contract root", h)
+	}
+	// the token info is read from the storage trie at the new root
+	tokenId := cabi.NewTokenHash(address, open.GetHash(), "Reward")
+	if _, err := vmstore.NewVMContext(l).GetStorage(types.MintageAddress[:], tokenId[:]); err != nil {
+		t.Fatal(err)
+	}
+	if h, err := l.GetReward(send.GetHash()); err != nil || h != reward.GetHash() {
+		t.Fatal(h, err)
+	}
+
+	// the receive of the rewarded send fails now, but the send can not be refunded or received again
+	if _, err := c.DoReceive(vmstore.NewVMContext(l), &types.StateBlock{}, send); err == nil {
+		t.Fatal("the send should not be received again")
+	}
+	refund := &types.StateBlock{}
+	if _, err := contract.Refund(vmstore.NewVMContext(l), c, refund, send); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := lv.BlockCheck(testStateBlock(account, refund)); err != nil || r != UnReceivable {
+		t.Fatal(r, err)
+	}
+	again := reward.Clone()
+	again.Previous = reward.GetHash()
+	if r, err := lv.BlockCheck(testStateBlock(account, again)); err != nil || r != UnReceivable {
+		t.Fatal(r, err)
+	}
+
+	if err := l.Rollback(reward.GetHash()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.GetReward(send.GetHash()); err != ledger.ErrRewardNotFound {
+		t.Fatal(err)
+	}
+	if _, err := l.GetVmLogs(reward.GetHash()); err != ledger.ErrVmLogsNotFound {
+		t.Fatal(err)
+	}
+	// the contract had no root before the reward
+	if _, err := l.GetContractRoot(types.MintageAddress); err != ledger.ErrContractRootNotFound {
+		t.Fatal(err)
+	}
+	if _, err := vmstore.NewVMContext(l).GetStorage(types.MintageAddress[:], tokenId[:]); err != vmstore.ErrStorageNotFound {
+		t.Fatal(err)
+	}
+	tm, err := l.GetTokenMeta(address, common.ChainToken())
+	if err != nil || tm.Header != send.GetHash() || !tm.Balance.Equal(send.Balance) {
+		t.Fatal(tm, err)
+	}
+}
+
+type testNEP5Verifier struct {
+	err error
+}
+
+func (v *testNEP5Verifier) Verify(txId string, amount *big.Int, beneficial types.Address) error {
+	return v.err
+}
+
+func TestLedgerVerifier_ContractNEP5Tx(t *testing.T) {
+	teardownTestCase, l, lv := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	source := mock.StateBlock()
+	source.Previous = types.ZeroHash
+	if err := l.AddStateBlock(source); err != nil {
+		t.Fatal(err)
+	}
+	account := mock.Account()
+	address := account.Address()
+	balance := new(big.Int).Mul(contract.MinPledgeAmount, big.NewInt(2))
+	open := testStateBlock(account, &types.StateBlock{
+		Type:           types.Open,
+		Address:        address,
+		Token:          common.ChainToken(),
+		Balance:        types.Balance{Int: balance},
+		Vote:           types.ZeroBalance,
+		Network:        types.ZeroBalance,
+		Storage:        types.ZeroBalance,
+		Oracle:         types.ZeroBalance,
+		Link:           source.GetHash(),
+		Representative: address,
+	})
+	if err := lv.BlockProcess(open); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := cabi.MintageABI.PackMethod(cabi.MethodNameMintage, cabi.NewTokenHash(address, open.GetHash(), "NEP5"),
+		"NEP5", "NEP", big.NewInt(1000), uint8(8), address, mock.Hash().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := testStateBlock(account, &types.StateBlock{
+		Type:           types.ContractSend,
+		Address:        address,
+		Token:          common.ChainToken(),
+		Balance:        types.Balance{Int: new(big.Int).Sub(balance, contract.MinPledgeAmount)},
+		Vote:           types.ZeroBalance,
+		Network:        types.ZeroBalance,
+		Storage:        types.ZeroBalance,
+		Oracle:         types.ZeroBalance,
+		Previous:       open.GetHash(),
+		Link:           types.Hash(types.MintageAddress),
+		Representative: address,
+		Data:           data,
+	})
+	if err := lv.BlockProcess(send); err != nil {
+		t.Fatal(err)
+	}
+
+	c, _, err := contract.GetChainContract(types.MintageAddress, send.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reward := &types.StateBlock{}
+	g, err := c.DoReceive(vmstore.NewVMContext(l), reward, send)
+	if err != nil || len(g) == 0 {
+		t.Fatal(g, err)
+	}
+	reward.Extra = g[0].VMContext.ContractRoot(types.MintageAddress)
+	reward.Timestamp = common.TimeNow().Unix()
+	reward = testStateBlock(account, reward)
+	refund := &types.StateBlock{}
+	if _, err := contract.Refund(vmstore.NewVMContext(l), c, refund, send); err != nil {
+		t.Fatal(err)
+	}
+	refund = testStateBlock(account, refund)
+
+	v := &testNEP5Verifier{}
+	contract.SetNEP5Verifier(v)
+	defer contract.SetNEP5Verifier(nil)
+
+	// the verifier can not be reached, the send is neither received nor refunded
+	v.err = errors.New("timeout")
+	if r, err := lv.BlockCheck(reward); !contract.IsNEP5TxUnverified(err) || r != Other {
+		t.Fatal(r, err)
+	}
+	if r, err := lv.BlockCheck(refund); !contract.IsNEP5TxUnverified(err) || r != Other {
+		t.Fatal(r, err)
+	}
+
+	// the nep5 tx is rejected, the send is refunded
+	v.err = contract.ErrNEP5TxNotFound
+	if r, err := lv.BlockCheck(reward); err != contract.ErrNEP5TxNotFound || r != Other {
+		t.Fatal(r, err)
+	}
+	if r, err := lv.BlockCheck(refund); err != nil || r != Progress {
+		t.Fatal(r, err)
+	}
+
+	// the nep5 tx is verified, the send is received
+	v.err = nil
+	if r, err := lv.BlockCheck(refund); err != nil || r != UnReceivable {
+		t.Fatal(r, err)
+	}
+	if r, err := lv.BlockCheck(reward); err != nil || r != Progress {
+		t.Fatal(r, err)
+	}
+}
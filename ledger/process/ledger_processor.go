@@ -19,6 +19,7 @@ import (
 	"github.com/qlcchain/go-qlc/ledger/db"
 	"github.com/qlcchain/go-qlc/log"
 	"github.com/qlcchain/go-qlc/vm/contract"
+	"github.com/qlcchain/go-qlc/vm/engine"
 	"github.com/qlcchain/go-qlc/vm/vmstore"
 	"go.uber.org/zap"
 )
@@ -34,6 +35,7 @@ func init() {
 	checkBlockFns[types.Open] = checkOpenBlock
 	checkBlockFns[types.ContractSend] = checkContractSendBlock
 	checkBlockFns[types.ContractReward] = checkContractReceiveBlock
+	checkBlockFns[types.ContractRefund] = checkContractRefundBlock
 }
 
 type checkBlock func(*LedgerVerifier, *types.StateBlock) (ProcessResult, error)
@@ -72,12 +74,64 @@ func (lv *LedgerVerifier) BlockCheck(block types.Block) (ProcessResult, error) {
 		} else {
 			return Other, fmt.Errorf("unsupport block type %s", b.Type.String())
 		}
-	} else if _, ok := block.(*types.SmartContractBlock); ok {
-		return Other, errors.New("smart contract block")
+	} else if b, ok := block.(*types.SmartContractBlock); ok {
+		r, err := checkSmartContractBlock(lv, b)
+		if err != nil {
+			lv.logger.Error(fmt.Sprintf("error:%s, smart contract block:%s", err.Error(), b.GetHash().String()))
+		}
+		if r != Progress {
+			lv.logger.Info(fmt.Sprintf("process result:%s, smart contract block:%s", r.String(), b.GetHash().String()))
+		}
+		return r, err
 	}
 	return Other, errors.New("invalid block")
 }
 
+func checkSmartContractBlock(lv *LedgerVerifier, block *types.SmartContractBlock) (ProcessResult, error) {
+	hash := block.GetHash()
+
+	if !block.IsValid() {
+		return BadWork, nil
+	}
+
+	blockExist, err := lv.l.HasSmartContractBlock(hash)
+	if err != nil {
+		return Other, err
+	}
+	if blockExist {
+		return Old, nil
+	}
+
+	if !block.InternalAccount.Verify(hash[:], block.Signature[:]) {
+		return BadSignature, nil
+	}
+
+	//check contract address
+	if contract.IsChainContract(block.Address) {
+		return Other, fmt.Errorf("contract address %s is reserved by chain contract", block.Address.String())
+	}
+	if _, err := lv.l.GetSmartContractBlockByAddress(block.Address); err == nil {
+		return Fork, nil
+	} else if err != ledger.ErrBlockNotFound {
+		return Other, err
+	}
+
+	//check contract code and abi schema
+	code := block.Abi
+	if code.AbiLength != uint64(len(code.Abi)) {
+		return InvalidData, nil
+	}
+	if h, err := types.HashBytes(code.Abi); err != nil || h != code.AbiHash {
+		return InvalidData, nil
+	}
+	if _, err := engine.NewExecuteEngine(block.Owner, block, lv.l); err != nil {
+		lv.logger.Info(err)
+		return InvalidData, nil
+	}
+
+	return Progress, nil
+}
+
 func checkStateBlock(lv *LedgerVerifier, block *types.StateBlock) (ProcessResult, error) {
 	hash := block.GetHash()
 	address := block.GetAddress()
@@ -261,9 +315,14 @@ func checkContractSendBlock(lv *LedgerVerifier, block *types.StateBlock) (Proces
 	address := types.Address(block.GetLink())
 
 	if !contract.IsChainContract(address) {
-		if b, err := lv.l.HasSmartContractBlock(address.ToHash()); !b && err == nil {
-			return GapSmartContract, nil
+		sc, err := lv.l.GetSmartContractBlockByAddress(address)
+		if err != nil {
+			if err == ledger.ErrBlockNotFound {
+				return GapSmartContract, nil
+			}
+			return Other, err
 		}
+		return checkWasmContractSend(lv, block, sc)
 	}
 
 	//verify data
@@ -285,6 +344,19 @@ func checkContractSendBlock(lv *LedgerVerifier, block *types.StateBlock) (Proces
 	}
 }
 
+//checkWasmContractSend runs the contract method called by block in the deployed wasm contract
+func checkWasmContractSend(lv *LedgerVerifier, block *types.StateBlock, sc *types.SmartContractBlock) (ProcessResult, error) {
+	e, err := engine.NewExecuteEngine(block.Address, sc, lv.l)
+	if err != nil {
+		return Other, err
+	}
+	if _, _, err := e.Run(block, nil); err != nil {
+		lv.logger.Info(fmt.Sprintf("run contract %s error: %s", sc.Address.String(), err))
+		return InvalidData, nil
+	}
+	return Progress, nil
+}
+
 func checkContractReceiveBlock(lv *LedgerVerifier, block *types.StateBlock) (ProcessResult, error) {
 	result, err := checkStateBlock(lv, block)
 	if err != nil || result != Progress {
@@ -307,6 +379,18 @@ func checkContractReceiveBlock(lv *LedgerVerifier, block *types.StateBlock) (Pro
 	}
 	address := types.Address(send.GetLink())
 
+	//check refund and reward
+	if _, err := lv.l.GetRefund(block.GetLink()); err == nil {
+		return UnReceivable, nil
+	} else if err != ledger.ErrRefundNotFound {
+		return Other, err
+	}
+	if _, err := lv.l.GetReward(block.GetLink()); err == nil {
+		return UnReceivable, nil
+	} else if err != ledger.ErrRewardNotFound {
+		return Other, err
+	}
+
 	//verify data
 	input, err := lv.l.GetStateBlock(block.Link)
 	if err != nil {
@@ -314,22 +398,16 @@ func checkContractReceiveBlock(lv *LedgerVerifier, block *types.StateBlock) (Pro
 	}
 	if c, ok, err := contract.GetChainContract(address, input.Data); ok && err == nil {
 		clone := block.Clone()
-		//TODO:verify extra hash and commit to db
 		vmCtx := vmstore.NewVMContext(lv.l)
 		if g, e := c.DoReceive(vmCtx, clone, input); e == nil {
 			if len(g) > 0 {
 				amount, _ := lv.l.CalculateAmount(block)
 				if bytes.EqualFold(g[0].Block.Data, block.Data) && g[0].Token == block.Token &&
 					g[0].Amount.Compare(amount) == types.BalanceCompEqual && g[0].ToAddress == block.Address {
-					//save contract data
-					ctx := g[0].VMContext
-					if ctx != nil {
-						err := ctx.SaveStorage()
-						if err != nil {
-							return InvalidData, nil
-						}
-						err = ctx.SaveTrie()
-						if err != nil {
+					//verify the contract root committed in extra, the contract data is saved when the block is processed
+					if ctx := g[0].VMContext; ctx != nil {
+						if root := ctx.ContractRoot(address); root != block.Extra {
+							lv.logger.Infof("contract root mismatch, exp: %s, act: %s", root.String(), block.Extra.String())
 							return InvalidData, nil
 						}
 					}
@@ -349,6 +427,72 @@ func checkContractReceiveBlock(lv *LedgerVerifier, block *types.StateBlock) (Pro
 	}
 }
 
+// checkContractRefundBlock checks the ContractRefund block which returns the amount of a contract send to the
+// sender, the contract send must not be received yet and the receive of the chain contract must fail
+func checkContractRefundBlock(lv *LedgerVerifier, block *types.StateBlock) (ProcessResult, error) {
+	result, err := checkStateBlock(lv, block)
+	if err != nil || result != Progress {
+		return result, err
+	}
+
+	// check previous
+	if previous, err := lv.l.GetStateBlock(block.Previous); err != nil {
+		return GapPrevious, nil
+	} else {
+		//check fork
+		if tm, err := lv.l.GetTokenMeta(block.Address, block.Token); err == nil && previous.GetHash() != tm.Header {
+			return Fork, nil
+		}
+	}
+
+	// check link
+	input, err := lv.l.GetStateBlock(block.Link)
+	if err != nil {
+		return GapSource, nil
+	}
+	if input.GetType() != types.ContractSend || input.Address != block.Address {
+		return InvalidData, nil
+	}
+	if _, err := lv.l.GetRefund(block.Link); err == nil {
+		return UnReceivable, nil
+	} else if err != ledger.ErrRefundNotFound {
+		return Other, err
+	}
+	// a received contract send is never refunded, whatever its receive returns now
+	if _, err := lv.l.GetReward(block.Link); err == nil {
+		return UnReceivable, nil
+	} else if err != ledger.ErrRewardNotFound {
+		return Other, err
+	}
+
+	address := types.Address(input.GetLink())
+	c, ok, err := contract.GetChainContract(address, input.Data)
+	if !ok || err != nil {
+		return Other, fmt.Errorf("can not find chain contract %s", address.String())
+	}
+	// a contract send which can be received by the contract is not refunded, nor one whose nep5 tx is not verified yet
+	if _, err := c.DoReceive(vmstore.NewVMContext(lv.l), block.Clone(), input); err == nil {
+		return UnReceivable, nil
+	} else if contract.IsNEP5TxUnverified(err) {
+		return Other, err
+	}
+
+	refund, err := contract.Refund(vmstore.NewVMContext(lv.l), c, block.Clone(), input)
+	if err != nil {
+		return Other, err
+	}
+	r := refund.Block
+	if r.Token != block.Token || r.Previous != block.Previous || !bytes.Equal(r.Data, block.Data) ||
+		r.Representative != block.Representative {
+		return InvalidData, nil
+	}
+	if !r.Balance.Equal(block.Balance) || !r.Vote.Equal(block.Vote) || !r.Network.Equal(block.Network) ||
+		!r.Oracle.Equal(block.Oracle) || !r.Storage.Equal(block.Storage) {
+		return BalanceMismatch, nil
+	}
+	return Progress, nil
+}
+
 func (lv *LedgerVerifier) BlockProcess(block types.Block) error {
 	return lv.l.BatchUpdate(func(txn db.StoreTxn) error {
 		if state, ok := block.(*types.StateBlock); ok {
@@ -358,8 +502,9 @@ func (lv *LedgerVerifier) BlockProcess(block types.Block) error {
 				return err
 			}
 			return nil
-		} else if _, ok := block.(*types.SmartContractBlock); ok {
-			return errors.New("smart contract block")
+		} else if sc, ok := block.(*types.SmartContractBlock); ok {
+			lv.logger.Debug("process smart contract block, ", sc.GetHash())
+			return lv.l.AddSmartContractBlock(sc, txn)
 		}
 		return errors.New("invalid block")
 	})
@@ -367,6 +512,10 @@ func (lv *LedgerVerifier) BlockProcess(block types.Block) error {
 
 func (lv *LedgerVerifier) processStateBlock(block *types.StateBlock, txn db.StoreTxn) error {
 	lv.logger.Debug("process block, ", block.GetHash())
+	// the logs of the contract are saved before the block which publishes them
+	if err := lv.updateContract(block, txn); err != nil {
+		return fmt.Errorf("update contract error: %s", err)
+	}
 	if err := lv.l.AddStateBlock(block, txn); err != nil {
 		return err
 	}
@@ -394,6 +543,44 @@ func (lv *LedgerVerifier) processStateBlock(block *types.StateBlock, txn db.Stor
 	return nil
 }
 
+// updateContract executes the receive of the contract send of the ContractReward block again and saves the contract
+// storage, logs and the root of the contract storage trie with the block
+func (lv *LedgerVerifier) updateContract(block *types.StateBlock, txn db.StoreTxn) error {
+	if block.GetType() != types.ContractReward || common.IsGenesisBlock(block) {
+		return nil
+	}
+	send, err := lv.l.GetStateBlock(block.GetLink(), txn)
+	if err != nil {
+		return err
+	}
+	address := types.Address(send.GetLink())
+	c, ok, err := contract.GetChainContract(address, send.Data)
+	if !ok || err != nil {
+		return fmt.Errorf("can not find chain contract %s", address.String())
+	}
+	g, err := c.DoReceive(vmstore.NewVMContext(lv.l), block.Clone(), send)
+	if err != nil {
+		return err
+	}
+	if len(g) == 0 || g[0].VMContext == nil {
+		return nil
+	}
+	// the storage is saved in the storage trie only, the flat store keeps the storage of a contract without a root
+	ctx := g[0].VMContext
+	if err := ctx.SaveTrie(txn); err != nil {
+		return err
+	}
+	root := ctx.ContractRoot(address)
+	lv.logger.Debugf("update contract %s root to %s", address.String(), root.String())
+	if err := lv.l.UpdateContractRoot(address, block.GetHash(), root, txn); err != nil {
+		return err
+	}
+	if logs := ctx.Logs(); logs != nil {
+		return lv.l.AddVmLogs(block.GetHash(), logs, txn)
+	}
+	return nil
+}
+
 func (lv *LedgerVerifier) updatePending(block *types.StateBlock, tm *types.TokenMeta, txn db.StoreTxn) error {
 	hash := block.GetHash()
 	switch block.Type {
@@ -420,6 +607,27 @@ func (lv *LedgerVerifier) updatePending(block *types.StateBlock, tm *types.Token
 		if err := lv.l.DeletePending(&pendingKey, txn); err != nil {
 			return err
 		}
+	case types.ContractReward:
+		// a contract send has no pending, the reward is recorded instead
+		if _, err := lv.l.GetRefund(block.GetLink(), txn); err == nil {
+			return fmt.Errorf("contract send %s is refunded", block.GetLink())
+		} else if err != ledger.ErrRefundNotFound {
+			return err
+		}
+		lv.logger.Debug("add reward, ", block.GetLink())
+		if err := lv.l.AddReward(block.GetLink(), hash, txn); err != nil {
+			return err
+		}
+	case types.ContractRefund:
+		if _, err := lv.l.GetReward(block.GetLink(), txn); err == nil {
+			return fmt.Errorf("contract send %s is received", block.GetLink())
+		} else if err != ledger.ErrRewardNotFound {
+			return err
+		}
+		lv.logger.Debug("add refund, ", block.GetLink())
+		if err := lv.l.AddRefund(block.GetLink(), hash, txn); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -9,6 +9,7 @@ package process
 
 import (
 	"fmt"
+	"math/big"
 	"os"
 	"path/filepath"
 	"testing"
@@ -111,3 +112,74 @@ func checkInfo(t *testing.T, l *ledger.Ledger) {
 		}
 	}
 }
+
+func TestLedger_Verify(t *testing.T) {
+	teardownTestCase, l, lv := setupTestCase(t)
+	defer teardownTestCase(t)
+	if err := lv.BlockProcess(bc[0]); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range bc[1:5] {
+		if p, err := lv.Process(b); err != nil || p != Progress {
+			t.Fatal(p, err)
+		}
+	}
+
+	report, err := l.Verify(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Blocks != 5 || report.Accounts != 2 || len(report.Inconsistencies) != 0 {
+		t.Fatal("invalid report", report.Blocks, report.Accounts, report.Inconsistencies)
+	}
+
+	// break the frontier, the pending of the unreceived send and the representation
+	tm, err := l.GetTokenMeta(bc[4].Address, bc[4].Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.DeleteFrontier(tm.Header); err != nil {
+		t.Fatal(err)
+	}
+	pendingKey := &types.PendingKey{Address: types.Address(bc[4].Link), Hash: bc[4].GetHash()}
+	if err := l.DeletePending(pendingKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AddRepresentation(mock.Address(), &types.Benefit{Balance: types.Balance{Int: big.NewInt(1)}, Vote: types.ZeroBalance,
+		Network: types.ZeroBalance, Storage: types.ZeroBalance, Oracle: types.ZeroBalance, Total: types.Balance{Int: big.NewInt(1)}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if report, err = l.Verify(false); err != nil {
+		t.Fatal(err)
+	}
+	kinds := make(map[string]int)
+	for _, i := range report.Inconsistencies {
+		if i.Repaired {
+			t.Fatal("inconsistency should not be repaired", i)
+		}
+		kinds[i.Kind]++
+	}
+	if len(report.Inconsistencies) != 3 || kinds[ledger.InconsistentFrontier] != 1 || kinds[ledger.InconsistentPending] != 1 ||
+		kinds[ledger.InconsistentRepresentation] != 1 {
+		t.Fatal("invalid inconsistencies", report.Inconsistencies)
+	}
+
+	if report, err = l.Verify(true); err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range report.Inconsistencies {
+		if !i.Repaired {
+			t.Fatal("inconsistency should be repaired", i)
+		}
+	}
+	if _, err := l.GetFrontier(tm.Header); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := l.GetPending(*pendingKey); err != nil || info.Amount.Compare(types.Balance{Int: big.NewInt(30000000000)}) != types.BalanceCompEqual {
+		t.Fatal("invalid pending", info, err)
+	}
+	if report, err = l.Verify(false); err != nil || len(report.Inconsistencies) != 0 {
+		t.Fatal("ledger should be repaired", report.Inconsistencies, err)
+	}
+}
@@ -7,13 +7,19 @@ type TableName string
 const (
 	TableBlockHash    TableName = "blockhash"
 	TableBlockMessage TableName = "blockmessage"
+	TableContractLog  TableName = "contractlog"
+	TableLogTopic     TableName = "logtopic"
 )
 
 const LikeSign = "_like_"
 
+// LessSign prefixes a numeric string value to select the rows whose column is less than the value
+const LessSign = "_less_"
+
 type Column string
 
 const (
+	ColumnId        Column = "id"
 	ColumnHash      Column = "hash"
 	ColumnType      Column = "type"
 	ColumnAddress   Column = "address"
@@ -21,6 +27,9 @@ const (
 	ColumnReceiver  Column = "receiver"
 	ColumnMessage   Column = "message"
 	ColumnTimestamp Column = "timestamp"
+	ColumnLogIndex  Column = "logindex"
+	ColumnPosition  Column = "position"
+	ColumnTopic     Column = "topic"
 	ColumnNoNeed    Column = ""
 )
 
@@ -32,4 +41,14 @@ type DbStore interface {
 	Delete(table TableName, condition map[Column]interface{}) error
 	Count(table TableName, dest interface{}) error
 	Group(table TableName, column Column, dest interface{}) error
+	ReadLogs(condition *LogCondition, offset int, limit int, dest interface{}) error
+}
+
+// LogCondition selects the rows of TableContractLog, Topics are matched by position and an empty topic matches any
+// topic, an empty Address or a zero FromTime or ToTime is not a condition
+type LogCondition struct {
+	Address  string
+	Topics   []string
+	FromTime int64
+	ToTime   int64
 }
@@ -90,6 +90,17 @@ func (s *DBSQL) Group(table TableName, column Column, dest interface{}) error {
 	return nil
 }
 
+func (s *DBSQL) ReadLogs(condition *LogCondition, offset int, limit int, dest interface{}) error {
+	sql := readLogSql(condition, offset, limit)
+	s.logger.Debug(sql)
+	err := s.db.Select(dest, sql)
+	if err != nil {
+		s.logger.Errorf("read logs error, sql: %s, err: %s", sql, err.Error())
+		return err
+	}
+	return nil
+}
+
 func (s *DBSQL) Close() error {
 	return s.db.Close()
 }
@@ -120,6 +131,9 @@ func readSql(table TableName, condition map[Column]interface{}, offset int, limi
 				s := v.(string)
 				if strings.HasPrefix(s, LikeSign) {
 					para = append(para, string(k)+" like '"+strings.TrimLeft(s, LikeSign)+"' ")
+				} else if strings.HasPrefix(s, LessSign) {
+					n, _ := strconv.ParseInt(strings.TrimPrefix(s, LessSign), 10, 64)
+					para = append(para, string(k)+" < "+strconv.FormatInt(n, 10))
 				} else {
 					para = append(para, string(k)+" = '"+s+"' ")
 				}
@@ -165,3 +179,36 @@ func deleteSql(table TableName, condition map[Column]interface{}) string {
 	}
 	return sql
 }
+
+// readLogSql selects the logs in the order they are added, every topic of the condition is an exists subquery on
+// TableLogTopic
+func readLogSql(condition *LogCondition, offset int, limit int) string {
+	var para []string
+	if condition.Address != "" {
+		para = append(para, "l."+string(ColumnAddress)+" = '"+condition.Address+"' ")
+	}
+	if condition.FromTime > 0 {
+		para = append(para, "l."+string(ColumnTimestamp)+" >= "+strconv.FormatInt(condition.FromTime, 10))
+	}
+	if condition.ToTime > 0 {
+		para = append(para, "l."+string(ColumnTimestamp)+" <= "+strconv.FormatInt(condition.ToTime, 10))
+	}
+	for i, topic := range condition.Topics {
+		if topic == "" {
+			continue
+		}
+		para = append(para, fmt.Sprintf("exists (select 1 from %s t where t.%s = l.%s and t.%s = l.%s and t.%s = %d and t.%s = '%s')",
+			string(TableLogTopic), string(ColumnHash), string(ColumnHash), string(ColumnLogIndex), string(ColumnLogIndex),
+			string(ColumnPosition), i, string(ColumnTopic), topic))
+	}
+	sql := fmt.Sprintf("select l.* from %s l ", string(TableContractLog))
+	if len(para) != 0 {
+		sql = sql + " where " + strings.Join(para, " and ")
+	}
+	// a negative limit of sqlite is no limit, so the offset works without a limit
+	sql = sql + " order by l." + string(ColumnId) + " limit " + strconv.Itoa(limit)
+	if offset > 0 {
+		sql = sql + " offset " + strconv.Itoa(offset)
+	}
+	return sql
+}
@@ -43,6 +43,24 @@ func openSqlite(cfg *config.Config) (*sqlx.DB, error) {
 		`CREATE INDEX IF NOT EXISTS index_sender   ON BLOCKMESSAGE (sender);  `,
 		`CREATE INDEX IF NOT EXISTS index_receiver ON BLOCKMESSAGE (receiver);`,
 		`CREATE INDEX IF NOT EXISTS index_message  ON BLOCKMESSAGE (message); `,
+		`CREATE TABLE IF NOT EXISTS CONTRACTLOG
+		(	id integer PRIMARY KEY AUTOINCREMENT,
+			hash char(32),
+			logindex integer,
+			address char(32),
+			timestamp integer
+		)`,
+		`CREATE TABLE IF NOT EXISTS LOGTOPIC
+		(	id integer PRIMARY KEY AUTOINCREMENT,
+			hash char(32),
+			logindex integer,
+			position integer,
+			topic char(32)
+		)`,
+		`CREATE INDEX IF NOT EXISTS index_log_hash    ON CONTRACTLOG (hash);`,
+		`CREATE INDEX IF NOT EXISTS index_log_address ON CONTRACTLOG (address, timestamp);`,
+		`CREATE INDEX IF NOT EXISTS index_topic_hash  ON LOGTOPIC (hash);`,
+		`CREATE INDEX IF NOT EXISTS index_topic       ON LOGTOPIC (topic);`,
 	}
 
 	for _, sql := range sqls {
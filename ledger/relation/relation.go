@@ -6,6 +6,7 @@ import (
 
 type Store interface {
 	AccountBlocks(address types.Address, limit int, offset int) ([]types.Hash, error)
+	AccountBlocksBefore(address types.Address, cursor int64, limit int) ([]types.Hash, int64, error)
 	BlocksCount() (uint64, error)
 	BlocksCountByType() (map[string]uint64, error)
 	Blocks(limit int, offset int) ([]types.Hash, error)
@@ -13,5 +14,7 @@ type Store interface {
 	MessageBlocks(hash types.Hash, limit int, offset int) ([]types.Hash, error)
 	AddBlock(block *types.StateBlock) error
 	DeleteBlock(hash types.Hash) error
+	ContractLogs(address types.Address, topics []types.Hash, fromTime, toTime int64, limit int, offset int) ([]*LogIndex, error)
+	AddLogs(address types.Address, block *types.StateBlock, logs *types.VmLogs) error
 	Close() error
 }
@@ -2,6 +2,7 @@ package relation
 
 import (
 	"encoding/base64"
+	"strconv"
 	"sync"
 
 	"github.com/qlcchain/go-qlc/common"
@@ -36,6 +37,23 @@ type blocksMessage struct {
 	Timestamp int64
 }
 
+type contractLog struct {
+	Id        int64
+	Hash      string
+	LogIndex  int64
+	Address   string
+	Timestamp int64
+}
+
+// LogIndex locates a contract log, it is the Index-th log emitted by the contract of Address when the block of
+// Hash is generated
+type LogIndex struct {
+	Hash      types.Hash
+	Index     int
+	Address   types.Address
+	Timestamp int64
+}
+
 var (
 	once     sync.Once
 	relation *Relation
@@ -70,6 +88,30 @@ func (r *Relation) AccountBlocks(address types.Address, limit int, offset int) (
 	return blockHash(h)
 }
 
+// AccountBlocksBefore returns the blocks of the account whose row id is less than cursor, the newest first, a zero
+// cursor starts from the newest block. It also returns the cursor of the next page, which is zero at the last page.
+func (r *Relation) AccountBlocksBefore(address types.Address, cursor int64, limit int) ([]types.Hash, int64, error) {
+	condition := make(map[db.Column]interface{})
+	condition[db.ColumnAddress] = address.String()
+	if cursor > 0 {
+		condition[db.ColumnId] = db.LessSign + strconv.FormatInt(cursor, 10)
+	}
+	var h []blocksHash
+	err := r.store.Read(db.TableBlockHash, condition, 0, limit, db.ColumnId, &h)
+	if err != nil {
+		return nil, 0, err
+	}
+	hashes, err := blockHash(h)
+	if err != nil {
+		return nil, 0, err
+	}
+	var next int64
+	if len(h) == limit && limit > 0 {
+		next = h[len(h)-1].Id
+	}
+	return hashes, next, nil
+}
+
 func (r *Relation) BlocksCount() (uint64, error) {
 	var count uint64
 	err := r.store.Count(db.TableBlockHash, &count)
@@ -161,7 +203,72 @@ func (r *Relation) DeleteBlock(hash types.Hash) error {
 	if err != nil {
 		return err
 	}
-	return r.store.Delete(db.TableBlockMessage, condition)
+	if err := r.store.Delete(db.TableBlockMessage, condition); err != nil {
+		return err
+	}
+	return r.deleteLogs(hash)
+}
+
+// ContractLogs returns the logs of the contract whose topics match topics by position in the order they are added,
+// a zero topic matches any topic, a zero address selects the logs of all contracts and a zero toTime is no upper
+// bound of the log timestamp
+func (r *Relation) ContractLogs(address types.Address, topics []types.Hash, fromTime, toTime int64, limit int, offset int) ([]*LogIndex, error) {
+	condition := &db.LogCondition{FromTime: fromTime, ToTime: toTime}
+	if !address.IsZero() {
+		condition.Address = address.String()
+	}
+	for _, topic := range topics {
+		if topic.IsZero() {
+			condition.Topics = append(condition.Topics, "")
+		} else {
+			condition.Topics = append(condition.Topics, topic.String())
+		}
+	}
+	var l []contractLog
+	if err := r.store.ReadLogs(condition, offset, limit, &l); err != nil {
+		return nil, err
+	}
+	return logIndex(l)
+}
+
+// AddLogs indexes the logs emitted by the contract of address when block is generated, the logs indexed before for
+// the block are replaced
+func (r *Relation) AddLogs(address types.Address, block *types.StateBlock, logs *types.VmLogs) error {
+	hash := block.GetHash()
+	r.logger.Info("add logs, ", hash)
+	if err := r.deleteLogs(hash); err != nil {
+		return err
+	}
+	for i, log := range logs.Logs {
+		conLog := make(map[db.Column]interface{})
+		conLog[db.ColumnHash] = hash.String()
+		conLog[db.ColumnLogIndex] = int64(i)
+		conLog[db.ColumnAddress] = address.String()
+		conLog[db.ColumnTimestamp] = block.GetTimestamp()
+		if err := r.store.Create(db.TableContractLog, conLog); err != nil {
+			return err
+		}
+		for j, topic := range log.Topics {
+			conTopic := make(map[db.Column]interface{})
+			conTopic[db.ColumnHash] = hash.String()
+			conTopic[db.ColumnLogIndex] = int64(i)
+			conTopic[db.ColumnPosition] = int64(j)
+			conTopic[db.ColumnTopic] = topic.String()
+			if err := r.store.Create(db.TableLogTopic, conTopic); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Relation) deleteLogs(hash types.Hash) error {
+	condition := make(map[db.Column]interface{})
+	condition[db.ColumnHash] = hash.String()
+	if err := r.store.Delete(db.TableContractLog, condition); err != nil {
+		return err
+	}
+	return r.store.Delete(db.TableLogTopic, condition)
 }
 
 func phoneToString(b []byte) string {
@@ -192,6 +299,23 @@ func blockMessage(bs []blocksMessage) ([]types.Hash, error) {
 	return hs, nil
 }
 
+func logIndex(ls []contractLog) ([]*LogIndex, error) {
+	is := make([]*LogIndex, 0)
+	for _, l := range ls {
+		i := &LogIndex{Index: int(l.LogIndex), Timestamp: l.Timestamp}
+		if err := i.Hash.Of(l.Hash); err != nil {
+			return nil, err
+		}
+		address, err := types.HexToAddress(l.Address)
+		if err != nil {
+			return nil, err
+		}
+		i.Address = address
+		is = append(is, i)
+	}
+	return is, nil
+}
+
 func blockType(bs []blocksType) map[string]uint64 {
 	t := make(map[string]uint64)
 	for _, b := range bs {
@@ -211,6 +335,11 @@ func (r *Relation) SetEvent() error {
 		r.logger.Error(err)
 		return err
 	}
+	err = r.eb.Subscribe(string(common.EventAddVmLogs), r.AddLogs)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
 	return nil
 }
 
@@ -225,5 +354,10 @@ func (r *Relation) UnsubscribeEvent() error {
 		r.logger.Error(err)
 		return err
 	}
+	err = r.eb.Unsubscribe(string(common.EventAddVmLogs), r.AddLogs)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
 	return nil
 }
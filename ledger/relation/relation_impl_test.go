@@ -43,6 +43,37 @@ func TestRelation_CreateData(t *testing.T) {
 		t.Fatal(err)
 	}
 	t.Log(b)
+
+	// page the blocks of the account by the cursor
+	var blocks []*types.StateBlock
+	for i := 0; i < 2; i++ {
+		b := mock.StateBlockWithoutWork()
+		b.Address = blk.Address
+		if err := r.AddBlock(b); err != nil {
+			t.Fatal(err)
+		}
+		blocks = append(blocks, b)
+	}
+	page, cursor, err := r.AccountBlocksBefore(blk.Address, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0] != blocks[1].GetHash() || page[1] != blocks[0].GetHash() || cursor == 0 {
+		t.Fatal("invalid first page", page, cursor)
+	}
+	page, cursor, err = r.AccountBlocksBefore(blk.Address, cursor, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0] != blk.GetHash() || cursor != 0 {
+		t.Fatal("invalid last page", page, cursor)
+	}
+	for _, b := range blocks {
+		if err := r.DeleteBlock(b.GetHash()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
 	b, err = r.Blocks(10, 0)
 	if err != nil {
 		t.Fatal(err)
@@ -58,6 +89,53 @@ func TestRelation_CreateData(t *testing.T) {
 		t.Fatal(err)
 	}
 	t.Log(g)
+
+	// index the contract logs and select them by topics and time
+	address := mock.Address()
+	topics := []types.Hash{mock.Hash(), mock.Hash(), mock.Hash()}
+	logs := &types.VmLogs{Logs: []*types.VmLog{
+		{Topics: []types.Hash{topics[0], topics[1]}},
+		{Topics: []types.Hash{topics[0], topics[2]}},
+	}}
+	reward := mock.StateBlockWithoutWork()
+	reward.Timestamp = 100
+	if err := r.AddLogs(address, reward, logs); err != nil {
+		t.Fatal(err)
+	}
+	// indexing the logs of the block again replaces them
+	if err := r.AddLogs(address, reward, logs); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddLogs(mock.Address(), blk, &types.VmLogs{Logs: []*types.VmLog{{Topics: topics[:1]}}}); err != nil {
+		t.Fatal(err)
+	}
+	if l, err := r.ContractLogs(address, nil, 0, 0, -1, 0); err != nil || len(l) != 2 || l[0].Index != 0 ||
+		l[1].Index != 1 || l[0].Hash != reward.GetHash() || l[0].Address != address || l[0].Timestamp != 100 {
+		t.Fatal("invalid contract logs", l, err)
+	}
+	if l, err := r.ContractLogs(address, []types.Hash{types.ZeroHash, topics[2]}, 0, 0, -1, 0); err != nil ||
+		len(l) != 1 || l[0].Index != 1 {
+		t.Fatal("invalid logs of topic", l, err)
+	}
+	if l, err := r.ContractLogs(address, []types.Hash{topics[1]}, 0, 0, -1, 0); err != nil || len(l) != 0 {
+		t.Fatal("topic should be matched by position", l, err)
+	}
+	if l, err := r.ContractLogs(types.ZeroAddress, topics[:1], 0, 0, -1, 0); err != nil || len(l) != 3 {
+		t.Fatal("invalid logs of all contracts", l, err)
+	}
+	if l, err := r.ContractLogs(address, nil, 101, 0, -1, 0); err != nil || len(l) != 0 {
+		t.Fatal("invalid logs from time", l, err)
+	}
+	if l, err := r.ContractLogs(address, nil, 50, 100, 1, 1); err != nil || len(l) != 1 || l[0].Index != 1 {
+		t.Fatal("invalid page of logs", l, err)
+	}
+	if err := r.DeleteBlock(reward.GetHash()); err != nil {
+		t.Fatal(err)
+	}
+	if l, err := r.ContractLogs(address, nil, 0, 0, -1, 0); err != nil || len(l) != 0 {
+		t.Fatal("logs should be deleted with the block", l, err)
+	}
+
 	err = r.DeleteBlock(blk.GetHash())
 	if err != nil {
 		t.Fatal(err)
@@ -20,6 +20,7 @@ const (
 	QlcMessageReservedEndIdx       = 14
 	QlcMessageHeaderCheckSumEndIdx = 18
 	QlcMessageDataCheckSumEndIdx   = 22
+	QlcMessageMaxDataLength        = 4 * 1024 * 1024
 )
 
 // Error types
@@ -5,18 +5,19 @@ import (
 	"time"
 
 	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/metrics"
 
-	"github.com/bluele/gcache"
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/ledger"
 	"github.com/qlcchain/go-qlc/p2p/protos"
 )
 
 const (
-	msgCacheSize           = 65535
-	checkCacheTimeInterval = 30 * time.Second
-	msgResendMaxTimes      = 10
-	msgNeedResendInterval  = 10 * time.Second
+	checkOutboxTimeInterval = time.Second
+	msgResendMaxTimes       = 10
+	msgNeedResendInterval   = 10 * time.Second
+	peersMetric             = "qlc_p2p_peers"
+	queueDepthMetric        = "qlc_p2p_message_queue_depth"
 )
 
 //  Message Type
@@ -30,16 +31,9 @@ const (
 	BulkPullRsp     = "6" //BulkPullRsp
 	BulkPushBlock   = "7" //BulkPushBlock
 	MessageResponse = "8" //MessageResponse
+	Handshake       = "9" //Handshake
 )
 
-type cacheValue struct {
-	peerID      string
-	resendTimes uint32
-	startTime   time.Time
-	data        []byte
-	t           string
-}
-
 type MessageService struct {
 	netService          *QlcService
 	quitCh              chan bool
@@ -50,7 +44,7 @@ type MessageService struct {
 	rspMessageCh        chan *Message
 	ledger              *ledger.Ledger
 	syncService         *ServiceSync
-	cache               gcache.Cache
+	outbox              *Outbox
 }
 
 // NewService return new Service.
@@ -64,7 +58,7 @@ func NewMessageService(netService *QlcService, ledger *ledger.Ledger) *MessageSe
 		rspMessageCh:        make(chan *Message, 65535),
 		ledger:              ledger,
 		netService:          netService,
-		cache:               gcache.New(msgCacheSize).LRU().Build(),
+		outbox:              NewOutbox(),
 	}
 	ms.syncService = NewSyncService(netService, ledger)
 	return ms
@@ -86,11 +80,34 @@ func (ms *MessageService) Start() {
 	// start loop().
 	go ms.startLoop()
 	go ms.syncService.Start()
-	go ms.checkMessageCacheLoop()
+	go ms.checkOutboxLoop()
 	go ms.messageResponseLoop()
 	go ms.publishReqLoop()
 	go ms.confirmReqLoop()
 	go ms.confirmAckLoop()
+	metrics.NewGaugeFunc(peersMetric, "count of the connected peers", func() float64 {
+		return float64(ms.netService.node.streamManager.PeerCounts())
+	})
+	metrics.NewGaugeVecFunc(queueDepthMetric, "count of the messages waiting in the queues of the message service",
+		"queue", ms.queueDepths)
+}
+
+// queueDepths returns the count of the received messages waiting to be handled by the queue, the outbox is the
+// count of the sent messages waiting for the response of the peers
+func (ms *MessageService) queueDepths() map[string]float64 {
+	depths := map[string]float64{
+		"publish":    float64(len(ms.publishMessageCh)),
+		"confirmReq": float64(len(ms.confirmReqMessageCh)),
+		"confirmAck": float64(len(ms.confirmAckMessageCh)),
+		"sync":       float64(len(ms.messageCh)),
+		"response":   float64(len(ms.rspMessageCh)),
+	}
+	var outbox int
+	for _, s := range ms.outbox.Stats() {
+		outbox += s.Pending
+	}
+	depths["outbox"] = float64(outbox)
+	return depths
 }
 
 func (ms *MessageService) startLoop() {
@@ -195,104 +212,54 @@ func (ms *MessageService) confirmAckLoop() {
 	}
 }
 
-func (ms *MessageService) checkMessageCacheLoop() {
-	ticker := time.NewTicker(checkCacheTimeInterval)
+func (ms *MessageService) checkOutboxLoop() {
+	ticker := time.NewTicker(checkOutboxTimeInterval)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-ms.quitCh:
 			return
 		case <-ticker.C:
-			ms.checkMessageCache()
-		default:
-			time.Sleep(5 * time.Millisecond)
+			ms.resendMessages(time.Now())
 		}
 	}
 }
 
-func (ms *MessageService) checkMessageCache() {
-	var cs []*cacheValue
-	var csTemp []*cacheValue
-	var hash types.Hash
-	m := ms.cache.GetALL()
-	for k, v := range m {
-		hash = k.(types.Hash)
-		cs = v.([]*cacheValue)
-		for i, value := range cs {
-			if value.resendTimes > msgResendMaxTimes {
-				csTemp = append(csTemp, cs[i])
-				continue
-			}
-			if time.Now().Sub(value.startTime) < msgNeedResendInterval {
-				continue
-			}
-			stream := ms.netService.node.streamManager.FindByPeerID(value.peerID)
-			if stream == nil {
-				ms.netService.node.logger.Debug("Failed to locate peer's stream,maybe lost connect")
-				//csTemp = append(csTemp, cs[i])
-				value.resendTimes++
-				continue
-			}
-			stream.messageChan <- value.data
-			value.resendTimes++
-			if value.resendTimes > msgResendMaxTimes {
-				csTemp = append(csTemp, cs[i])
-				continue
-			}
+// resendMessages resends the messages of the outbox which are not answered in time, a message is not resent if
+// the stream of the peer is gone or its write queue is full, but the resend is still counted
+func (ms *MessageService) resendMessages(now time.Time) {
+	sm := ms.netService.node.streamManager
+	ms.outbox.Resend(now, func(peerID string, data []byte) {
+		stream := sm.FindByPeerID(peerID)
+		if stream == nil {
+			ms.netService.node.logger.Debugf("failed to locate stream of peer [%s], maybe lost connect", peerID)
+			return
 		}
-
-		if len(csTemp) == len(cs) {
-			t := ms.cache.Remove(hash)
-			if t {
-				ms.netService.node.logger.Debugf("remove message:[%s] success", hash.String())
-			}
-		} else {
-			csDiff := sliceDiff(cs, csTemp)
-			err := ms.cache.Set(hash, csDiff)
-			if err != nil {
-				ms.netService.node.logger.Error(err)
-			}
+		select {
+		case stream.messageChan <- data:
+		default:
+			ms.netService.node.logger.Debugf("write queue of peer [%s] is full", peerID)
 		}
-	}
+	}, func(peerID string) bool {
+		return sm.FindByPeerID(peerID) != nil
+	})
 }
 
 func (ms *MessageService) onMessageResponse(message *Message) {
-	//ms.netService.node.logger.Info("receive MessageResponse")
 	var hash types.Hash
-	var cs []*cacheValue
-	var csTemp []*cacheValue
 	err := hash.UnmarshalText(message.Data())
 	if err != nil {
 		ms.netService.node.logger.Errorf("onMessageResponse err:[%s]", err)
 		return
 	}
-	v, err := ms.cache.Get(hash)
-	if err != nil {
-		if err == gcache.KeyNotFoundError {
-			ms.netService.node.logger.Debugf("this hash:[%s] is not in cache", hash)
-		} else {
-			ms.netService.node.logger.Errorf("Get cache err:[%s] for hash:[%s]", err, hash)
-		}
-		return
-	}
-	cs = v.([]*cacheValue)
-	for k, v := range cs {
-		if v.peerID == message.MessageFrom() {
-			csTemp = append(csTemp, cs[k])
-		}
+	if !ms.outbox.Ack(message.MessageFrom(), hash) {
+		ms.netService.node.logger.Debugf("this hash:[%s] is not in outbox", hash)
 	}
+}
 
-	if len(csTemp) == len(cs) {
-		t := ms.cache.Remove(hash)
-		if t {
-			ms.netService.node.logger.Debugf("remove message cache for hash:[%s] success", hash)
-		}
-	} else {
-		csDiff := sliceDiff(cs, csTemp)
-		err := ms.cache.Set(hash, csDiff)
-		if err != nil {
-			ms.netService.node.logger.Error(err)
-		}
-	}
+// DeliveryStats returns the delivery stats of the peers, it is the callback of EventDeliveryStats
+func (ms *MessageService) DeliveryStats(stats *[]*DeliveryStats) {
+	*stats = append(*stats, ms.outbox.Stats()...)
 }
 
 func (ms *MessageService) onPublishReq(message *Message) {
@@ -314,6 +281,15 @@ func (ms *MessageService) onPublishReq(message *Message) {
 		ms.netService.node.logger.Error(err)
 		return
 	}
+	if t, err := protos.PublishBlockType(message.Data()); err == nil && t == types.SmartContract {
+		p, err := protos.PublishContractFromProto(message.Data())
+		if err != nil {
+			ms.netService.node.logger.Info(err)
+			return
+		}
+		ms.netService.msgEvent.Publish(string(common.EventPublishContract), p.Blk, hash, message.MessageFrom())
+		return
+	}
 	p, err := protos.PublishBlockFromProto(message.Data())
 	if err != nil {
 		ms.netService.node.logger.Info(err)
@@ -378,6 +354,8 @@ func (ms *MessageService) onConfirmAck(message *Message) {
 
 func (ms *MessageService) Stop() {
 	//ms.netService.node.logger.Info("stopped message monitor")
+	metrics.Unregister(peersMetric)
+	metrics.Unregister(queueDepthMetric)
 	// quit.
 	for i := 0; i < 6; i++ {
 		ms.quitCh <- true
@@ -397,6 +375,9 @@ func (ms *MessageService) Stop() {
 func marshalMessage(messageName string, value interface{}) ([]byte, error) {
 	switch messageName {
 	case PublishReq:
+		if blk, ok := value.(*types.SmartContractBlock); ok {
+			return protos.PublishContractToProto(&protos.PublishContract{Blk: blk})
+		}
 		packet := protos.PublishBlock{
 			Blk: value.(*types.StateBlock),
 		}
@@ -483,23 +464,3 @@ func (ms *MessageService) addPerformanceTime(hash types.Hash) {
 		}
 	}
 }
-
-// InSliceIface checks given interface in interface slice.
-func inSliceIface(v interface{}, sl []*cacheValue) bool {
-	for _, vv := range sl {
-		if vv == v {
-			return true
-		}
-	}
-	return false
-}
-
-// SliceDiff returns diff slice of slice1 - slice2.
-func sliceDiff(slice1, slice2 []*cacheValue) (diffslice []*cacheValue) {
-	for _, v := range slice1 {
-		if !inSliceIface(v, slice2) {
-			diffslice = append(diffslice, v)
-		}
-	}
-	return
-}
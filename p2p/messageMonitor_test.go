@@ -515,39 +515,39 @@ func Test_MessageCache(t *testing.T) {
 
 	msg := <-node2.msgService.publishMessageCh
 
-	//test message cache
-	if node1.msgService.cache.Len() != 1 {
-		t.Fatal("message cache error")
+	//test message outbox
+	outbox := node1.msgService.outbox
+	peerID2 := node2.node.ID.Pretty()
+	if outbox.Pending(peerID2) != 1 {
+		t.Fatal("message outbox error")
 	}
-
-	if !node1.msgService.cache.Has(msg.Hash()) {
-		t.Fatal("message cache key error")
+	if !outbox.Ack(peerID2, msg.Hash()) || outbox.Pending(peerID2) != 0 {
+		t.Fatal("message outbox key error")
 	}
-	v, err := node1.msgService.cache.Get(msg.Hash())
-	if err != nil {
-		t.Fatal(err)
-	}
-	c := v.([]*cacheValue)
-
-	if len(c) != 2 {
-		t.Fatal("message cache value lens error:", len(c))
-	}
-
-	if c[0].peerID != node2.node.ID.Pretty() && c[1].peerID != node2.node.ID.Pretty() {
-		t.Fatal("message cache peer ID error")
+	node1.Broadcast(PublishReq, mock.StateBlock())
+	time.Sleep(1 * time.Second)
+	stats := func() *DeliveryStats {
+		for _, s := range outbox.Stats() {
+			if s.PeerID == peerID2 {
+				return s
+			}
+		}
+		t.Fatal("no delivery stats of", peerID2)
+		return nil
 	}
-	if c[0].resendTimes != 0 || c[1].resendTimes != 0 {
-		t.Fatal("message cache resendTimes error")
+	now := time.Now()
+	node1.msgService.resendMessages(now)
+	if s := stats(); s.Sent != 2 || s.Acked != 1 || s.Pending != 1 || s.Retried != 0 {
+		t.Fatal("message should not be resent before the interval", s)
 	}
-	time.Sleep(10 * time.Second)
-	node1.msgService.checkMessageCache()
-	if c[0].resendTimes != 1 || c[1].resendTimes != 1 {
-		t.Fatal("message cache resendTimes error")
+	node1.msgService.resendMessages(now.Add(msgNeedResendInterval))
+	if s := stats(); s.Retried != 1 {
+		t.Fatal("message outbox resendTimes error", s)
 	}
-	for i := 0; i < 20; i++ {
-		node1.msgService.checkMessageCache()
+	for i := 0; i < 2*msgResendMaxTimes; i++ {
+		node1.msgService.resendMessages(now.Add(time.Duration(i+2) * msgResendMaxInterval))
 	}
-	if node1.msgService.cache.Has(msg.Hash()) {
-		t.Fatal("resendTimes error")
+	if s := stats(); s.Pending != 0 || s.Failed != 1 || s.Retried != msgResendMaxTimes {
+		t.Fatal("resendTimes error", s)
 	}
 }
@@ -24,10 +24,11 @@ import (
 // Error types
 var (
 	ErrPeerIsNotConnected = errors.New("peer is not connected")
+	ErrPeerIsBanned       = errors.New("peer is banned")
 )
 
 // p2p protocol version
-var p2pVersion = 4
+var p2pVersion = 5
 
 //var logger = log.NewLogger("p2p")
 
@@ -267,3 +268,54 @@ func (node *QlcNode) SendMessageToPeer(messageName string, value interface{}, pe
 	}
 	return stream.SendMessageToPeer(messageName, data)
 }
+
+// ConnectPeer connects to the peer of the multiaddr addr and opens a stream with it
+func (node *QlcNode) ConnectPeer(addr string) error {
+	mAddr, err := ma.NewMultiaddr(addr)
+	if err != nil {
+		return err
+	}
+	pInfo, err := pstore.InfoFromP2pAddr(mAddr)
+	if err != nil {
+		return err
+	}
+	if node.isBanned(pInfo.ID) {
+		return ErrPeerIsBanned
+	}
+	ctx, cancel := context.WithTimeout(node.ctx, discoveryConnTimeout)
+	defer cancel()
+	if err := node.host.Connect(ctx, *pInfo); err != nil {
+		return err
+	}
+	node.streamManager.createStreamWithPeer(pInfo.ID)
+	return nil
+}
+
+// DisconnectPeer closes the stream and all connections with the peer
+func (node *QlcNode) DisconnectPeer(peerID string) error {
+	pid, err := peer.IDB58Decode(peerID)
+	if err != nil {
+		return err
+	}
+	stream := node.streamManager.FindByPeerID(peerID)
+	if stream == nil {
+		return ErrPeerIsNotConnected
+	}
+	stream.close()
+	return node.host.Network().ClosePeer(pid)
+}
+
+func (node *QlcNode) isBanned(pid peer.ID) bool {
+	if node.streamManager.isTemporarilyBanned(pid.Pretty()) {
+		return true
+	}
+	if node.netService == nil || node.netService.msgService == nil {
+		return false
+	}
+	banned, err := node.netService.msgService.ledger.IsBannedPeer(pid.Pretty())
+	if err != nil {
+		node.logger.Error(err)
+		return false
+	}
+	return banned
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	swarmt "github.com/libp2p/go-libp2p-swarm/testing"
@@ -37,3 +38,86 @@ func TestQlcNode(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func testPeerService(t *testing.T, listen string) *QlcService {
+	dir := filepath.Join(config.QlcTestDataDir(), "p2p", uuid.New().String())
+	cfg, err := config.DefaultConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.P2P.Listen = listen
+	cfg.P2P.BootNodes = []string{}
+	cfg.P2P.Discovery.MDNSEnabled = false
+	ns, err := NewQlcService(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ns.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return ns
+}
+
+func TestQlcNode_ConnectPeer(t *testing.T) {
+	node1 := testPeerService(t, "/ip4/127.0.0.1/tcp/19750")
+	node2 := testPeerService(t, "/ip4/127.0.0.1/tcp/19751")
+	defer func() {
+		for _, n := range []*QlcService{node1, node2} {
+			if err := n.Stop(); err != nil {
+				t.Fatal(err)
+			}
+			if err := n.msgService.ledger.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.RemoveAll(config.QlcTestDataDir()); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	addr2 := "/ip4/127.0.0.1/tcp/19751/ipfs/" + node2.node.ID.Pretty()
+	peerID2 := node2.node.ID.Pretty()
+
+	if err := node1.node.ConnectPeer("/ip4/127.0.0.1"); err == nil {
+		t.Fatal("invalid peer address should be rejected")
+	}
+	if err := node1.node.ConnectPeer(addr2); err != nil {
+		t.Fatal(err)
+	}
+	waitHandshake(t, node1.node.streamManager, peerID2)
+	peers := node1.node.streamManager.PeersInfo()
+	if len(peers) != 1 || peers[0].ID != peerID2 || peers[0].ConnectedSince == 0 || peers[0].BytesIn == 0 ||
+		peers[0].BytesOut == 0 {
+		t.Fatal("invalid peers", peers)
+	}
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for node1.node.streamManager.PeersInfo()[0].Latency == 0 {
+		select {
+		case <-ticker.C:
+			t.Fatal("ping peer error")
+		default:
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	if err := node1.node.DisconnectPeer(peerID2); err != nil {
+		t.Fatal(err)
+	}
+	if node1.node.streamManager.FindByPeerID(peerID2) != nil {
+		t.Fatal("peer should be disconnected")
+	}
+	if err := node1.node.DisconnectPeer(peerID2); err != ErrPeerIsNotConnected {
+		t.Fatal("disconnect a disconnected peer", err)
+	}
+
+	if err := node1.msgService.ledger.AddBannedPeer(peerID2); err != nil {
+		t.Fatal(err)
+	}
+	if err := node1.node.ConnectPeer(addr2); err != ErrPeerIsBanned {
+		t.Fatal("banned peer should be rejected", err)
+	}
+	node1.node.streamManager.createStreamWithPeer(node2.node.ID)
+	if node1.node.streamManager.FindByPeerID(peerID2) != nil {
+		t.Fatal("stream of banned peer should be refused")
+	}
+}
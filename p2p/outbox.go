@@ -0,0 +1,183 @@
+package p2p
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/qlcchain/go-qlc/common/types"
+)
+
+const (
+	// outboxSize is the max count of the messages waiting for the response of a peer, the oldest message is
+	// dropped when the outbox is full
+	outboxSize = 1024
+	// msgResendMaxInterval is the max interval between two resends of a message
+	msgResendMaxInterval = time.Minute
+)
+
+// DeliveryStats is the delivery of the messages which need the MessageResponse of a peer, Sent is the count of
+// the messages sent, Retried the count of the resends, Failed the count of the messages given up after
+// msgResendMaxTimes resends and Dropped the count of the messages dropped because the outbox was full
+type DeliveryStats struct {
+	PeerID  string `json:"peerId"`
+	Pending int    `json:"pending"`
+	Sent    uint64 `json:"sent"`
+	Acked   uint64 `json:"acked"`
+	Retried uint64 `json:"retried"`
+	Failed  uint64 `json:"failed"`
+	Dropped uint64 `json:"dropped"`
+}
+
+type outboxMessage struct {
+	data        []byte
+	messageType string
+	resendTimes uint32
+	startTime   time.Time
+	nextResend  time.Time
+}
+
+type peerOutbox struct {
+	messages map[types.Hash]*outboxMessage
+	stats    DeliveryStats
+}
+
+// Outbox keeps the PublishReq, ConfirmReq and ConfirmAck messages sent to every peer until the peer answers with
+// a MessageResponse, a message is resent with an exponential backoff and given up after msgResendMaxTimes resends
+type Outbox struct {
+	mu    sync.Mutex
+	peers map[string]*peerOutbox
+}
+
+func NewOutbox() *Outbox {
+	return &Outbox{peers: make(map[string]*peerOutbox)}
+}
+
+// needResponse checks whether the peer answers the message with a MessageResponse
+func needResponse(messageType string) bool {
+	return messageType == PublishReq || messageType == ConfirmReq || messageType == ConfirmAck
+}
+
+// resendInterval returns the interval before the resend after resendTimes resends
+func resendInterval(resendTimes uint32) time.Duration {
+	interval := msgNeedResendInterval
+	for i := uint32(0); i < resendTimes && interval < msgResendMaxInterval; i++ {
+		interval *= 2
+	}
+	if interval > msgResendMaxInterval {
+		interval = msgResendMaxInterval
+	}
+	return interval
+}
+
+// Add puts the message sent to the peer into the outbox, a message which is already waiting is kept as it is
+func (o *Outbox) Add(peerID string, hash types.Hash, data []byte, messageType string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	po, ok := o.peers[peerID]
+	if !ok {
+		po = &peerOutbox{messages: make(map[types.Hash]*outboxMessage), stats: DeliveryStats{PeerID: peerID}}
+		o.peers[peerID] = po
+	}
+	if _, ok := po.messages[hash]; ok {
+		return
+	}
+	if len(po.messages) >= outboxSize {
+		var oldest types.Hash
+		var oldestTime time.Time
+		for h, m := range po.messages {
+			if oldestTime.IsZero() || m.startTime.Before(oldestTime) {
+				oldest, oldestTime = h, m.startTime
+			}
+		}
+		delete(po.messages, oldest)
+		po.stats.Dropped++
+	}
+	now := time.Now()
+	po.messages[hash] = &outboxMessage{
+		data:        data,
+		messageType: messageType,
+		startTime:   now,
+		nextResend:  now.Add(resendInterval(0)),
+	}
+	po.stats.Sent++
+}
+
+// Ack removes the message answered by the peer, it returns false if the message is not in the outbox
+func (o *Outbox) Ack(peerID string, hash types.Hash) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	po, ok := o.peers[peerID]
+	if !ok {
+		return false
+	}
+	if _, ok := po.messages[hash]; !ok {
+		return false
+	}
+	delete(po.messages, hash)
+	po.stats.Acked++
+	return true
+}
+
+// Pending returns the count of the messages waiting for the response of the peer
+func (o *Outbox) Pending(peerID string) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if po, ok := o.peers[peerID]; ok {
+		return len(po.messages)
+	}
+	return 0
+}
+
+// Resend resends the messages whose response is overdue by send, the messages which have been resent
+// msgResendMaxTimes times are given up. The peers which are gone and have no message left are removed, connected
+// reports whether a peer is still connected.
+func (o *Outbox) Resend(now time.Time, send func(peerID string, data []byte), connected func(peerID string) bool) {
+	type resend struct {
+		peerID string
+		data   []byte
+	}
+	var resends []resend
+
+	o.mu.Lock()
+	for peerID, po := range o.peers {
+		for hash, m := range po.messages {
+			if now.Before(m.nextResend) {
+				continue
+			}
+			if m.resendTimes >= msgResendMaxTimes {
+				delete(po.messages, hash)
+				po.stats.Failed++
+				continue
+			}
+			m.resendTimes++
+			m.nextResend = now.Add(resendInterval(m.resendTimes))
+			po.stats.Retried++
+			resends = append(resends, resend{peerID: peerID, data: m.data})
+		}
+		if len(po.messages) == 0 && !connected(peerID) {
+			delete(o.peers, peerID)
+		}
+	}
+	o.mu.Unlock()
+
+	for _, r := range resends {
+		send(r.peerID, r.data)
+	}
+}
+
+// Stats returns the delivery stats of the peers sorted by the peer id
+func (o *Outbox) Stats() []*DeliveryStats {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	stats := make([]*DeliveryStats, 0, len(o.peers))
+	for _, po := range o.peers {
+		s := po.stats
+		s.Pending = len(po.messages)
+		stats = append(stats, &s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].PeerID < stats[j].PeerID
+	})
+	return stats
+}
@@ -0,0 +1,70 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qlcchain/go-qlc/common/types"
+)
+
+func TestResendInterval(t *testing.T) {
+	if resendInterval(0) != msgNeedResendInterval || resendInterval(1) != 2*msgNeedResendInterval {
+		t.Fatal("invalid backoff", resendInterval(0), resendInterval(1))
+	}
+	if resendInterval(msgResendMaxTimes) != msgResendMaxInterval {
+		t.Fatal("backoff should be capped", resendInterval(msgResendMaxTimes))
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	o := NewOutbox()
+	peerID := "QmdFSukPUMF3t1JxjvTo14SEEb5JV9JBT6PukGRo6A2g4f"
+	h1 := types.HashData([]byte("1"))
+	h2 := types.HashData([]byte("2"))
+	o.Add(peerID, h1, []byte("1"), PublishReq)
+	o.Add(peerID, h1, []byte("1"), PublishReq)
+	o.Add(peerID, h2, []byte("2"), ConfirmAck)
+	if o.Pending(peerID) != 2 {
+		t.Fatal("invalid pending", o.Pending(peerID))
+	}
+	if !o.Ack(peerID, h1) || o.Ack(peerID, h1) || o.Ack("QmOther", h2) {
+		t.Fatal("invalid ack")
+	}
+
+	var sent [][]byte
+	send := func(p string, data []byte) {
+		sent = append(sent, data)
+	}
+	connected := func(p string) bool {
+		return false
+	}
+	now := time.Now()
+	o.Resend(now, send, connected)
+	if len(sent) != 0 {
+		t.Fatal("message should not be resent before the interval")
+	}
+	at := now
+	for i := uint32(0); i < msgResendMaxTimes; i++ {
+		at = at.Add(resendInterval(i))
+		o.Resend(at, send, connected)
+	}
+	if len(sent) != msgResendMaxTimes || string(sent[0]) != "2" {
+		t.Fatal("invalid resends", len(sent))
+	}
+	stats := o.Stats()
+	if len(stats) != 1 || stats[0].Sent != 2 || stats[0].Acked != 1 || stats[0].Retried != msgResendMaxTimes ||
+		stats[0].Failed != 0 || stats[0].Pending != 1 {
+		t.Fatal("invalid stats", stats[0])
+	}
+	o.Resend(at.Add(msgResendMaxInterval), send, connected)
+	if o.Pending(peerID) != 0 || len(o.Stats()) != 0 {
+		t.Fatal("message should be given up and the gone peer removed")
+	}
+
+	for i := 0; i < outboxSize+1; i++ {
+		o.Add(peerID, types.HashData([]byte{byte(i), byte(i >> 8)}), nil, ConfirmReq)
+	}
+	if stats := o.Stats(); o.Pending(peerID) != outboxSize || stats[0].Dropped != 1 {
+		t.Fatal("outbox should be bounded", o.Pending(peerID))
+	}
+}
@@ -0,0 +1,133 @@
+package p2p
+
+import (
+	"time"
+)
+
+// peer score
+const (
+	initialPeerScore = 100
+	maxPeerScore     = 200
+	// peers with a score lower than goodPeerScore are only chosen by RandomPeer if there is no good peer
+	goodPeerScore = initialPeerScore
+	// peers with a score not higher than banPeerScore are banned for peerBanDuration
+	banPeerScore    = 0
+	peerBanDuration = 10 * time.Minute
+)
+
+// Misbehavior is a kind of misbehavior of a peer, each kind decrements the peer score by its penalty
+type Misbehavior byte
+
+const (
+	BadChecksum Misbehavior = iota
+	OversizedMessage
+	BadBlock
+	BadVote
+	NoResponse
+)
+
+var penalties = map[Misbehavior]int{
+	BadChecksum:      20,
+	OversizedMessage: 50,
+	BadBlock:         20,
+	BadVote:          20,
+	NoResponse:       10,
+}
+
+func (m Misbehavior) String() string {
+	switch m {
+	case BadChecksum:
+		return "bad checksum"
+	case OversizedMessage:
+		return "oversized message"
+	case BadBlock:
+		return "bad block"
+	case BadVote:
+		return "bad vote"
+	case NoResponse:
+		return "no response"
+	default:
+		return "unknown misbehavior"
+	}
+}
+
+type peerScore struct {
+	score       int
+	bannedUntil time.Time
+}
+
+// PeerScore return the score of the peer, peers never seen have the initial score
+func (sm *StreamManager) PeerScore(peerID string) int {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if ps, ok := sm.scores[peerID]; ok {
+		return ps.score
+	}
+	return initialPeerScore
+}
+
+// Penalize decrements the score of the peer for the misbehavior, the peer is disconnected and banned for
+// peerBanDuration if its score drops to banPeerScore
+func (sm *StreamManager) Penalize(peerID string, m Misbehavior) {
+	sm.mu.Lock()
+	ps := sm.getPeerScore(peerID)
+	ps.score -= penalties[m]
+	banned := ps.score <= banPeerScore
+	if banned {
+		ps.bannedUntil = time.Now().Add(peerBanDuration)
+		ps.score = initialPeerScore
+	}
+	score := ps.score
+	sm.mu.Unlock()
+
+	if banned {
+		sm.node.logger.Infof("ban peer [%s] for %s: %s", peerID, peerBanDuration, m)
+		sm.CloseStream(peerID)
+	} else {
+		sm.node.logger.Debugf("penalize peer [%s] for %s, score %d", peerID, m, score)
+	}
+}
+
+// Reward increments the score of the peer, up to maxPeerScore
+func (sm *StreamManager) Reward(peerID string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	ps := sm.getPeerScore(peerID)
+	if ps.score < maxPeerScore {
+		ps.score++
+	}
+}
+
+func (sm *StreamManager) isTemporarilyBanned(peerID string) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if ps, ok := sm.scores[peerID]; ok {
+		return time.Now().Before(ps.bannedUntil)
+	}
+	return false
+}
+
+// removePeerScores drops the scores of the disconnected peers which are not banned, a peer is scored again from the
+// initial score when it reconnects
+func (sm *StreamManager) removePeerScores() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	now := time.Now()
+	for peerID, ps := range sm.scores {
+		if now.Before(ps.bannedUntil) {
+			continue
+		}
+		if _, ok := sm.allStreams.Load(peerID); !ok {
+			delete(sm.scores, peerID)
+		}
+	}
+}
+
+func (sm *StreamManager) getPeerScore(peerID string) *peerScore {
+	ps, ok := sm.scores[peerID]
+	if !ok {
+		ps = &peerScore{score: initialPeerScore}
+		sm.scores[peerID] = ps
+	}
+	return ps
+}
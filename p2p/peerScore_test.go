@@ -0,0 +1,75 @@
+package p2p
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qlcchain/go-qlc/config"
+)
+
+func TestStreamManager_Penalize(t *testing.T) {
+	dir := filepath.Join(config.QlcTestDataDir(), "p2p", uuid.New().String())
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	cfg, err := config.DefaultConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := NewNode(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm := node.streamManager
+	peerID := node.ID.Pretty()
+
+	if sm.PeerScore(peerID) != initialPeerScore {
+		t.Fatal("invalid initial score", sm.PeerScore(peerID))
+	}
+	sm.Penalize(peerID, NoResponse)
+	if sm.PeerScore(peerID) != initialPeerScore-penalties[NoResponse] {
+		t.Fatal("invalid score after penalty", sm.PeerScore(peerID))
+	}
+	sm.Reward(peerID)
+	if sm.PeerScore(peerID) != initialPeerScore-penalties[NoResponse]+1 {
+		t.Fatal("invalid score after reward", sm.PeerScore(peerID))
+	}
+	if node.isBanned(node.ID) {
+		t.Fatal("peer should not be banned")
+	}
+	for sm.PeerScore(peerID) > penalties[OversizedMessage] {
+		sm.Penalize(peerID, BadBlock)
+	}
+	sm.Penalize(peerID, OversizedMessage)
+	if !node.isBanned(node.ID) {
+		t.Fatal("peer should be banned temporarily")
+	}
+	if sm.PeerScore(peerID) != initialPeerScore {
+		t.Fatal("score should be reset after ban", sm.PeerScore(peerID))
+	}
+	for i := 0; i < 2*maxPeerScore; i++ {
+		sm.Reward(peerID)
+	}
+	if sm.PeerScore(peerID) != maxPeerScore {
+		t.Fatal("score should not exceed the max score", sm.PeerScore(peerID))
+	}
+
+	// the score of a disconnected peer is only kept while it is banned
+	other := "QmPeerWithoutStream"
+	sm.Penalize(other, NoResponse)
+	sm.removePeerScores()
+	if _, ok := sm.scores[other]; ok {
+		t.Fatal("score of the disconnected peer should be removed")
+	}
+	if _, ok := sm.scores[peerID]; !ok {
+		t.Fatal("score of the banned peer should be kept")
+	}
+	sm.scores[peerID].bannedUntil = time.Now()
+	sm.removePeerScores()
+	if len(sm.scores) != 0 {
+		t.Fatal("score of the peer whose ban is over should be removed", len(sm.scores))
+	}
+}
@@ -1,15 +1,22 @@
 package protos
 
 import (
+	"bytes"
+	"math"
+
 	"github.com/gogo/protobuf/proto"
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/p2p/protos/pb"
 )
 
+// FrontierReq requests the frontiers of the remote peer, the frontiers whose open block is in [StartHash, EndHash)
+// are returned, a zero EndHash means there is no upper bound
 type FrontierReq struct {
 	StartAddress types.Address
 	Age          uint32
 	Count        uint32
+	StartHash    types.Hash
+	EndHash      types.Hash
 }
 
 func NewFrontierReq(addr types.Address, Age, Count uint32) (packet *FrontierReq) {
@@ -20,6 +27,24 @@ func NewFrontierReq(addr types.Address, Age, Count uint32) (packet *FrontierReq)
 	}
 }
 
+// NewFrontierRangeReq requests the frontiers whose open block is in [start, end)
+func NewFrontierRangeReq(start, end types.Hash) *FrontierReq {
+	return &FrontierReq{
+		Age:       math.MaxUint32,
+		Count:     math.MaxUint32,
+		StartHash: start,
+		EndHash:   end,
+	}
+}
+
+// InRange checks whether the open block hash is in the range of the request
+func (fr *FrontierReq) InRange(openBlock types.Hash) bool {
+	if bytes.Compare(openBlock[:], fr.StartHash[:]) < 0 {
+		return false
+	}
+	return fr.EndHash.IsZero() || bytes.Compare(openBlock[:], fr.EndHash[:]) < 0
+}
+
 // ToProto converts domain frontier into proto frontier
 func FrontierReqToProto(fr *FrontierReq) ([]byte, error) {
 	//pb := new(pb.Frontier)
@@ -29,6 +54,12 @@ func FrontierReqToProto(fr *FrontierReq) ([]byte, error) {
 		Age:     fr.Age,
 		Count:   fr.Count,
 	}
+	if !fr.StartHash.IsZero() {
+		frPb.StartHash = fr.StartHash[:]
+	}
+	if !fr.EndHash.IsZero() {
+		frPb.EndHash = fr.EndHash[:]
+	}
 	data, err := proto.Marshal(frPb)
 	if err != nil {
 		return nil, err
@@ -52,6 +83,17 @@ func FrontierReqFromProto(data []byte) (*FrontierReq, error) {
 		Age:          fr.Age,
 		Count:        fr.Count,
 	}
+	// the range is empty in the requests of old peers, which means all the frontiers
+	if len(fr.StartHash) > 0 {
+		if frq.StartHash, err = types.BytesToHash(fr.StartHash); err != nil {
+			return nil, err
+		}
+	}
+	if len(fr.EndHash) > 0 {
+		if frq.EndHash, err = types.BytesToHash(fr.EndHash); err != nil {
+			return nil, err
+		}
+	}
 	return frq, nil
 }
 
@@ -35,6 +35,36 @@ func TestFrontierReq(t *testing.T) {
 	}
 }
 
+func TestFrontierRangeReq(t *testing.T) {
+	var start, end, hash types.Hash
+	start[0], end[0] = 0x10, 0x20
+	frBytes, err := FrontierReqToProto(NewFrontierRangeReq(start, end))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := FrontierReqFromProto(frBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.StartHash != start || req.EndHash != end {
+		t.Fatal("range error", req.StartHash, req.EndHash)
+	}
+	for _, c := range []struct {
+		first   byte
+		inRange bool
+	}{{0x0f, false}, {0x10, true}, {0x1f, true}, {0x20, false}} {
+		hash[0] = c.first
+		if req.InRange(hash) != c.inRange {
+			t.Fatal("invalid range check of", hash)
+		}
+	}
+	req.EndHash = types.ZeroHash
+	hash[0] = 0xff
+	if !req.InRange(hash) {
+		t.Fatal("range without end should contain", hash)
+	}
+}
+
 func TestFrontierRsp(t *testing.T) {
 	Frontier := new(types.Frontier)
 	err := Frontier.HeaderBlock.Of(HeaderBlockHash)
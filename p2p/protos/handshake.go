@@ -0,0 +1,61 @@
+package protos
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/p2p/protos/pb"
+)
+
+// Handshake is exchanged by both peers when a stream is opened, peers on another network or chain are disconnected.
+// Pruned is set by the peers which do not keep the full history of the accounts.
+type Handshake struct {
+	NetworkID     uint32
+	GenesisHash   types.Hash
+	Version       uint32
+	FrontierCount uint64
+	Pruned        bool
+}
+
+func NewHandshake(networkID uint32, genesis types.Hash, version uint32, frontierCount uint64) *Handshake {
+	return &Handshake{
+		NetworkID:     networkID,
+		GenesisHash:   genesis,
+		Version:       version,
+		FrontierCount: frontierCount,
+	}
+}
+
+// HandshakeToProto converts domain Handshake into proto Handshake
+func HandshakeToProto(hs *Handshake) ([]byte, error) {
+	hsPb := &pb.Handshake{
+		NetworkID:     hs.NetworkID,
+		GenesisHash:   hs.GenesisHash[:],
+		Version:       hs.Version,
+		FrontierCount: hs.FrontierCount,
+		Pruned:        hs.Pruned,
+	}
+	data, err := proto.Marshal(hsPb)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// HandshakeFromProto parse the data into Handshake message
+func HandshakeFromProto(data []byte) (*Handshake, error) {
+	hs := new(pb.Handshake)
+	if err := proto.Unmarshal(data, hs); err != nil {
+		return nil, err
+	}
+	genesis, err := types.BytesToHash(hs.GenesisHash)
+	if err != nil {
+		return nil, err
+	}
+	return &Handshake{
+		NetworkID:     hs.NetworkID,
+		GenesisHash:   genesis,
+		Version:       hs.Version,
+		FrontierCount: hs.FrontierCount,
+		Pruned:        hs.Pruned,
+	}, nil
+}
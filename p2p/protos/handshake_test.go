@@ -0,0 +1,30 @@
+package protos
+
+import (
+	"testing"
+
+	"github.com/qlcchain/go-qlc/common/types"
+)
+
+func TestHandshake(t *testing.T) {
+	var genesis types.Hash
+	if err := genesis.Of(HeaderBlockHash); err != nil {
+		t.Fatal(err)
+	}
+	hs := NewHandshake(2, genesis, 5, 1024)
+	hs.Pruned = true
+	data, err := HandshakeToProto(hs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs2, err := HandshakeFromProto(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *hs2 != *hs {
+		t.Fatal("handshake mismatch", hs2)
+	}
+	if _, err := HandshakeFromProto([]byte{0x12, 0x01, 0x01}); err == nil {
+		t.Fatal("invalid genesis hash should be rejected")
+	}
+}
@@ -22,6 +22,8 @@ type FrontierReq struct {
 	Address              []byte   `protobuf:"bytes,1,opt,name=Address,proto3" json:"Address,omitempty"`
 	Age                  uint32   `protobuf:"varint,2,opt,name=Age,proto3" json:"Age,omitempty"`
 	Count                uint32   `protobuf:"varint,3,opt,name=Count,proto3" json:"Count,omitempty"`
+	StartHash            []byte   `protobuf:"bytes,4,opt,name=StartHash,proto3" json:"StartHash,omitempty"`
+	EndHash              []byte   `protobuf:"bytes,5,opt,name=EndHash,proto3" json:"EndHash,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -72,6 +74,20 @@ func (m *FrontierReq) GetCount() uint32 {
 	return 0
 }
 
+func (m *FrontierReq) GetStartHash() []byte {
+	if m != nil {
+		return m.StartHash
+	}
+	return nil
+}
+
+func (m *FrontierReq) GetEndHash() []byte {
+	if m != nil {
+		return m.EndHash
+	}
+	return nil
+}
+
 type FrontierRsp struct {
 	TotalFrontierNum     uint32   `protobuf:"varint,1,opt,name=TotalFrontierNum,proto3" json:"TotalFrontierNum,omitempty"`
 	HeaderBlock          []byte   `protobuf:"bytes,2,opt,name=HeaderBlock,proto3" json:"HeaderBlock,omitempty"`
@@ -426,6 +442,76 @@ func (m *ConfirmAck) GetBlock() []byte {
 	return nil
 }
 
+type Handshake struct {
+	NetworkID            uint32   `protobuf:"varint,1,opt,name=NetworkID,proto3" json:"NetworkID,omitempty"`
+	GenesisHash          []byte   `protobuf:"bytes,2,opt,name=GenesisHash,proto3" json:"GenesisHash,omitempty"`
+	Version              uint32   `protobuf:"varint,3,opt,name=Version,proto3" json:"Version,omitempty"`
+	FrontierCount        uint64   `protobuf:"varint,4,opt,name=FrontierCount,proto3" json:"FrontierCount,omitempty"`
+	Pruned               bool     `protobuf:"varint,5,opt,name=Pruned,proto3" json:"Pruned,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Handshake) Reset()         { *m = Handshake{} }
+func (m *Handshake) String() string { return proto.CompactTextString(m) }
+func (*Handshake) ProtoMessage()    {}
+func (*Handshake) Descriptor() ([]byte, []int) {
+	return fileDescriptor_33c57e4bae7b9afd, []int{8}
+}
+func (m *Handshake) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Handshake.Unmarshal(m, b)
+}
+func (m *Handshake) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Handshake.Marshal(b, m, deterministic)
+}
+func (dst *Handshake) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Handshake.Merge(dst, src)
+}
+func (m *Handshake) XXX_Size() int {
+	return xxx_messageInfo_Handshake.Size(m)
+}
+func (m *Handshake) XXX_DiscardUnknown() {
+	xxx_messageInfo_Handshake.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Handshake proto.InternalMessageInfo
+
+func (m *Handshake) GetNetworkID() uint32 {
+	if m != nil {
+		return m.NetworkID
+	}
+	return 0
+}
+
+func (m *Handshake) GetGenesisHash() []byte {
+	if m != nil {
+		return m.GenesisHash
+	}
+	return nil
+}
+
+func (m *Handshake) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Handshake) GetFrontierCount() uint64 {
+	if m != nil {
+		return m.FrontierCount
+	}
+	return 0
+}
+
+func (m *Handshake) GetPruned() bool {
+	if m != nil {
+		return m.Pruned
+	}
+	return false
+}
+
 func init() {
 	proto.RegisterType((*FrontierReq)(nil), "pb.FrontierReq")
 	proto.RegisterType((*FrontierRsp)(nil), "pb.FrontierRsp")
@@ -435,31 +521,37 @@ func init() {
 	proto.RegisterType((*PublishBlock)(nil), "pb.PublishBlock")
 	proto.RegisterType((*ConfirmReq)(nil), "pb.ConfirmReq")
 	proto.RegisterType((*ConfirmAck)(nil), "pb.ConfirmAck")
+	proto.RegisterType((*Handshake)(nil), "pb.Handshake")
 }
 
 func init() { proto.RegisterFile("message.proto", fileDescriptor_33c57e4bae7b9afd) }
 
 var fileDescriptor_33c57e4bae7b9afd = []byte{
-	// 323 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x92, 0xcd, 0x4e, 0xc2, 0x40,
-	0x14, 0x85, 0x53, 0x10, 0x95, 0x0b, 0x4d, 0xc8, 0xc4, 0x45, 0x63, 0x58, 0x90, 0xae, 0x88, 0x0b,
-	0x37, 0xbe, 0x80, 0x85, 0x60, 0x58, 0x09, 0x29, 0xbe, 0x40, 0x7f, 0xae, 0xd0, 0x50, 0x66, 0x86,
-	0xf9, 0x59, 0xf0, 0x1e, 0x3e, 0xb0, 0x99, 0x99, 0xfe, 0x19, 0xe3, 0x82, 0xb8, 0xeb, 0x39, 0x37,
-	0xf9, 0xe6, 0xdc, 0xdb, 0x03, 0xfe, 0x09, 0xa5, 0x4c, 0xf6, 0xf8, 0xcc, 0x05, 0x53, 0x8c, 0xf4,
-	0x78, 0x1a, 0x6e, 0x60, 0xf4, 0x26, 0x18, 0x55, 0x05, 0x8a, 0x18, 0xcf, 0x24, 0x80, 0xbb, 0x28,
-	0xcf, 0x05, 0x4a, 0x19, 0x78, 0x33, 0x6f, 0x3e, 0x8e, 0x6b, 0x49, 0x26, 0xd0, 0x8f, 0xf6, 0x18,
-	0xf4, 0x66, 0xde, 0xdc, 0x8f, 0xcd, 0x27, 0x79, 0x80, 0xc1, 0x92, 0x69, 0xaa, 0x82, 0xbe, 0xf5,
-	0x9c, 0x08, 0x2f, 0x1d, 0xa0, 0xe4, 0xe4, 0x09, 0x26, 0x1f, 0x4c, 0x25, 0x65, 0xed, 0xbd, 0xeb,
-	0x93, 0x25, 0xfb, 0xf1, 0x2f, 0x9f, 0xcc, 0x60, 0xb4, 0xc6, 0x24, 0x47, 0xb1, 0x28, 0x59, 0x76,
-	0xb4, 0x4f, 0x8d, 0xe3, 0xae, 0x45, 0xa6, 0x30, 0xdc, 0x70, 0xa4, 0x6e, 0xde, 0xb7, 0xf3, 0xd6,
-	0x08, 0x57, 0x30, 0x5a, 0xe8, 0xf2, 0xb8, 0xd5, 0x65, 0x69, 0x76, 0x99, 0xc2, 0x70, 0xa7, 0x12,
-	0xa1, 0xd6, 0x89, 0x3c, 0x54, 0xdb, 0xb4, 0x86, 0xd9, 0x74, 0x45, 0x73, 0x3b, 0x73, 0x0f, 0xd5,
-	0x32, 0x8c, 0x3a, 0x18, 0xc9, 0x0d, 0x26, 0x35, 0x78, 0x75, 0xe1, 0x58, 0x45, 0x6f, 0x0d, 0x73,
-	0x84, 0xb4, 0x93, 0xd6, 0x89, 0x70, 0x09, 0xbe, 0x43, 0xc8, 0x43, 0x13, 0xfc, 0x6a, 0xc8, 0x02,
-	0xc6, 0x5b, 0x9d, 0x96, 0xc5, 0x7f, 0x18, 0xaf, 0x00, 0x4b, 0x46, 0x3f, 0x0b, 0x71, 0xaa, 0x2e,
-	0x72, 0x35, 0xe1, 0xcb, 0x6b, 0x10, 0x51, 0x76, 0xb4, 0x05, 0xc9, 0x32, 0xfb, 0xdb, 0xeb, 0x82,
-	0x38, 0x69, 0xcf, 0x5d, 0xec, 0x69, 0xa2, 0xb4, 0xc0, 0x0a, 0xd1, 0x1a, 0xe4, 0x11, 0xee, 0x77,
-	0x78, 0xd6, 0x48, 0x33, 0xac, 0xfa, 0xd2, 0xe8, 0x9f, 0xb1, 0x6e, 0xfe, 0x8c, 0x35, 0xe8, 0xc4,
-	0x4a, 0x6f, 0x6d, 0x85, 0x5f, 0xbe, 0x03, 0x00, 0x00, 0xff, 0xff, 0xca, 0xdd, 0x5d, 0x66, 0xd3,
-	0x02, 0x00, 0x00,
+	// 405 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x93, 0xcd, 0xca, 0xd3, 0x40,
+	0x14, 0x86, 0x49, 0x7f, 0x3e, 0xbf, 0x9e, 0x36, 0x50, 0x06, 0x91, 0x20, 0x2e, 0x4a, 0x70, 0x51,
+	0x5c, 0xb8, 0xf1, 0x06, 0x4c, 0x6b, 0xb5, 0x6e, 0x6a, 0x49, 0xc5, 0xfd, 0x24, 0x39, 0xb6, 0x21,
+	0xe9, 0x4c, 0x3a, 0x3f, 0x48, 0xaf, 0xc0, 0x1b, 0xf0, 0x0e, 0xbc, 0x51, 0x99, 0x99, 0x24, 0x4d,
+	0x10, 0x85, 0xf2, 0xed, 0xfa, 0xbe, 0x81, 0x87, 0xe7, 0x9c, 0x9e, 0x01, 0xff, 0x8c, 0x52, 0xd2,
+	0x23, 0xbe, 0xad, 0x04, 0x57, 0x9c, 0x0c, 0xaa, 0x24, 0xfc, 0xe9, 0xc1, 0xf4, 0xa3, 0xe0, 0x4c,
+	0xe5, 0x28, 0x62, 0xbc, 0x90, 0x00, 0x9e, 0x45, 0x59, 0x26, 0x50, 0xca, 0xc0, 0x5b, 0x78, 0xcb,
+	0x59, 0xdc, 0x44, 0x32, 0x87, 0x61, 0x74, 0xc4, 0x60, 0xb0, 0xf0, 0x96, 0x7e, 0x6c, 0x7e, 0x92,
+	0xe7, 0x30, 0x5e, 0x73, 0xcd, 0x54, 0x30, 0xb4, 0x9d, 0x0b, 0xe4, 0x15, 0x4c, 0x0e, 0x8a, 0x0a,
+	0xb5, 0xa5, 0xf2, 0x14, 0x8c, 0x2c, 0xe3, 0x56, 0x18, 0xfe, 0x86, 0x65, 0xf6, 0xdb, 0xd8, 0xf1,
+	0xeb, 0x18, 0x5e, 0x3b, 0x22, 0xb2, 0x22, 0x6f, 0x60, 0xfe, 0x95, 0x2b, 0x5a, 0x36, 0xdd, 0x4e,
+	0x9f, 0xad, 0x91, 0x1f, 0xff, 0xd5, 0x93, 0x05, 0x4c, 0xb7, 0x48, 0x33, 0x14, 0xab, 0x92, 0xa7,
+	0x85, 0x55, 0x9c, 0xc5, 0xdd, 0xca, 0x48, 0x7d, 0xa9, 0x90, 0xb9, 0xef, 0x43, 0x27, 0xd5, 0x16,
+	0xe1, 0x06, 0xa6, 0x2b, 0x5d, 0x16, 0x7b, 0x5d, 0x96, 0x66, 0x07, 0xbd, 0x09, 0xbc, 0xff, 0x4c,
+	0x30, 0xe8, 0x4f, 0x10, 0x75, 0x30, 0xb2, 0x32, 0x98, 0xc4, 0xe0, 0xd5, 0xb5, 0xc2, 0x5a, 0xfd,
+	0x56, 0x98, 0xe5, 0x25, 0x1d, 0x5b, 0x17, 0xc2, 0x35, 0xf8, 0x0e, 0x21, 0x4f, 0xad, 0xf8, 0xdd,
+	0x90, 0x15, 0xcc, 0xf6, 0x3a, 0x29, 0xf3, 0xa7, 0x30, 0xde, 0x03, 0xac, 0x39, 0xfb, 0x9e, 0x8b,
+	0x73, 0xbd, 0x91, 0xbb, 0x09, 0xbf, 0xbc, 0x16, 0x11, 0xa5, 0x85, 0x3d, 0xac, 0x34, 0xb5, 0xe7,
+	0xd2, 0x1c, 0x96, 0x8b, 0x76, 0xdd, 0xf9, 0x91, 0x51, 0xa5, 0x05, 0xd6, 0x88, 0x5b, 0x41, 0x5e,
+	0xc2, 0xe3, 0x01, 0x2f, 0x1a, 0x59, 0x8a, 0xf5, 0x9d, 0xb5, 0xb9, 0xaf, 0x35, 0xfa, 0xa7, 0xd6,
+	0xb8, 0xab, 0xf5, 0xdb, 0x83, 0xc9, 0x96, 0xb2, 0x4c, 0x9e, 0x68, 0x61, 0x09, 0x3b, 0x54, 0x3f,
+	0xb8, 0x28, 0x3e, 0x7f, 0x68, 0x06, 0x6b, 0x0b, 0x73, 0x57, 0x9f, 0x90, 0xa1, 0xcc, 0x65, 0xe7,
+	0xef, 0xee, 0x56, 0x66, 0xaa, 0x6f, 0x28, 0x64, 0xce, 0x59, 0x2d, 0xd7, 0x44, 0xf2, 0x1a, 0xfc,
+	0xe6, 0x44, 0xdd, 0x23, 0x31, 0x7e, 0xa3, 0xb8, 0x5f, 0x92, 0x17, 0xf0, 0xb0, 0x17, 0x9a, 0x61,
+	0x66, 0x25, 0x1f, 0xe3, 0x3a, 0x25, 0x0f, 0xf6, 0x85, 0xbe, 0xfb, 0x33, 0x00, 0x4e, 0x3c, 0xc9,
+	0xe1, 0xb2, 0x03, 0x00, 0x00,
 }
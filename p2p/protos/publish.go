@@ -1,6 +1,8 @@
 package protos
 
 import (
+	"errors"
+
 	"github.com/gogo/protobuf/proto"
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/p2p/protos/pb"
@@ -34,6 +36,9 @@ func PublishBlockFromProto(data []byte) (*PublishBlock, error) {
 	if err := proto.Unmarshal(data, bp); err != nil {
 		return nil, err
 	}
+	if types.BlockType(bp.Blocktype) == types.SmartContract {
+		return nil, errors.New("publish block is a smart contract block")
+	}
 	blk := new(types.StateBlock)
 	if err := blk.Deserialize(bp.Block); err != nil {
 		return nil, err
@@ -43,3 +48,51 @@ func PublishBlockFromProto(data []byte) (*PublishBlock, error) {
 	}
 	return bPush, nil
 }
+
+// PublishContract is a smart contract block published in a PublishBlock message with the SmartContract block type,
+// a node of an older version fails to parse it as a state block and drops it
+type PublishContract struct {
+	Blk *types.SmartContractBlock
+}
+
+// PublishContractToProto converts domain PublishContract into proto PublishBlock
+func PublishContractToProto(publish *PublishContract) ([]byte, error) {
+	blkData, err := publish.Blk.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	bpPb := &pb.PublishBlock{
+		Blocktype: uint32(types.SmartContract),
+		Block:     blkData,
+	}
+	data, err := proto.Marshal(bpPb)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// PublishContractFromProto parse the data into PublishContract message
+func PublishContractFromProto(data []byte) (*PublishContract, error) {
+	bp := new(pb.PublishBlock)
+	if err := proto.Unmarshal(data, bp); err != nil {
+		return nil, err
+	}
+	if types.BlockType(bp.Blocktype) != types.SmartContract {
+		return nil, errors.New("publish block is not a smart contract block")
+	}
+	blk := new(types.SmartContractBlock)
+	if err := blk.Deserialize(bp.Block); err != nil {
+		return nil, err
+	}
+	return &PublishContract{Blk: blk}, nil
+}
+
+// PublishBlockType returns the type of the block published in the data
+func PublishBlockType(data []byte) (types.BlockType, error) {
+	bp := new(pb.PublishBlock)
+	if err := proto.Unmarshal(data, bp); err != nil {
+		return types.Invalid, err
+	}
+	return types.BlockType(bp.Blocktype), nil
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/test/mock"
 )
 
 var (
@@ -48,3 +49,28 @@ func TestPublishPacket(t *testing.T) {
 		t.Fatal("hash error")
 	}
 }
+
+func TestPublishContractPacket(t *testing.T) {
+	blk := &types.SmartContractBlock{
+		Address:   mock.Address(),
+		Owner:     mock.Address(),
+		AbiSchema: "[]",
+	}
+	bytes, err := PublishContractToProto(&PublishContract{Blk: blk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ, err := PublishBlockType(bytes); err != nil || typ != types.SmartContract {
+		t.Fatal(typ, err)
+	}
+	block, err := PublishContractFromProto(bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blk.GetHash() != block.Blk.GetHash() {
+		t.Fatal("hash error")
+	}
+	if _, err := PublishBlockFromProto(bytes); err == nil {
+		t.Fatal("smart contract block should not be parsed as state block")
+	}
+}
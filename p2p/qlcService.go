@@ -80,6 +80,31 @@ func (ns *QlcService) setEvent() error {
 		ns.node.logger.Error(err)
 		return err
 	}
+	err = ns.msgEvent.Subscribe(string(common.EventPeersInfo), ns.node.streamManager.GetPeersInfo)
+	if err != nil {
+		ns.node.logger.Error(err)
+		return err
+	}
+	err = ns.msgEvent.Subscribe(string(common.EventConnectPeer), ns.connectPeer)
+	if err != nil {
+		ns.node.logger.Error(err)
+		return err
+	}
+	err = ns.msgEvent.Subscribe(string(common.EventDisconnectPeer), ns.disconnectPeer)
+	if err != nil {
+		ns.node.logger.Error(err)
+		return err
+	}
+	err = ns.msgEvent.SubscribeAsync(string(common.EventPenalizePeer), ns.node.streamManager.Penalize, false)
+	if err != nil {
+		ns.node.logger.Error(err)
+		return err
+	}
+	err = ns.msgEvent.Subscribe(string(common.EventDeliveryStats), ns.msgService.DeliveryStats)
+	if err != nil {
+		ns.node.logger.Error(err)
+		return err
+	}
 	return nil
 }
 
@@ -94,9 +119,44 @@ func (ns *QlcService) unsubscribeEvent() error {
 		ns.node.logger.Error(err)
 		return err
 	}
+	err = ns.msgEvent.Unsubscribe(string(common.EventPeersInfo), ns.node.streamManager.GetPeersInfo)
+	if err != nil {
+		ns.node.logger.Error(err)
+		return err
+	}
+	err = ns.msgEvent.Unsubscribe(string(common.EventConnectPeer), ns.connectPeer)
+	if err != nil {
+		ns.node.logger.Error(err)
+		return err
+	}
+	err = ns.msgEvent.Unsubscribe(string(common.EventDisconnectPeer), ns.disconnectPeer)
+	if err != nil {
+		ns.node.logger.Error(err)
+		return err
+	}
+	err = ns.msgEvent.Unsubscribe(string(common.EventPenalizePeer), ns.node.streamManager.Penalize)
+	if err != nil {
+		ns.node.logger.Error(err)
+		return err
+	}
+	err = ns.msgEvent.Unsubscribe(string(common.EventDeliveryStats), ns.msgService.DeliveryStats)
+	if err != nil {
+		ns.node.logger.Error(err)
+		return err
+	}
 	return nil
 }
 
+// connectPeer is the callback of EventConnectPeer, the result is returned by err
+func (ns *QlcService) connectPeer(addr string, err *error) {
+	*err = ns.node.ConnectPeer(addr)
+}
+
+// disconnectPeer is the callback of EventDisconnectPeer, the result is returned by err
+func (ns *QlcService) disconnectPeer(peerID string, err *error) {
+	*err = ns.node.DisconnectPeer(peerID)
+}
+
 // Stop stop p2p manager.
 func (ns *QlcService) Stop() error {
 	//ns.node.logger.Info("Stopping QlcService...")
@@ -1,24 +1,36 @@
 package p2p
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	libnet "github.com/libp2p/go-libp2p-net"
 	peer "github.com/libp2p/go-libp2p-peer"
 	ma "github.com/multiformats/go-multiaddr"
+	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/p2p/protos"
 )
 
 // Stream Errors
 var (
 	ErrStreamIsNotConnected = errors.New("stream is not connected")
 	ErrNoStream             = errors.New("no stream")
+	ErrHandshakeRequired    = errors.New("message received before handshake")
+)
+
+const (
+	streamPingInterval = 30 * time.Second
+	streamPingTimeout  = 10 * time.Second
 )
 
 // Stream define the structure of a stream in p2p network
 type Stream struct {
+	bytesIn     uint64
+	bytesOut    uint64
 	syncMutex   sync.Mutex
 	pid         peer.ID
 	addr        ma.Multiaddr
@@ -26,6 +38,9 @@ type Stream struct {
 	node        *QlcNode
 	quitWriteCh chan bool
 	messageChan chan []byte
+	handshake   *protos.Handshake
+	connectedAt time.Time
+	closed      bool
 }
 
 // NewStream return a new Stream
@@ -39,7 +54,7 @@ func NewStreamFromPID(pid peer.ID, node *QlcNode) *Stream {
 }
 
 func newStreamInstance(pid peer.ID, addr ma.Multiaddr, stream libnet.Stream, node *QlcNode) *Stream {
-	return &Stream{
+	s := &Stream{
 		pid:         pid,
 		addr:        addr,
 		stream:      stream,
@@ -47,6 +62,10 @@ func newStreamInstance(pid peer.ID, addr ma.Multiaddr, stream libnet.Stream, nod
 		quitWriteCh: make(chan bool, 1),
 		messageChan: make(chan []byte, 2*1024),
 	}
+	if stream != nil {
+		s.connectedAt = time.Now()
+	}
+	return s
 }
 
 // Connect to the stream
@@ -65,6 +84,7 @@ func (s *Stream) Connect() error {
 	//s.node.logger.Info("connect success to :", s.pid.Pretty())
 	s.stream = stream
 	s.addr = stream.Conn().RemoteMultiaddr()
+	s.connectedAt = time.Now()
 	return nil
 }
 
@@ -84,7 +104,6 @@ func (s *Stream) String() string {
 
 // StartLoop start stream ping loop.
 func (s *Stream) StartLoop() {
-	go s.writeLoop()
 	go s.readLoop()
 }
 
@@ -99,6 +118,14 @@ func (s *Stream) readLoop() {
 
 	}
 	s.node.logger.Info("connect ", s.pid.Pretty(), " success")
+	// the handshake must be the first message of the stream, so it is written before the write loop starts
+	if err := s.sendHandshake(); err != nil {
+		s.node.logger.Error(err)
+		s.close()
+		return
+	}
+	go s.writeLoop()
+	go s.pingLoop()
 	// loop.
 	buf := make([]byte, 1024*4)
 	messageBuffer := make([]byte, 0)
@@ -113,6 +140,7 @@ func (s *Stream) readLoop() {
 			return
 		}
 
+		atomic.AddUint64(&s.bytesIn, uint64(n))
 		messageBuffer = append(messageBuffer, buf[:n]...)
 
 		for {
@@ -126,6 +154,11 @@ func (s *Stream) readLoop() {
 				}
 				message, err = ParseQlcMessage(messageBuffer)
 				if err != nil {
+					s.misbehave(BadChecksum, err)
+					return
+				}
+				if message.DataLength() > QlcMessageMaxDataLength {
+					s.misbehave(OversizedMessage, ErrInvalidMessageDataLength)
 					return
 				}
 				messageBuffer = messageBuffer[QlcMessageHeaderLength:]
@@ -137,13 +170,18 @@ func (s *Stream) readLoop() {
 				break
 			}
 			if err := message.ParseMessageData(messageBuffer); err != nil {
+				s.misbehave(BadChecksum, err)
 				return
 			}
 			// remove data from buffer.
 			messageBuffer = messageBuffer[message.DataLength():]
 
 			// handle message.
-			s.handleMessage(message)
+			if err := s.handleMessage(message); err != nil {
+				s.node.logger.Infof("disconnect peer [%s]: %s", s.pid.Pretty(), err)
+				s.close()
+				return
+			}
 			// reset message.
 			message = nil
 		}
@@ -182,8 +220,12 @@ func (s *Stream) writeLoop() {
 // Close close the stream
 func (s *Stream) close() {
 	// Add lock & close flag to prevent multi call.
-	//s.syncMutex.Lock()
-	//defer s.syncMutex.Unlock()
+	s.syncMutex.Lock()
+	defer s.syncMutex.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
 	//s.node.logger.Info("Closing stream.")
 
 	// cleanup.
@@ -221,6 +263,7 @@ func (s *Stream) Write(data []byte) error {
 	}
 
 	n, err := s.stream.Write(data)
+	atomic.AddUint64(&s.bytesOut, uint64(n))
 	if err != nil {
 		s.node.logger.Errorf("Failed to send message to peer [%s].", s.pid.Pretty())
 		//s.close()
@@ -230,11 +273,107 @@ func (s *Stream) Write(data []byte) error {
 	return nil
 }
 
-func (s *Stream) handleMessage(message *QlcMessage) {
+func (s *Stream) handleMessage(message *QlcMessage) error {
+	if message.MessageType() == Handshake {
+		return s.onHandshake(message.MessageData())
+	}
+	if s.Handshake() == nil {
+		return ErrHandshakeRequired
+	}
 	if message.Version() < byte(p2pVersion) {
 		s.node.logger.Debugf("message Version [%d] is less then p2pVersion [%d]", message.Version(), p2pVersion)
-		return
+		return nil
 	}
 	m := NewMessage(message.MessageType(), s.pid.Pretty(), message.MessageData(), message.content)
 	s.node.netService.PutMessage(m)
+	return nil
+}
+
+// misbehave penalizes the peer and closes the stream, the framing of the stream is lost after a bad message
+func (s *Stream) misbehave(m Misbehavior, err error) {
+	s.node.logger.Infof("disconnect peer [%s]: %s", s.pid.Pretty(), err)
+	s.node.streamManager.Penalize(s.pid.Pretty(), m)
+	s.close()
+}
+
+// Handshake return the handshake of the peer, it is nil until the handshake is done
+func (s *Stream) Handshake() *protos.Handshake {
+	s.syncMutex.Lock()
+	defer s.syncMutex.Unlock()
+	return s.handshake
+}
+
+// pingLoop measures the latency of the peer until the stream is removed, the latency is recorded in the peer store
+func (s *Stream) pingLoop() {
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+	for {
+		if s.node.streamManager.FindByPeerID(s.pid.Pretty()) != s {
+			return
+		}
+		ctx, cancel := context.WithTimeout(s.node.ctx, streamPingTimeout)
+		if ts, err := s.node.ping.Ping(ctx, s.pid); err == nil {
+			<-ts
+		} else {
+			s.node.logger.Debugf("ping peer [%s] error: %s", s.pid.Pretty(), err)
+		}
+		cancel()
+		<-ticker.C
+	}
+}
+
+func (s *Stream) sendHandshake() error {
+	var count uint64
+	// a node which has ever been pruned can not serve the full history even if pruning is disabled now
+	pruned := s.node.cfg.Pruning != nil && s.node.cfg.Pruning.Enabled
+	if s.node.netService != nil {
+		l := s.node.netService.msgService.ledger
+		c, err := l.CountFrontiers()
+		if err != nil {
+			return err
+		}
+		count = c
+		t, err := l.PrunedTime()
+		if err != nil {
+			return err
+		}
+		pruned = pruned || t > 0
+	}
+	hs := protos.NewHandshake(common.NetworkID(), common.GenesisBlockHash(), uint32(p2pVersion), count)
+	hs.Pruned = pruned
+	data, err := protos.HandshakeToProto(hs)
+	if err != nil {
+		return err
+	}
+	return s.Write(NewQlcMessage(data, byte(p2pVersion), Handshake))
+}
+
+func (s *Stream) onHandshake(data []byte) error {
+	hs, err := protos.HandshakeFromProto(data)
+	if err != nil {
+		return err
+	}
+	if err := verifyHandshake(hs); err != nil {
+		return err
+	}
+	s.syncMutex.Lock()
+	s.handshake = hs
+	s.syncMutex.Unlock()
+	s.node.logger.Debugf("handshake with peer [%s] success, network %d, %d frontiers, pruned %t", s.pid.Pretty(),
+		hs.NetworkID, hs.FrontierCount, hs.Pruned)
+	return nil
+}
+
+// verifyHandshake checks that the peer runs on the same network and chain with a supported protocol version
+func verifyHandshake(hs *protos.Handshake) error {
+	if hs.NetworkID != common.NetworkID() {
+		return fmt.Errorf("peer is on network %d, expect %d", hs.NetworkID, common.NetworkID())
+	}
+	if hs.GenesisHash != common.GenesisBlockHash() {
+		return fmt.Errorf("peer is on chain %s, expect %s", hs.GenesisHash, common.GenesisBlockHash())
+	}
+	if hs.Version < uint32(p2pVersion) {
+		return fmt.Errorf("peer p2p version [%d] is less then p2pVersion [%d]", hs.Version, p2pVersion)
+	}
+	return nil
 }
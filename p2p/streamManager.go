@@ -2,7 +2,9 @@ package p2p
 
 import (
 	"math/rand"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	libnet "github.com/libp2p/go-libp2p-net"
@@ -15,12 +17,14 @@ type StreamManager struct {
 	mu         sync.Mutex
 	allStreams *sync.Map
 	node       *QlcNode
+	scores     map[string]*peerScore
 }
 
 // NewStreamManager return a new stream manager
 func NewStreamManager() *StreamManager {
 	return &StreamManager{
 		allStreams: new(sync.Map),
+		scores:     make(map[string]*peerScore),
 	}
 }
 
@@ -41,6 +45,14 @@ func (sm *StreamManager) AddStream(stream *Stream) {
 	//sm.mu.Lock()
 	//defer sm.mu.Unlock()
 
+	if sm.node.isBanned(stream.pid) {
+		sm.node.logger.Infof("Refused a stream of banned peer:[%s]", stream.pid.Pretty())
+		if stream.stream != nil {
+			stream.stream.Close()
+		}
+		return
+	}
+
 	// check & close old stream
 	if v, ok := sm.allStreams.Load(stream.pid.Pretty()); ok {
 		old, _ := v.(*Stream)
@@ -69,6 +81,7 @@ func (sm *StreamManager) RemoveStream(s *Stream) {
 		}
 		sm.node.logger.Debugf("Removing a stream:[%s]", s.pid.Pretty())
 		sm.allStreams.Delete(s.pid.Pretty())
+		sm.removePeerScores()
 	}
 }
 
@@ -80,16 +93,24 @@ func (sm *StreamManager) FindByPeerID(peerID string) *Stream {
 	return nil
 }
 
+// RandomPeer return a random connected peer, peers with a good score are preferred
 func (sm *StreamManager) RandomPeer() (string, error) {
 	allPeers := make(PeersSlice, 0)
+	goodPeers := make(PeersSlice, 0)
 
 	sm.allStreams.Range(func(key, value interface{}) bool {
 		stream := value.(*Stream)
 		if stream.IsConnected() {
 			allPeers = append(allPeers, value)
+			if sm.PeerScore(stream.pid.Pretty()) >= goodPeerScore {
+				goodPeers = append(goodPeers, value)
+			}
 		}
 		return true
 	})
+	if len(goodPeers) > 0 {
+		allPeers = goodPeers
+	}
 	var peerID string
 	rand.Seed(time.Now().Unix())
 	if (len(allPeers)) == 0 {
@@ -126,8 +147,6 @@ func (sm *StreamManager) createStreamWithPeer(pid peer.ID) {
 
 // BroadcastMessage broadcast the message
 func (sm *StreamManager) BroadcastMessage(messageName string, v interface{}) {
-	//var cs []*cacheValue
-	//var c *cacheValue
 	messageContent, err := marshalMessage(messageName, v)
 	if err != nil {
 		sm.node.logger.Error(err)
@@ -143,53 +162,14 @@ func (sm *StreamManager) BroadcastMessage(messageName string, v interface{}) {
 	sm.allStreams.Range(func(key, value interface{}) bool {
 		stream := value.(*Stream)
 		stream.messageChan <- message
-		if messageName == PublishReq || messageName == ConfirmReq || messageName == ConfirmAck {
-			sm.searchCache(stream, hash, message, messageName)
-			//exitCache, err := sm.node.netService.msgService.cache.Get(hash)
-			//if err == nil {
-			//	cs = exitCache.([]*cacheValue)
-			//	for k, v := range cs {
-			//		if v.peerID == stream.pid.Pretty() {
-			//			v.resendTimes++
-			//			break
-			//		}
-			//		if k == (len(cs) - 1) {
-			//			c = &cacheValue{
-			//				peerID:      stream.pid.Pretty(),
-			//				resendTimes: 0,
-			//				startTime:   time.Now(),
-			//				data:        message,
-			//				t:           messageName,
-			//			}
-			//			cs = append(cs, c)
-			//			err = sm.node.netService.msgService.cache.Set(hash, cs)
-			//			if err != nil {
-			//				sm.node.logger.Error(err)
-			//			}
-			//		}
-			//	}
-			//} else {
-			//	c = &cacheValue{
-			//		peerID:      stream.pid.Pretty(),
-			//		resendTimes: 0,
-			//		startTime:   time.Now(),
-			//		data:        message,
-			//		t:           messageName,
-			//	}
-			//	cs = append(cs, c)
-			//	err = sm.node.netService.msgService.cache.Set(hash, cs)
-			//	if err != nil {
-			//		sm.node.logger.Error(err)
-			//	}
-			//}
+		if needResponse(messageName) {
+			sm.node.netService.msgService.outbox.Add(stream.pid.Pretty(), hash, message, messageName)
 		}
 		return true
 	})
 }
 
 func (sm *StreamManager) SendMessageToPeers(messageName string, v interface{}, peerID string) {
-	//var cs []*cacheValue
-	//var c *cacheValue
 	messageContent, err := marshalMessage(messageName, v)
 	if err != nil {
 		sm.node.logger.Error(err)
@@ -206,93 +186,14 @@ func (sm *StreamManager) SendMessageToPeers(messageName string, v interface{}, p
 		stream := value.(*Stream)
 		if stream.pid.Pretty() != peerID {
 			stream.messageChan <- message
-			if messageName == PublishReq || messageName == ConfirmReq || messageName == ConfirmAck {
-				sm.searchCache(stream, hash, message, messageName)
-				//exitCache, err := sm.node.netService.msgService.cache.Get(hash)
-				//if err == nil {
-				//	cs = exitCache.([]*cacheValue)
-				//	for k, v := range cs {
-				//		if v.peerID == stream.pid.Pretty() {
-				//			v.resendTimes++
-				//			break
-				//		}
-				//		if k == (len(cs) - 1) {
-				//			c = &cacheValue{
-				//				peerID:      stream.pid.Pretty(),
-				//				resendTimes: 0,
-				//				startTime:   time.Now(),
-				//				data:        message,
-				//				t:           messageName,
-				//			}
-				//			cs = append(cs, c)
-				//			err = sm.node.netService.msgService.cache.Set(hash, cs)
-				//			if err != nil {
-				//				sm.node.logger.Error(err)
-				//			}
-				//		}
-				//	}
-				//} else {
-				//	c = &cacheValue{
-				//		peerID:      stream.pid.Pretty(),
-				//		resendTimes: 0,
-				//		startTime:   time.Now(),
-				//		data:        message,
-				//		t:           messageName,
-				//	}
-				//	cs = append(cs, c)
-				//	err = sm.node.netService.msgService.cache.Set(hash, cs)
-				//	if err != nil {
-				//		sm.node.logger.Error(err)
-				//	}
-				//}
+			if needResponse(messageName) {
+				sm.node.netService.msgService.outbox.Add(stream.pid.Pretty(), hash, message, messageName)
 			}
 		}
 		return true
 	})
 }
 
-func (sm *StreamManager) searchCache(stream *Stream, hash types.Hash, message []byte, messageName string) {
-	var cs []*cacheValue
-	var c *cacheValue
-	if sm.node.netService.msgService.cache.Has(hash) {
-		exitCache, _ := sm.node.netService.msgService.cache.Get(hash)
-		cs = exitCache.([]*cacheValue)
-		for k, v := range cs {
-			if v.peerID == stream.pid.Pretty() {
-				v.resendTimes++
-				break
-			}
-			if k == (len(cs) - 1) {
-				c = &cacheValue{
-					peerID:      stream.pid.Pretty(),
-					resendTimes: 0,
-					startTime:   time.Now(),
-					data:        message,
-					t:           messageName,
-				}
-				cs = append(cs, c)
-				err := sm.node.netService.msgService.cache.Set(hash, cs)
-				if err != nil {
-					sm.node.logger.Error(err)
-				}
-			}
-		}
-	} else {
-		c = &cacheValue{
-			peerID:      stream.pid.Pretty(),
-			resendTimes: 0,
-			startTime:   time.Now(),
-			data:        message,
-			t:           messageName,
-		}
-		cs = append(cs, c)
-		err := sm.node.netService.msgService.cache.Set(hash, cs)
-		if err != nil {
-			sm.node.logger.Error(err)
-		}
-	}
-}
-
 func (sm *StreamManager) PeerCounts() int {
 	allPeers := make(PeersSlice, 0)
 
@@ -306,3 +207,79 @@ func (sm *StreamManager) PeerCounts() int {
 
 	return len(allPeers)
 }
+
+// PeerInfo is the handshake result and the traffic of a connected peer, Latency is the average ping time in
+// nanoseconds, ConnectedSince is the unix time the stream was opened, Score is the reputation of the peer and Pruned
+// reports whether the peer has discarded the history of the accounts
+type PeerInfo struct {
+	ID             string        `json:"id"`
+	Address        string        `json:"address"`
+	NetworkID      uint32        `json:"networkId"`
+	GenesisHash    types.Hash    `json:"genesisHash"`
+	Version        uint32        `json:"version"`
+	FrontierCount  uint64        `json:"frontierCount"`
+	Pruned         bool          `json:"pruned"`
+	Latency        time.Duration `json:"latency"`
+	BytesIn        uint64        `json:"bytesIn"`
+	BytesOut       uint64        `json:"bytesOut"`
+	ConnectedSince int64         `json:"connectedSince"`
+	Score          int           `json:"score"`
+}
+
+// PeersInfo return the peers which have finished the handshake
+func (sm *StreamManager) PeersInfo() []*PeerInfo {
+	peers := make([]*PeerInfo, 0)
+	sm.allStreams.Range(func(key, value interface{}) bool {
+		stream := value.(*Stream)
+		hs := stream.Handshake()
+		if hs == nil {
+			return true
+		}
+		addr := ""
+		if stream.addr != nil {
+			addr = stream.addr.String()
+		}
+		var latency time.Duration
+		if sm.node.peerStore != nil {
+			latency = sm.node.peerStore.LatencyEWMA(stream.pid)
+		}
+		peers = append(peers, &PeerInfo{
+			ID:             stream.pid.Pretty(),
+			Address:        addr,
+			NetworkID:      hs.NetworkID,
+			GenesisHash:    hs.GenesisHash,
+			Version:        hs.Version,
+			FrontierCount:  hs.FrontierCount,
+			Pruned:         hs.Pruned,
+			Latency:        latency,
+			BytesIn:        atomic.LoadUint64(&stream.bytesIn),
+			BytesOut:       atomic.LoadUint64(&stream.bytesOut),
+			ConnectedSince: stream.connectedAt.Unix(),
+			Score:          sm.PeerScore(stream.pid.Pretty()),
+		})
+		return true
+	})
+	return peers
+}
+
+// SyncPeers returns the ids of the peers which have finished the handshake, the peers with higher scores come first.
+// The pruned peers are skipped since they can not serve the history of the accounts.
+func (sm *StreamManager) SyncPeers() []string {
+	peers := sm.PeersInfo()
+	sort.SliceStable(peers, func(i, j int) bool {
+		return peers[i].Score > peers[j].Score
+	})
+	ids := make([]string, 0, len(peers))
+	for _, p := range peers {
+		if p.Pruned {
+			continue
+		}
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+// GetPeersInfo fills p with the peers which have finished the handshake, it is the callback of EventPeersInfo
+func (sm *StreamManager) GetPeersInfo(p *[]*PeerInfo) {
+	*p = append(*p, sm.PeersInfo()...)
+}
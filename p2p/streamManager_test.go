@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/qlcchain/go-qlc/common"
 	"github.com/qlcchain/go-qlc/config"
 )
 
@@ -106,6 +107,10 @@ func Test_StreamManager(t *testing.T) {
 		t.Fatal("peer1 count error")
 	}
 
+	if p := waitHandshake(t, node1.node.streamManager, node2.node.ID.Pretty()); p.GenesisHash != common.GenesisBlockHash() {
+		t.Fatal("peer info error", p)
+	}
+
 	s := node1.node.streamManager.FindByPeerID(node2.node.ID.Pretty())
 	if s == nil {
 		t.Fatal("find peer2 error")
@@ -114,6 +119,10 @@ func Test_StreamManager(t *testing.T) {
 	if p1 != cfgFile2.P2P.ID.PeerID || err != nil {
 		t.Fatal("node1 random peer error")
 	}
+	node1.node.streamManager.Penalize(p1, NoResponse)
+	if p, err := node1.node.streamManager.RandomPeer(); p != p1 || err != nil {
+		t.Fatal("peer with a bad score should be chosen if there is no good peer")
+	}
 	node1.node.streamManager.RemoveStream(s)
 	if node1.node.streamManager.FindByPeerID(node2.node.ID.Pretty()) != nil {
 		t.Fatal("node1 RemoveStream error")
@@ -122,4 +131,25 @@ func Test_StreamManager(t *testing.T) {
 	if node1.node.streamManager.FindByPeerID(node2.node.ID.Pretty()) == nil {
 		t.Fatal("node1 create Stream With node2 error")
 	}
+	waitHandshake(t, node1.node.streamManager, node2.node.ID.Pretty())
+}
+
+// waitHandshake waits until the handshake with the peer is done, the stream of the peer may be replaced meanwhile
+// if both peers open a stream at the same time
+func waitHandshake(t *testing.T, sm *StreamManager, peerID string) *PeerInfo {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for {
+		for _, p := range sm.PeersInfo() {
+			if p.ID == peerID {
+				return p
+			}
+		}
+		select {
+		case <-ticker.C:
+			t.Fatal("handshake error")
+		default:
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
 }
@@ -0,0 +1,100 @@
+package p2p
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/config"
+	"github.com/qlcchain/go-qlc/p2p/protos"
+)
+
+func TestVerifyHandshake(t *testing.T) {
+	hs := protos.NewHandshake(common.NetworkID(), common.GenesisBlockHash(), uint32(p2pVersion), 10)
+	if err := verifyHandshake(hs); err != nil {
+		t.Fatal(err)
+	}
+	wrongNetwork := *hs
+	wrongNetwork.NetworkID = common.NetworkID() + 1
+	if err := verifyHandshake(&wrongNetwork); err == nil {
+		t.Fatal("peer on another network should be rejected")
+	}
+	wrongChain := *hs
+	wrongChain.GenesisHash = types.HashData([]byte("genesis"))
+	if err := verifyHandshake(&wrongChain); err == nil {
+		t.Fatal("peer on another chain should be rejected")
+	}
+	oldVersion := *hs
+	oldVersion.Version = uint32(p2pVersion - 1)
+	if err := verifyHandshake(&oldVersion); err == nil {
+		t.Fatal("peer with old p2p version should be rejected")
+	}
+}
+
+func TestStream_Handshake(t *testing.T) {
+	dir := filepath.Join(config.QlcTestDataDir(), "p2p", uuid.New().String())
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	cfg, err := config.DefaultConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := NewNode(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newStreamInstance(node.ID, nil, nil, node)
+
+	msg, err := ParseQlcMessage(NewQlcMessage([]byte{}, byte(p2pVersion), PublishReq))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.handleMessage(msg); err != ErrHandshakeRequired {
+		t.Fatal("message before handshake should be rejected", err)
+	}
+
+	hs := protos.NewHandshake(common.NetworkID()+1, common.GenesisBlockHash(), uint32(p2pVersion), 10)
+	if err := s.handleMessage(handshakeMessage(t, hs)); err == nil || s.Handshake() != nil {
+		t.Fatal("handshake from another network should be rejected")
+	}
+	hs.NetworkID = common.NetworkID()
+	if err := s.handleMessage(handshakeMessage(t, hs)); err != nil {
+		t.Fatal(err)
+	}
+	if s.Handshake() == nil || s.Handshake().FrontierCount != 10 {
+		t.Fatal("invalid handshake", s.Handshake())
+	}
+
+	// pruned peers are never asked for the history
+	node.streamManager.allStreams.Store(s.pid.Pretty(), s)
+	if peers := node.streamManager.SyncPeers(); len(peers) != 1 {
+		t.Fatal("invalid sync peers", peers)
+	}
+	hs.Pruned = true
+	if err := s.handleMessage(handshakeMessage(t, hs)); err != nil {
+		t.Fatal(err)
+	}
+	if peers := node.streamManager.SyncPeers(); len(peers) != 0 {
+		t.Fatal("pruned peer should not be a sync peer", peers)
+	}
+}
+
+func handshakeMessage(t *testing.T, hs *protos.Handshake) *QlcMessage {
+	data, err := protos.HandshakeToProto(hs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := NewQlcMessage(data, byte(p2pVersion), Handshake)
+	msg, err := ParseQlcMessage(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := msg.ParseMessageData(content[QlcMessageHeaderLength:]); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
@@ -1,12 +1,12 @@
 package p2p
 
 import (
-	"math"
+	"bytes"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/qlcchain/go-qlc/common"
-
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/ledger"
 	"github.com/qlcchain/go-qlc/log"
@@ -14,19 +14,43 @@ import (
 	"go.uber.org/zap"
 )
 
-var zeroHash = types.Hash{}
-var headerBlockHash types.Hash
-var openBlockHash types.Hash
-var bulkPush, bulkPull []*protos.Bulk
+const (
+	// syncRangeCount is the count of the ranges the accounts are split into by the first byte of the open block,
+	// it must divide 256
+	syncRangeCount = 16
+	// syncRangeTimeout is the time a peer has to answer the frontier request of a range
+	syncRangeTimeout = 30 * time.Second
+	// syncScheduleInterval is the interval the pending ranges of a round are assigned to the idle peers
+	syncScheduleInterval = time.Second
+	// syncMaxFrontiers is the most frontiers a peer can announce for a range, a peer of an older version ignores
+	// the range and announces all its frontiers
+	syncMaxFrontiers = 1 << 18
+)
 
-// Service manage sync tasks
+// syncTask is a range requested from a peer, the frontiers of the peer are collected until TotalFrontierNum, then
+// the range is done once the last blocks of all the account chains pulled from the peer are received
+type syncTask struct {
+	rng       *types.SyncRange
+	total     uint32
+	received  uint32
+	frontiers []*types.Frontier
+	pulls     map[types.Hash]bool //the last blocks of the account chains pulled, nil until the frontiers are collected
+	deadline  time.Time
+}
+
+// ServiceSync syncs the ledger with the peers, a sync round splits the accounts into ranges and requests the
+// frontiers of the ranges from several peers concurrently, every peer syncs one range at a time. The progress of
+// the round is kept in the ledger so an unfinished round is resumed after restart, a range which is not answered
+// in time is retried on another peer.
 type ServiceSync struct {
-	netService      *QlcService
-	qlcLedger       *ledger.Ledger
-	frontiers       []*types.Frontier
-	remoteFrontiers []*types.Frontier
-	quitCh          chan bool
-	logger          *zap.SugaredLogger
+	netService *QlcService
+	qlcLedger  *ledger.Ledger
+	quitCh     chan bool
+	logger     *zap.SugaredLogger
+	mu         sync.Mutex
+	progress   *types.SyncProgress
+	tasks      map[string]*syncTask    //peer id to the range requested from it
+	failed     map[int]map[string]bool //range index to the peers which did not answer it
 }
 
 // NewService return new Service.
@@ -36,73 +60,204 @@ func NewSyncService(netService *QlcService, ledger *ledger.Ledger) *ServiceSync
 		qlcLedger:  ledger,
 		quitCh:     make(chan bool, 1),
 		logger:     log.NewLogger("sync"),
+		tasks:      make(map[string]*syncTask),
+		failed:     make(map[int]map[string]bool),
 	}
+	ss.resume()
 	return ss
 }
 
 func (ss *ServiceSync) Start() {
 	ss.logger.Info("started sync loop")
-	address := types.Address{}
-	Req := protos.NewFrontierReq(address, math.MaxUint32, math.MaxUint32)
-	ticker := time.NewTicker(time.Duration(ss.netService.node.cfg.P2P.SyncInterval) * time.Second)
+	roundTicker := time.NewTicker(time.Duration(ss.netService.node.cfg.P2P.SyncInterval) * time.Second)
+	defer roundTicker.Stop()
+	scheduleTicker := time.NewTicker(syncScheduleInterval)
+	defer scheduleTicker.Stop()
 	for {
 		select {
 		case <-ss.quitCh:
 			ss.logger.Info("Stopped Sync Loop.")
 			return
-		case <-ticker.C:
-			peerID, err := ss.netService.node.StreamManager().RandomPeer()
-			if err != nil {
+		case <-roundTicker.C:
+			ss.newRound()
+		case <-scheduleTicker.C:
+			ss.schedule()
+		}
+	}
+}
+
+// Stop sync service
+func (ss *ServiceSync) Stop() {
+	//ss.logger.Info("Stop Qlc sync...")
+
+	ss.quitCh <- true
+}
+
+// resume loads the unfinished round from the ledger, the peers of the ranges are gone after restart
+func (ss *ServiceSync) resume() {
+	progress, err := ss.qlcLedger.GetSyncProgress()
+	if err != nil {
+		ss.logger.Error(err)
+		return
+	}
+	if progress == nil {
+		return
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	for _, r := range progress.Ranges {
+		r.Peer = ""
+	}
+	ss.progress = progress
+	ss.logger.Infof("resume sync round started at %d, %d of %d ranges done", progress.StartTime,
+		progress.DoneCount(), len(progress.Ranges))
+}
+
+// newRound starts a sync round if there is no unfinished one
+func (ss *ServiceSync) newRound() {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.progress != nil {
+		return
+	}
+	ss.progress = newSyncProgress(syncRangeCount)
+	ss.failed = make(map[int]map[string]bool)
+	ss.saveProgress()
+	ss.logger.Info("begin sync round")
+}
+
+func newSyncProgress(count int) *types.SyncProgress {
+	p := &types.SyncProgress{StartTime: time.Now().Unix()}
+	step := 256 / count
+	for i := 0; i < count; i++ {
+		r := &types.SyncRange{Index: i}
+		r.Start[0] = byte(i * step)
+		if i < count-1 {
+			r.End[0] = byte((i + 1) * step)
+		}
+		p.Ranges = append(p.Ranges, r)
+	}
+	return p
+}
+
+// schedule retries the ranges which are not answered in time and assigns the pending ranges to the idle peers
+func (ss *ServiceSync) schedule() {
+	var timeout []string
+	requests := make(map[string]*protos.FrontierReq)
+
+	ss.mu.Lock()
+	now := time.Now()
+	for peerID, task := range ss.tasks {
+		if now.After(task.deadline) {
+			ss.logger.Infof("range %d is not answered by [%s] in time", task.rng.Index, peerID)
+			ss.failTask(peerID, task)
+			timeout = append(timeout, peerID)
+		}
+	}
+	if ss.progress != nil {
+		peers := ss.netService.node.streamManager.SyncPeers()
+		for _, r := range ss.progress.Ranges {
+			if r.Done || r.Peer != "" {
 				continue
 			}
-			ss.frontiers, err = getLocalFrontier(ss.qlcLedger)
-			if err != nil {
+			peerID := ss.idlePeer(peers, r.Index)
+			if peerID == "" {
 				continue
 			}
-			ss.logger.Infof("begin sync block from [%s]", peerID)
-			ss.remoteFrontiers = ss.remoteFrontiers[:0:0]
-			ss.next()
-			bulkPull = bulkPull[:0:0]
-			bulkPush = bulkPush[:0:0]
-			err = ss.netService.node.SendMessageToPeer(FrontierRequest, Req, peerID)
-			if err != nil {
-				ss.logger.Errorf("err [%s] when send FrontierRequest", err)
+			r.Peer = peerID
+			ss.tasks[peerID] = &syncTask{rng: r, deadline: now.Add(syncRangeTimeout)}
+			requests[peerID] = protos.NewFrontierRangeReq(r.Start, r.End)
+		}
+		if len(timeout) > 0 || len(requests) > 0 {
+			ss.saveProgress()
+		}
+	}
+	ss.mu.Unlock()
+
+	for _, peerID := range timeout {
+		ss.netService.node.streamManager.Penalize(peerID, NoResponse)
+	}
+	for peerID, req := range requests {
+		if err := ss.netService.node.SendMessageToPeer(FrontierRequest, req, peerID); err != nil {
+			ss.logger.Errorf("err [%s] when send FrontierRequest to [%s]", err, peerID)
+		}
+	}
+}
+
+// idlePeer returns a peer which is not syncing any range, the peers which did not answer the range are skipped
+// unless all the peers did, it must be called with ss.mu held
+func (ss *ServiceSync) idlePeer(peers []string, index int) string {
+	failed := ss.failed[index]
+	if len(failed) > 0 {
+		all := true
+		for _, p := range peers {
+			if !failed[p] {
+				all = false
+				break
 			}
-		default:
-			time.Sleep(5 * time.Millisecond)
+		}
+		if all {
+			delete(ss.failed, index)
+			failed = nil
+		}
+	}
+	for _, p := range peers {
+		if _, ok := ss.tasks[p]; !ok && !failed[p] {
+			return p
 		}
 	}
+	return ""
 }
 
-// Stop sync service
-func (ss *ServiceSync) Stop() {
-	//ss.logger.Info("Stop Qlc sync...")
+// failTask gives up the range requested from the peer, the range is retried on another peer, it must be called with
+// ss.mu held
+func (ss *ServiceSync) failTask(peerID string, task *syncTask) {
+	if ss.tasks[peerID] != task {
+		return
+	}
+	delete(ss.tasks, peerID)
+	task.rng.Peer = ""
+	task.rng.Retries++
+	if ss.failed[task.rng.Index] == nil {
+		ss.failed[task.rng.Index] = make(map[string]bool)
+	}
+	ss.failed[task.rng.Index][peerID] = true
+}
 
-	ss.quitCh <- true
+// saveProgress must be called with ss.mu held
+func (ss *ServiceSync) saveProgress() {
+	if err := ss.qlcLedger.SetSyncProgress(ss.progress); err != nil {
+		ss.logger.Error(err)
+	}
 }
 
 func (ss *ServiceSync) onFrontierReq(message *Message) error {
 	ss.netService.node.logger.Info("receive FrontierReq")
-	var fs []*types.Frontier
+	req, err := protos.FrontierReqFromProto(message.Data())
+	if err != nil {
+		return err
+	}
 	fs, err := ss.qlcLedger.GetFrontiers()
 	if err != nil {
 		return err
 	}
-	num := len(fs)
-	var rsp *protos.FrontierResponse
+	var frontiers []*types.Frontier
 	for _, f := range fs {
-		rsp = protos.NewFrontierRsp(f, uint32(num))
-		err = ss.netService.SendMessageToPeer(FrontierRsp, rsp, message.MessageFrom())
+		if req.InRange(f.OpenBlock) {
+			frontiers = append(frontiers, f)
+		}
+	}
+	//there is no account in the range, send a zero frontier to tell the remote peer the range is empty
+	if len(frontiers) == 0 {
+		return ss.netService.SendMessageToPeer(FrontierRsp, protos.NewFrontierRsp(new(types.Frontier), 0), message.MessageFrom())
+	}
+	num := len(frontiers)
+	for _, f := range frontiers {
+		err = ss.netService.SendMessageToPeer(FrontierRsp, protos.NewFrontierRsp(f, uint32(num)), message.MessageFrom())
 		if err != nil {
 			ss.logger.Errorf("send FrontierRsp err [%s]", err)
 		}
 	}
-	//send frontier finished,last frontier is all zero,tell remote peer send finished
-	//zeroFrontier := new(types.Frontier)
-	//err = ss.netService.SendMessageToPeer(FrontierRsp, zeroFrontier, message.MessageFrom())
-	//if err != nil {
-	//	ss.logger.Errorf("send FrontierRsp err [%s] for zeroFrontier", err)
-	//}
 	return nil
 }
 
@@ -112,173 +267,199 @@ func (ss *ServiceSync) checkFrontier(message *Message) {
 		ss.logger.Error(err)
 		return
 	}
-	if uint32(len(ss.remoteFrontiers)) < rsp.TotalFrontierNum {
-		ss.remoteFrontiers = append(ss.remoteFrontiers, rsp.Frontier)
-		if uint32(len(ss.remoteFrontiers)) == rsp.TotalFrontierNum {
-			var remoteFrontiers []*types.Frontier
-			remoteFrontiers = append(remoteFrontiers, ss.remoteFrontiers...)
-			sort.Sort(types.Frontiers(remoteFrontiers))
-			zeroFrontier := new(types.Frontier)
-			remoteFrontiers = append(remoteFrontiers, zeroFrontier)
-			ss.remoteFrontiers = ss.remoteFrontiers[:0:0]
-			go ss.processFrontiers(remoteFrontiers, message.MessageFrom())
+	peerID := message.MessageFrom()
+
+	ss.mu.Lock()
+	task, ok := ss.tasks[peerID]
+	if !ok || task.pulls != nil {
+		ss.mu.Unlock()
+		return
+	}
+	if rsp.TotalFrontierNum > syncMaxFrontiers {
+		ss.logger.Infof("[%s] announces %d frontiers for range %d", peerID, rsp.TotalFrontierNum, task.rng.Index)
+		ss.failTask(peerID, task)
+		ss.saveProgress()
+		ss.mu.Unlock()
+		ss.netService.node.streamManager.Penalize(peerID, OversizedMessage)
+		return
+	}
+	// the count announced by the first response bounds the frontiers collected
+	if task.total == 0 {
+		task.total = rsp.TotalFrontierNum
+	}
+	if rsp.TotalFrontierNum > 0 {
+		task.received++
+		req := protos.NewFrontierRangeReq(task.rng.Start, task.rng.End)
+		if req.InRange(rsp.Frontier.OpenBlock) {
+			task.frontiers = append(task.frontiers, rsp.Frontier)
 		}
+	}
+	if task.received < task.total {
+		ss.mu.Unlock()
 		return
 	}
+	task.pulls = make(map[types.Hash]bool)
+	task.deadline = time.Now().Add(syncRangeTimeout)
+	ss.mu.Unlock()
 
+	ss.netService.node.streamManager.Reward(peerID)
+	go ss.syncRange(task, peerID)
 }
 
-func (ss *ServiceSync) processFrontiers(fsRemotes []*types.Frontier, peerID string) error {
-	//ss.netService.node.logger.Info("receive FrontierRsp")
-	//fsRemote, err := protos.FrontierResponseFromProto(message.Data())
-	//if err != nil {
-	//	return err
-	//}
-	//fr := fsRemote.Frontier
-	//ss.logger.Info(fr.HeaderBlock, fr.OpenBlock)
-	for i := 0; i < len(fsRemotes); i++ {
-		if !fsRemotes[i].OpenBlock.IsZero() {
-			for {
-				if !openBlockHash.IsZero() && (openBlockHash.String() < fsRemotes[i].OpenBlock.String()) {
-					// We have an account but remote peer have not.
-					push := &protos.Bulk{
-						StartHash: zeroHash,
-						EndHash:   headerBlockHash,
-					}
-					bulkPush = append(bulkPush, push)
-					ss.next()
-				} else {
-					break
-				}
-			}
-			if !openBlockHash.IsZero() {
-				if fsRemotes[i].OpenBlock == openBlockHash {
-					if headerBlockHash == fsRemotes[i].HeaderBlock {
-						//ss.logger.Infof("this token %s have the same block", openBlockHash)
-					} else {
-						exit, _ := ss.qlcLedger.HasStateBlock(fsRemotes[i].HeaderBlock)
-						if exit == true {
-							push := &protos.Bulk{
-								StartHash: fsRemotes[i].HeaderBlock,
-								EndHash:   headerBlockHash,
-							}
-							bulkPush = append(bulkPush, push)
-						} else {
-							pull := &protos.Bulk{
-								StartHash: headerBlockHash,
-								EndHash:   fsRemotes[i].HeaderBlock,
-							}
-							bulkPull = append(bulkPull, pull)
-						}
-					}
-					ss.next()
-				} else {
-					if fsRemotes[i].OpenBlock.String() > openBlockHash.String() {
-						return nil
-					}
-					pull := &protos.Bulk{
-						StartHash: zeroHash,
-						EndHash:   fsRemotes[i].HeaderBlock,
-					}
-					bulkPull = append(bulkPull, pull)
-				}
-			} else {
-				pull := &protos.Bulk{
-					StartHash: zeroHash,
-					EndHash:   fsRemotes[i].HeaderBlock,
-				}
-				bulkPull = append(bulkPull, pull)
+// syncRange pulls the blocks the peer has and pushes the blocks the peer does not have in the range, the range is
+// done when the pulled blocks are received, or at once if nothing is pulled
+func (ss *ServiceSync) syncRange(task *syncTask, peerID string) {
+	local, err := ss.qlcLedger.GetFrontiers()
+	if err != nil {
+		ss.logger.Error(err)
+		return
+	}
+	req := protos.NewFrontierRangeReq(task.rng.Start, task.rng.End)
+	var frontiers []*types.Frontier
+	for _, f := range local {
+		if req.InRange(f.OpenBlock) {
+			frontiers = append(frontiers, f)
+		}
+	}
+	remote := task.frontiers
+	sort.Sort(types.Frontiers(remote))
+
+	pulls, pushes := compareFrontiers(frontiers, remote, func(hash types.Hash) bool {
+		exist, _ := ss.qlcLedger.HasStateBlock(hash)
+		return exist
+	})
+	ss.mu.Lock()
+	for _, pull := range pulls {
+		task.pulls[pull.EndHash] = true
+	}
+	ss.mu.Unlock()
+
+	for _, pull := range pulls {
+		blkReq := &protos.BulkPullReqPacket{
+			StartHash: pull.StartHash,
+			EndHash:   pull.EndHash,
+		}
+		if err := ss.netService.SendMessageToPeer(BulkPullRequest, blkReq, peerID); err != nil {
+			ss.logger.Errorf("err [%s] when send BulkPullRequest, range %d is retried", err, task.rng.Index)
+			ss.mu.Lock()
+			ss.failTask(peerID, task)
+			ss.saveProgress()
+			ss.mu.Unlock()
+			return
+		}
+	}
+	for _, push := range pushes {
+		blocks, err := ss.bulkBlocks(push.StartHash, push.EndHash)
+		if err != nil {
+			ss.logger.Error(err)
+			continue
+		}
+		for _, blk := range blocks {
+			if err := ss.netService.SendMessageToPeer(BulkPushBlock, blk, peerID); err != nil {
+				ss.logger.Errorf("err [%s] when send BulkPushBlock", err)
 			}
-		} else {
-			for {
-				if !openBlockHash.IsZero() {
-					// We have an account but remote peer have not.
-					push := &protos.Bulk{
-						StartHash: zeroHash,
-						EndHash:   headerBlockHash,
-					}
-					bulkPush = append(bulkPush, push)
-					ss.next()
+		}
+	}
+
+	if len(pulls) == 0 {
+		ss.mu.Lock()
+		defer ss.mu.Unlock()
+		ss.finishTask(peerID, task)
+	}
+}
+
+// pulled records a block pulled from the peer, the range requested from the peer is done when the last blocks of
+// all the account chains pulled are received
+func (ss *ServiceSync) pulled(peerID string, hash types.Hash) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	task, ok := ss.tasks[peerID]
+	if !ok || !task.pulls[hash] {
+		return
+	}
+	delete(task.pulls, hash)
+	task.deadline = time.Now().Add(syncRangeTimeout)
+	if len(task.pulls) == 0 {
+		ss.finishTask(peerID, task)
+	}
+}
+
+// finishTask marks the range requested from the peer done and finishes the round if all the ranges are done, it
+// must be called with ss.mu held
+func (ss *ServiceSync) finishTask(peerID string, task *syncTask) {
+	if ss.tasks[peerID] != task {
+		return
+	}
+	delete(ss.tasks, peerID)
+	task.rng.Done = true
+	task.rng.Peer = ""
+	if ss.progress == nil || !ss.progress.Finished() {
+		ss.saveProgress()
+		return
+	}
+	ss.logger.Infof("sync round started at %d finished", ss.progress.StartTime)
+	ss.progress = nil
+	if err := ss.qlcLedger.DeleteSyncProgress(); err != nil {
+		ss.logger.Error(err)
+	}
+}
+
+// compareFrontiers compares the local and remote frontiers sorted by the open block, it returns the blocks to pull
+// from the remote peer and the blocks to push to it, a bulk with a zero StartHash is the whole account chain
+func compareFrontiers(local, remote []*types.Frontier, hasBlock func(types.Hash) bool) (pulls, pushes []*protos.Bulk) {
+	i, j := 0, 0
+	for i < len(local) || j < len(remote) {
+		var c int
+		switch {
+		case i == len(local):
+			c = 1
+		case j == len(remote):
+			c = -1
+		default:
+			c = bytes.Compare(local[i].OpenBlock[:], remote[j].OpenBlock[:])
+		}
+		switch {
+		case c < 0:
+			// We have an account but remote peer have not.
+			pushes = append(pushes, &protos.Bulk{StartHash: types.ZeroHash, EndHash: local[i].HeaderBlock})
+			i++
+		case c > 0:
+			pulls = append(pulls, &protos.Bulk{StartHash: types.ZeroHash, EndHash: remote[j].HeaderBlock})
+			j++
+		default:
+			if local[i].HeaderBlock != remote[j].HeaderBlock {
+				if hasBlock(remote[j].HeaderBlock) {
+					pushes = append(pushes, &protos.Bulk{StartHash: remote[j].HeaderBlock, EndHash: local[i].HeaderBlock})
 				} else {
-					if len(ss.frontiers) == 0 {
-						getLocalFrontier(ss.qlcLedger)
-						ss.next()
-					}
-					for _, value := range bulkPull {
-						blkReq := &protos.BulkPullReqPacket{
-							StartHash: value.StartHash,
-							EndHash:   value.EndHash,
-						}
-						err := ss.netService.SendMessageToPeer(BulkPullRequest, blkReq, peerID)
-						if err != nil {
-							ss.logger.Errorf("err [%s] when send BulkPullRequest", err)
-						}
-					}
-					for _, value := range bulkPush {
-						startHash := value.StartHash
-						endHash := value.EndHash
-						var err error
-						if startHash.IsZero() {
-							//ss.logger.Infof("need to send all the blocks of this account")
-							var blk *types.StateBlock
-							var bulkBlk []*types.StateBlock
-							for {
-								blk, err = ss.qlcLedger.GetStateBlock(endHash)
-								if err != nil {
-									return err
-								}
-								bulkBlk = append(bulkBlk, blk)
-								endHash = blk.GetPrevious()
-								if endHash.IsZero() == true {
-									break
-								}
-							}
-							for i := len(bulkBlk) - 1; i >= 0; i-- {
-								err = ss.netService.SendMessageToPeer(BulkPushBlock, bulkBlk[i], peerID)
-								if err != nil {
-									ss.logger.Errorf("err [%s] when send BulkPushBlock", err)
-								}
-							}
-						} else {
-							//ss.logger.Info("need to send some blocks of this account")
-							var blk *types.StateBlock
-							var bulkBlk []*types.StateBlock
-							for {
-								blk, err = ss.qlcLedger.GetStateBlock(endHash)
-								if err != nil {
-									return err
-								}
-								bulkBlk = append(bulkBlk, blk)
-
-								endHash = blk.GetPrevious()
-								if endHash == startHash {
-									break
-								}
-							}
-							for i := len(bulkBlk) - 1; i >= 0; i-- {
-								err = ss.netService.SendMessageToPeer(BulkPushBlock, bulkBlk[i], peerID)
-								if err != nil {
-									ss.logger.Errorf("err [%s] when send BulkPushBlock", err)
-								}
-							}
-						}
-					}
-					break
+					pulls = append(pulls, &protos.Bulk{StartHash: local[i].HeaderBlock, EndHash: remote[j].HeaderBlock})
 				}
 			}
+			i++
+			j++
 		}
 	}
-	return nil
+	return
 }
 
-func getLocalFrontier(ledger *ledger.Ledger) ([]*types.Frontier, error) {
-	frontiers, err := ledger.GetFrontiers()
-	if err != nil {
-		return nil, err
+// bulkBlocks returns the blocks after startHash up to endHash in chain order, all the blocks of the account up to
+// endHash if startHash is zero
+func (ss *ServiceSync) bulkBlocks(startHash, endHash types.Hash) ([]*types.StateBlock, error) {
+	var bulkBlk []*types.StateBlock
+	for {
+		blk, err := ss.qlcLedger.GetStateBlock(endHash)
+		if err != nil {
+			return nil, err
+		}
+		bulkBlk = append(bulkBlk, blk)
+		endHash = blk.GetPrevious()
+		if endHash.IsZero() || endHash == startHash {
+			break
+		}
 	}
-	fsBack := new(types.Frontier)
-	frontiers = append(frontiers, fsBack)
-	return frontiers, nil
+	for i, j := 0, len(bulkBlk)-1; i < j; i, j = i+1, j-1 {
+		bulkBlk[i], bulkBlk[j] = bulkBlk[j], bulkBlk[i]
+	}
+	return bulkBlk, nil
 }
 
 func (ss *ServiceSync) onBulkPullRequest(message *Message) error {
@@ -288,49 +469,14 @@ func (ss *ServiceSync) onBulkPullRequest(message *Message) error {
 		return err
 	}
 
-	startHash := pullRemote.StartHash
-	endHash := pullRemote.EndHash
-	if startHash.IsZero() {
-		var blk *types.StateBlock
-		var bulkBlk []*types.StateBlock
-		//ss.logger.Info("need to send all the blocks of this account")
-		for {
-			blk, err = ss.qlcLedger.GetStateBlock(endHash)
-			if err != nil {
-				return err
-			}
-			bulkBlk = append(bulkBlk, blk)
-			endHash = blk.GetPrevious()
-			if endHash.IsZero() == true {
-				break
-			}
-		}
-		for i := len(bulkBlk) - 1; i >= 0; i-- {
-			err = ss.netService.SendMessageToPeer(BulkPullRsp, bulkBlk[i], message.MessageFrom())
-			if err != nil {
-				ss.logger.Errorf("err [%s] when send BulkPullRsp", err)
-			}
-		}
-	} else {
-		var blk *types.StateBlock
-		var bulkBlk []*types.StateBlock
-		//ss.logger.Info("need to send some blocks of this account")
-		for {
-			blk, err = ss.qlcLedger.GetStateBlock(endHash)
-			if err != nil {
-				return err
-			}
-			bulkBlk = append(bulkBlk, blk)
-			endHash = blk.GetPrevious()
-			if endHash == startHash {
-				break
-			}
-		}
-		for i := len(bulkBlk) - 1; i >= 0; i-- {
-			err = ss.netService.SendMessageToPeer(BulkPullRsp, bulkBlk[i], message.MessageFrom())
-			if err != nil {
-				ss.logger.Errorf("err [%s] when send BulkPullRsp", err)
-			}
+	bulkBlk, err := ss.bulkBlocks(pullRemote.StartHash, pullRemote.EndHash)
+	if err != nil {
+		return err
+	}
+	for _, blk := range bulkBlk {
+		err = ss.netService.SendMessageToPeer(BulkPullRsp, blk, message.MessageFrom())
+		if err != nil {
+			ss.logger.Errorf("err [%s] when send BulkPullRsp", err)
 		}
 	}
 	return nil
@@ -348,7 +494,8 @@ func (ss *ServiceSync) onBulkPullRsp(message *Message) error {
 		hash := block.GetHash()
 		ss.netService.msgService.addPerformanceTime(hash)
 	}
-	ss.netService.msgEvent.Publish(string(common.EventSyncBlock), block)
+	ss.netService.msgEvent.Publish(string(common.EventSyncBlock), block, message.MessageFrom())
+	ss.pulled(message.MessageFrom(), block.GetHash())
 	return nil
 }
 
@@ -364,14 +511,6 @@ func (ss *ServiceSync) onBulkPushBlock(message *Message) error {
 		hash := block.GetHash()
 		ss.netService.msgService.addPerformanceTime(hash)
 	}
-	ss.netService.msgEvent.Publish(string(common.EventSyncBlock), block)
+	ss.netService.msgEvent.Publish(string(common.EventSyncBlock), block, message.MessageFrom())
 	return nil
 }
-
-func (ss *ServiceSync) next() {
-	if len(ss.frontiers) > 0 {
-		openBlockHash = ss.frontiers[0].OpenBlock
-		headerBlockHash = ss.frontiers[0].HeaderBlock
-		ss.frontiers = ss.frontiers[1:]
-	}
-}
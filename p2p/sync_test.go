@@ -0,0 +1,166 @@
+package p2p
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/config"
+	"github.com/qlcchain/go-qlc/ledger"
+	"github.com/qlcchain/go-qlc/log"
+	"github.com/qlcchain/go-qlc/test/mock"
+)
+
+func testFrontier(open, header byte) *types.Frontier {
+	f := new(types.Frontier)
+	f.OpenBlock[0], f.HeaderBlock[0] = open, header
+	return f
+}
+
+func TestCompareFrontiers(t *testing.T) {
+	local := []*types.Frontier{testFrontier(1, 11), testFrontier(2, 12), testFrontier(3, 13), testFrontier(5, 15)}
+	remote := []*types.Frontier{testFrontier(2, 22), testFrontier(3, 23), testFrontier(4, 24), testFrontier(5, 15)}
+	has := testFrontier(0, 22).HeaderBlock
+	pulls, pushes := compareFrontiers(local, remote, func(hash types.Hash) bool {
+		return hash == has
+	})
+	if len(pulls) != 2 || len(pushes) != 2 {
+		t.Fatal("invalid bulks", len(pulls), len(pushes))
+	}
+	// account 1 is only in the local ledger, account 2 is behind on the remote peer
+	if !pushes[0].StartHash.IsZero() || pushes[0].EndHash != local[0].HeaderBlock {
+		t.Fatal("invalid push of account 1", pushes[0])
+	}
+	if pushes[1].StartHash != remote[0].HeaderBlock || pushes[1].EndHash != local[1].HeaderBlock {
+		t.Fatal("invalid push of account 2", pushes[1])
+	}
+	// account 3 is ahead on the remote peer, account 4 is only in the remote ledger
+	if pulls[0].StartHash != local[2].HeaderBlock || pulls[0].EndHash != remote[1].HeaderBlock {
+		t.Fatal("invalid pull of account 3", pulls[0])
+	}
+	if !pulls[1].StartHash.IsZero() || pulls[1].EndHash != remote[2].HeaderBlock {
+		t.Fatal("invalid pull of account 4", pulls[1])
+	}
+}
+
+func TestNewSyncProgress(t *testing.T) {
+	p := newSyncProgress(syncRangeCount)
+	if len(p.Ranges) != syncRangeCount || p.Finished() {
+		t.Fatal("invalid ranges", len(p.Ranges))
+	}
+	if !p.Ranges[0].Start.IsZero() || !p.Ranges[syncRangeCount-1].End.IsZero() {
+		t.Fatal("ranges should cover the hash space")
+	}
+	for i := 1; i < syncRangeCount; i++ {
+		if p.Ranges[i].Start != p.Ranges[i-1].End {
+			t.Fatal("ranges should be continuous", i)
+		}
+	}
+}
+
+func TestServiceSync_Resume(t *testing.T) {
+	node1 := testPeerService(t, "/ip4/127.0.0.1/tcp/19752")
+	node2 := testPeerService(t, "/ip4/127.0.0.1/tcp/19753")
+	defer func() {
+		for _, n := range []*QlcService{node1, node2} {
+			if err := n.Stop(); err != nil {
+				t.Fatal(err)
+			}
+			if err := n.msgService.ledger.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := os.RemoveAll(config.QlcTestDataDir()); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	peerID2 := node2.node.ID.Pretty()
+	if err := node1.node.ConnectPeer("/ip4/127.0.0.1/tcp/19753/ipfs/" + peerID2); err != nil {
+		t.Fatal(err)
+	}
+	waitHandshake(t, node1.node.streamManager, peerID2)
+
+	// a round interrupted by a restart, range 1 was requested from a peer which is gone
+	l := node1.msgService.ledger
+	progress := newSyncProgress(syncRangeCount)
+	for _, r := range progress.Ranges[2:] {
+		r.Done = true
+	}
+	progress.Ranges[1].Peer = "QmdFSukPUMF3t1JxjvTo14SEEb5JV9JBT6PukGRo6A2g4f"
+	if err := l.SetSyncProgress(progress); err != nil {
+		t.Fatal(err)
+	}
+	ss := node1.msgService.syncService
+	ss.resume()
+
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for {
+		p, err := l.GetSyncProgress()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p == nil {
+			break
+		}
+		select {
+		case <-ticker.C:
+			t.Fatal("sync round is not finished", p.DoneCount())
+		default:
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if node1.node.streamManager.PeerScore(peerID2) != initialPeerScore+2 {
+		t.Fatal("peer which answered the ranges should be rewarded", node1.node.streamManager.PeerScore(peerID2))
+	}
+}
+
+func TestServiceSync_Pulled(t *testing.T) {
+	dir := filepath.Join(config.QlcTestDataDir(), "sync", uuid.New().String())
+	l := ledger.NewLedger(dir)
+	defer func() {
+		if err := l.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	ss := &ServiceSync{
+		qlcLedger: l,
+		logger:    log.NewLogger("sync_test"),
+		tasks:     make(map[string]*syncTask),
+		failed:    make(map[int]map[string]bool),
+		progress:  newSyncProgress(2),
+	}
+	ss.progress.Ranges[1].Done = true
+
+	// a range fails when the peer does not send the pulled blocks
+	peerID := "QmdFSukPUMF3t1JxjvTo14SEEb5JV9JBT6PukGRo6A2g4f"
+	h1, h2 := mock.Hash(), mock.Hash()
+	rng := ss.progress.Ranges[0]
+	task := &syncTask{rng: rng, pulls: map[types.Hash]bool{h1: true}}
+	ss.tasks[peerID] = task
+	ss.failTask(peerID, task)
+	ss.pulled(peerID, h1)
+	if rng.Done || rng.Retries != 1 || !ss.failed[rng.Index][peerID] {
+		t.Fatal("failed range should be retried", rng)
+	}
+
+	// the range is done when the last blocks of all the pulled chains are received
+	task = &syncTask{rng: rng, pulls: map[types.Hash]bool{h1: true, h2: true}}
+	ss.tasks[peerID] = task
+	ss.pulled("QmPeerWithoutTask", h1)
+	ss.pulled(peerID, mock.Hash())
+	ss.pulled(peerID, h1)
+	if rng.Done || len(task.pulls) != 1 {
+		t.Fatal("range should wait for the pulled blocks", len(task.pulls))
+	}
+	ss.pulled(peerID, h2)
+	if !rng.Done || ss.progress != nil || len(ss.tasks) != 0 {
+		t.Fatal("range and round should be done")
+	}
+}
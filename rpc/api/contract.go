@@ -11,23 +11,317 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
 	"strings"
 
+	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/event"
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/ledger"
+	"github.com/qlcchain/go-qlc/ledger/process"
+	"github.com/qlcchain/go-qlc/ledger/relation"
 	"github.com/qlcchain/go-qlc/log"
+	"github.com/qlcchain/go-qlc/p2p"
 	"github.com/qlcchain/go-qlc/vm/abi"
+	"github.com/qlcchain/go-qlc/vm/contract"
+	"github.com/qlcchain/go-qlc/vm/engine"
+	"github.com/qlcchain/go-qlc/vm/vmstore"
 	"go.uber.org/zap"
 )
 
 type ContractApi struct {
-	logger *zap.SugaredLogger
-	ledger *ledger.Ledger
+	logger   *zap.SugaredLogger
+	ledger   *ledger.Ledger
+	relation *relation.Relation
+	verifier *process.LedgerVerifier
+	eb       event.EventBus
 }
 
-func NewContractApi(ledger *ledger.Ledger) *ContractApi {
-	return &ContractApi{logger: log.NewLogger("api_contract"), ledger: ledger}
+func NewContractApi(ledger *ledger.Ledger, relation *relation.Relation, eb event.EventBus) *ContractApi {
+	return &ContractApi{logger: log.NewLogger("api_contract"), ledger: ledger, relation: relation,
+		verifier: process.NewLedgerVerifier(ledger), eb: eb}
+}
+
+type APIContractDeployPara struct {
+	Owner        types.Address `json:"owner"`
+	Account      types.Address `json:"account"`
+	PrevHash     types.Hash    `json:"prevHash"`
+	Code         string        `json:"code"`
+	AbiSchema    string        `json:"abiSchema"`
+	IsUseStorage bool          `json:"isUseStorage"`
+}
+
+// GetDeployBlock creates the smart contract block for the wasm code(hex encoded), the address of the contract is
+// derived from the owner, the previous block hash of the owner and the code. The block is signed by the account,
+// it is the owner if not set, then the work is set and the block is deployed by Deploy
+func (c *ContractApi) GetDeployBlock(para *APIContractDeployPara) (*types.SmartContractBlock, error) {
+	if para == nil || para.Owner.IsZero() || para.Code == "" || para.AbiSchema == "" {
+		return nil, errors.New("invalid deploy parameter")
+	}
+	code, err := hex.DecodeString(para.Code)
+	if err != nil {
+		return nil, err
+	}
+	codeHash, err := types.HashBytes(code)
+	if err != nil {
+		return nil, err
+	}
+	addrHash, err := types.HashBytes(para.Owner[:], para.PrevHash[:], codeHash[:])
+	if err != nil {
+		return nil, err
+	}
+	account := para.Account
+	if account.IsZero() {
+		account = para.Owner
+	}
+
+	return &types.SmartContractBlock{
+		Address:         types.Address(addrHash),
+		InternalAccount: account,
+		Owner:           para.Owner,
+		Abi:             types.ContractAbi{Abi: code, AbiLength: uint64(len(code)), AbiHash: codeHash},
+		AbiSchema:       para.AbiSchema,
+		IsUseStorage:    para.IsUseStorage,
+	}, nil
+}
+
+// Deploy verifies and stores the signed smart contract block created by GetDeployBlock and publishes it to the
+// network, the address of the new contract is returned
+func (c *ContractApi) Deploy(block *types.SmartContractBlock) (types.Address, error) {
+	if block == nil {
+		return types.ZeroAddress, errors.New("invalid smart contract block")
+	}
+	r, err := c.verifier.Process(block)
+	if err != nil {
+		return types.ZeroAddress, err
+	}
+	if r != process.Progress {
+		return types.ZeroAddress, fmt.Errorf("deploy contract error: %s", r.String())
+	}
+	c.eb.Publish(string(common.EventBroadcast), p2p.PublishReq, block)
+	return block.Address, nil
+}
+
+// Call runs the contract method encoded in data(see PackContractData) against the contract of address,
+// nothing is written to the ledger, send a ContractSend block to the contract address to invoke it on chain
+func (c *ContractApi) Call(address types.Address, data []byte) ([]byte, error) {
+	sc, err := c.ledger.GetSmartContractBlockByAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	e, err := engine.NewExecuteEngine(types.ZeroAddress, sc, c.ledger)
+	if err != nil {
+		return nil, err
+	}
+	return e.Call(data)
+}
+
+type APIContractBlock struct {
+	Block     *types.StateBlock `json:"block"`
+	ToAddress types.Address     `json:"toAddress"`
+	BlockType types.BlockType   `json:"blockType"`
+	Amount    types.Balance     `json:"amount"`
+	Token     types.Hash        `json:"token"`
+	Data      []byte            `json:"data"`
+}
+
+type APIContractSimulation struct {
+	Send    *types.StateBlock      `json:"send"`
+	Reward  *types.StateBlock      `json:"reward"`
+	Fee     types.Balance          `json:"fee"`
+	Blocks  []*APIContractBlock    `json:"blocks"`
+	Storage []*vmstore.StorageDiff `json:"storage"`
+	Error   string                 `json:"error,omitempty"`
+}
+
+// Simulate runs the chain contract called by the ContractSend block against a throwaway vm context, nothing is
+// saved. The send block with the data rewritten by the contract, the fee, the unsigned ContractReward block built
+// as GetRewardBlock of the contract builds it, the blocks generated on receive and the storage changes are returned, the contract error is reported in the Error field of the result.
+func (c *ContractApi) Simulate(block *types.StateBlock) (*APIContractSimulation, error) {
+	if block == nil || block.GetType() != types.ContractSend {
+		return nil, errors.New("invalid contract send block")
+	}
+	address := types.Address(block.GetLink())
+	ca, ok, err := contract.GetChainContract(address, block.GetData())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("can not find chain contract %s", address.String())
+	}
+
+	ctx := vmstore.NewVMContext(c.ledger)
+	send := block.Clone()
+	result := &APIContractSimulation{Send: send, Fee: types.ZeroBalance, Blocks: make([]*APIContractBlock, 0)}
+	if result.Fee, err = ca.GetFee(ctx, send); err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	if err := ca.DoSend(ctx, send); err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	reward := &types.StateBlock{}
+	blocks, err := ca.DoReceive(ctx, reward, send)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	if len(blocks) > 0 {
+		reward.Timestamp = common.TimeNow().UTC().Unix()
+		reward.Extra = blocks[0].VMContext.ContractRoot(address)
+		result.Reward = reward
+	}
+	for _, b := range blocks {
+		result.Blocks = append(result.Blocks, &APIContractBlock{
+			Block:     b.Block,
+			ToAddress: b.ToAddress,
+			BlockType: b.BlockType,
+			Amount:    b.Amount,
+			Token:     b.Token,
+			Data:      b.Data,
+		})
+	}
+	if result.Storage, err = ctx.StorageDiffs(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetRefundBlock returns the unsigned ContractRefund block which returns the amount of the contract send to its
+// sender, it is only generated if the chain contract can not receive the send. The block is signed by the sender.
+func (c *ContractApi) GetRefundBlock(send *types.StateBlock) (*types.StateBlock, error) {
+	if send == nil || send.GetType() != types.ContractSend {
+		return nil, errors.New("invalid contract send block")
+	}
+	hash := send.GetHash()
+	if b, err := c.ledger.HasStateBlock(hash); err != nil {
+		return nil, err
+	} else if !b {
+		return nil, fmt.Errorf("send block(%s) does not exist", hash.String())
+	}
+	if _, err := c.ledger.GetRefund(hash); err == nil {
+		return nil, fmt.Errorf("send block(%s) is already refunded", hash.String())
+	} else if err != ledger.ErrRefundNotFound {
+		return nil, err
+	}
+	if _, err := c.ledger.GetReward(hash); err == nil {
+		return nil, fmt.Errorf("send block(%s) is already received", hash.String())
+	} else if err != ledger.ErrRewardNotFound {
+		return nil, err
+	}
+	address := types.Address(send.GetLink())
+	ca, ok, err := contract.GetChainContract(address, send.GetData())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("can not find chain contract %s", address.String())
+	}
+	if _, err := ca.DoReceive(vmstore.NewVMContext(c.ledger), &types.StateBlock{}, send); err == nil {
+		return nil, fmt.Errorf("send block(%s) can be received by the contract", hash.String())
+	} else if contract.IsNEP5TxUnverified(err) {
+		return nil, err
+	}
+
+	refund := &types.StateBlock{}
+	if _, err := contract.Refund(vmstore.NewVMContext(c.ledger), ca, refund, send); err != nil {
+		return nil, err
+	}
+	refund.Timestamp = common.TimeNow().UTC().Unix()
+	return refund, nil
+}
+
+// APIContractLogFilter selects contract logs, Topics are matched by position and a zero topic matches any topic,
+// a zero Address selects the logs of all contracts, a zero FromTime or ToTime is not a bound of the log timestamp
+type APIContractLogFilter struct {
+	Address  types.Address `json:"address"`
+	Topics   []types.Hash  `json:"topics"`
+	FromTime int64         `json:"fromTime"`
+	ToTime   int64         `json:"toTime"`
+}
+
+func (f *APIContractLogFilter) match(address types.Address, vmLog *types.VmLog, timestamp int64) bool {
+	if !f.Address.IsZero() && f.Address != address {
+		return false
+	}
+	if (f.FromTime > 0 && timestamp < f.FromTime) || (f.ToTime > 0 && timestamp > f.ToTime) {
+		return false
+	}
+	if len(f.Topics) > len(vmLog.Topics) {
+		return false
+	}
+	for i, topic := range f.Topics {
+		if !topic.IsZero() && topic != vmLog.Topics[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// APIContractLog is the Index-th log emitted by the contract of Address when the block of Hash is generated
+type APIContractLog struct {
+	Address   types.Address `json:"address"`
+	Hash      types.Hash    `json:"hash"`
+	Index     int           `json:"index"`
+	Timestamp int64         `json:"timestamp"`
+	Topics    []types.Hash  `json:"topics"`
+	Data      []byte        `json:"data"`
+}
+
+const (
+	defaultLogCount = 100
+	maxLogCount     = 1000
+)
+
+// GetLogs returns the contract logs selected by filter in the order they are added, at most defaultLogCount logs
+// are returned if count is not set and count can not exceed maxLogCount, the log data can be decoded by the event
+// of the contract abi
+func (c *ContractApi) GetLogs(filter *APIContractLogFilter, count *int, offset *int) ([]*APIContractLog, error) {
+	if filter == nil {
+		return nil, errors.New("invalid log filter")
+	}
+	n := defaultLogCount
+	if count != nil {
+		n = *count
+	}
+	limit, o, err := checkOffset(n, offset)
+	if err != nil {
+		return nil, err
+	}
+	if limit > maxLogCount {
+		return nil, fmt.Errorf("count can not exceed %d", maxLogCount)
+	}
+	indexes, err := c.relation.ContractLogs(filter.Address, filter.Topics, filter.FromTime, filter.ToTime, limit, o)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*APIContractLog, 0, len(indexes))
+	cache := make(map[types.Hash]*types.VmLogs)
+	for _, index := range indexes {
+		logs, ok := cache[index.Hash]
+		if !ok {
+			if logs, err = c.ledger.GetVmLogs(index.Hash); err != nil {
+				return nil, fmt.Errorf("get logs of %s: %s", index.Hash.String(), err)
+			}
+			cache[index.Hash] = logs
+		}
+		if index.Index >= len(logs.Logs) {
+			return nil, fmt.Errorf("log %d of %s does not exist", index.Index, index.Hash.String())
+		}
+		vmLog := logs.Logs[index.Index]
+		result = append(result, &APIContractLog{
+			Address:   index.Address,
+			Hash:      index.Hash,
+			Index:     index.Index,
+			Timestamp: index.Timestamp,
+			Topics:    vmLog.Topics,
+			Data:      vmLog.Data,
+		})
+	}
+	return result, nil
 }
 
 func (c *ContractApi) PackContractData(abiStr string, methodName string, params []string) ([]byte, error) {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 
 	"github.com/qlcchain/go-qlc/common"
 	"github.com/qlcchain/go-qlc/common/event"
@@ -14,6 +15,8 @@ import (
 	"github.com/qlcchain/go-qlc/ledger/relation"
 	"github.com/qlcchain/go-qlc/log"
 	"github.com/qlcchain/go-qlc/p2p"
+	"github.com/qlcchain/go-qlc/trie"
+	"github.com/qlcchain/go-qlc/vm/contract"
 	"github.com/qlcchain/go-qlc/vm/contract/abi"
 	"github.com/qlcchain/go-qlc/vm/vmstore"
 	"go.uber.org/zap"
@@ -143,6 +146,51 @@ func (l *LedgerApi) AccountHistoryTopn(address types.Address, count int, offset
 	return bs, nil
 }
 
+// APIBlockPage is a page of blocks, Cursor gets the next page and it is empty at the last page
+type APIBlockPage struct {
+	Blocks []*APIBlock `json:"blocks"`
+	Cursor string      `json:"cursor,omitempty"`
+}
+
+// AccountHistory returns up to count blocks of the account before the cursor, the newest first, an empty cursor
+// starts from the newest block. Unlike the offset of AccountHistoryTopn the cursor is not shifted by new blocks.
+func (l *LedgerApi) AccountHistory(address types.Address, count int, cursor string) (*APIBlockPage, error) {
+	if count < 1 {
+		return nil, errors.New("err count")
+	}
+	var c int64
+	if len(cursor) > 0 {
+		var err error
+		if c, err = strconv.ParseInt(cursor, 10, 64); err != nil || c <= 0 {
+			return nil, errors.New("err cursor")
+		}
+	}
+	hashes, next, err := l.relation.AccountBlocksBefore(address, c, count)
+	if err != nil {
+		l.logger.Error(err)
+		return nil, err
+	}
+	page := &APIBlockPage{Blocks: make([]*APIBlock, 0)}
+	if next > 0 {
+		page.Cursor = strconv.FormatInt(next, 10)
+	}
+	for _, h := range hashes {
+		block, err := l.ledger.GetStateBlock(h)
+		if err != nil {
+			if err == ledger.ErrBlockNotFound {
+				continue
+			}
+			return nil, err
+		}
+		b, err := generateAPIBlock(l.vmContext, block)
+		if err != nil {
+			return nil, err
+		}
+		page.Blocks = append(page.Blocks, b)
+	}
+	return page, nil
+}
+
 func (l *LedgerApi) AccountInfo(address types.Address) (*APIAccount, error) {
 	aa := new(APIAccount)
 	am, err := l.ledger.GetAccountMeta(address)
@@ -335,6 +383,14 @@ func (l *LedgerApi) Accounts(count int, offset *int) ([]*types.Address, error) {
 	return as, nil
 }
 
+// ActiveElections returns the elections which are waiting for enough representative votes, they are served from
+// the memory of the consensus
+func (l *LedgerApi) ActiveElections() ([]*types.ElectionInfo, error) {
+	infos := make([]*types.ElectionInfo, 0)
+	l.eb.Publish(string(common.EventActiveElections), &infos)
+	return infos, nil
+}
+
 func (l *LedgerApi) BlockAccount(hash types.Hash) (types.Address, error) {
 	sb, err := l.ledger.GetStateBlock(hash)
 	if err != nil {
@@ -511,6 +567,11 @@ func (l *LedgerApi) DelegatorsCount(hash types.Address) (int64, error) {
 	return count, nil
 }
 
+// ElectionInfo returns the result of the election which confirmed or rolled back the block
+func (l *LedgerApi) ElectionInfo(hash types.Hash) (*types.ElectionInfo, error) {
+	return l.ledger.GetElectionInfo(hash)
+}
+
 type APISendBlockPara struct {
 	From      types.Address `json:"from"`
 	TokenName string        `json:"tokenName"`
@@ -522,13 +583,26 @@ type APISendBlockPara struct {
 }
 
 func (l *LedgerApi) GenerateSendBlock(para *APISendBlockPara, prkStr string) (*types.StateBlock, error) {
-	if para.Amount.Int == nil || para.From.IsZero() || para.To.IsZero() || para.TokenName == "" {
-		return nil, errors.New("invalid transaction parameter")
+	sb, err := l.sendBlock(para)
+	if err != nil {
+		return nil, err
 	}
 	prk, err := hex.DecodeString(prkStr)
 	if err != nil {
 		return nil, err
 	}
+	block, err := l.ledger.GenerateSendBlock(sb, para.Amount, prk)
+	if err != nil {
+		return nil, err
+	}
+	l.logger.Debug(block)
+	return block, nil
+}
+
+func (l *LedgerApi) sendBlock(para *APISendBlockPara) (*types.StateBlock, error) {
+	if para.Amount.Int == nil || para.From.IsZero() || para.To.IsZero() || para.TokenName == "" {
+		return nil, errors.New("invalid transaction parameter")
+	}
 	info, err := abi.GetTokenByName(l.vmContext, para.TokenName)
 	if err != nil {
 		return nil, err
@@ -542,20 +616,14 @@ func (l *LedgerApi) GenerateSendBlock(para *APISendBlockPara, prkStr string) (*t
 	if err != nil {
 		return nil, errors.New("error receiver")
 	}
-	sb := types.StateBlock{
+	return &types.StateBlock{
 		Address:  para.From,
 		Token:    info.TokenId,
 		Link:     para.To.ToHash(),
 		Sender:   s,
 		Receiver: r,
 		Message:  para.Message,
-	}
-	block, err := l.ledger.GenerateSendBlock(&sb, para.Amount, prk)
-	if err != nil {
-		return nil, err
-	}
-	l.logger.Debug(block)
-	return block, nil
+	}, nil
 }
 
 func (l *LedgerApi) GenerateReceiveBlock(sendBlock *types.StateBlock, prkStr string) (*types.StateBlock, error) {
@@ -587,6 +655,48 @@ func (l *LedgerApi) GenerateChangeBlock(account types.Address, representative ty
 	return block, nil
 }
 
+// APIUnsignedBlock is a block without signature, Hash is what the owner of the block must sign
+type APIUnsignedBlock struct {
+	Block *types.StateBlock `json:"block"`
+	Hash  types.Hash        `json:"hash"`
+}
+
+func newAPIUnsignedBlock(block *types.StateBlock) *APIUnsignedBlock {
+	return &APIUnsignedBlock{Block: block, Hash: block.GetHash()}
+}
+
+// GenerateUnsignedSendBlock generates a send block without signature, it can be signed offline and
+// then be submitted by Process, so the private key never leaves the signer
+func (l *LedgerApi) GenerateUnsignedSendBlock(para *APISendBlockPara) (*APIUnsignedBlock, error) {
+	sb, err := l.sendBlock(para)
+	if err != nil {
+		return nil, err
+	}
+	block, err := l.ledger.GenerateUnsignedSendBlock(sb, para.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return newAPIUnsignedBlock(block), nil
+}
+
+// GenerateUnsignedReceiveBlock generates a receive block of the send block without signature
+func (l *LedgerApi) GenerateUnsignedReceiveBlock(sendBlock *types.StateBlock) (*APIUnsignedBlock, error) {
+	block, err := l.ledger.GenerateUnsignedReceiveBlock(sendBlock)
+	if err != nil {
+		return nil, err
+	}
+	return newAPIUnsignedBlock(block), nil
+}
+
+// GenerateUnsignedChangeBlock generates a change block without signature
+func (l *LedgerApi) GenerateUnsignedChangeBlock(account types.Address, representative types.Address) (*APIUnsignedBlock, error) {
+	block, err := l.ledger.GenerateUnsignedChangeBlock(account, representative)
+	if err != nil {
+		return nil, err
+	}
+	return newAPIUnsignedBlock(block), nil
+}
+
 func (l *LedgerApi) Pendings() ([]*APIPending, error) {
 	aps := make([]*APIPending, 0)
 	err := l.ledger.GetPendings(func(pendingKey *types.PendingKey, pendingInfo *types.PendingInfo) error {
@@ -742,3 +852,69 @@ func (l *LedgerApi) TokenInfoByName(tokenName string) (*ApiTokenInfo, error) {
 	}
 	return &ApiTokenInfo{*token}, nil
 }
+
+// APIStorageProof is the proof of a storage key of a contract, the trie key is the key of the value in the storage
+// trie and the value is empty if the key is not in the storage
+type APIStorageProof struct {
+	Key     string   `json:"key"`
+	TrieKey string   `json:"trieKey"`
+	Value   string   `json:"value"`
+	Proof   []string `json:"proof"`
+}
+
+// APIProof is the proof of the storage of a contract, the root is committed in the Extra of the latest
+// ContractReward block of the contract
+type APIProof struct {
+	Address      types.Address      `json:"address"`
+	Root         types.Hash         `json:"root"`
+	StorageProof []*APIStorageProof `json:"storageProof"`
+}
+
+// GetProof returns the proof of the storage keys of the contract against the root of its storage trie, which is the
+// store the contract storage is read from, the keys and the proof nodes are hex encoded, a proof is verified by
+// trie.VerifyProof with the root and the trie key. The state of a user account is not in a trie, it is the header
+// block of its token chain signed by the account, so there is no proof of the account state.
+func (l *LedgerApi) GetProof(address types.Address, storageKeys []string) (*APIProof, error) {
+	if !contract.IsChainContract(address) {
+		return nil, fmt.Errorf("%s is not a contract, only the contract storage can be proved", address.String())
+	}
+	root, err := l.ledger.GetContractRoot(address)
+	if err != nil {
+		return nil, fmt.Errorf("get root of contract %s: %s", address.String(), err)
+	}
+
+	vmContext := vmstore.NewVMContext(l.ledger)
+	if r := vmContext.ContractRoot(address); r != root {
+		return nil, fmt.Errorf("storage trie of contract %s is at %s, expect %s", address.String(), r.String(), root.String())
+	}
+	ap := &APIProof{
+		Address:      address,
+		Root:         root,
+		StorageProof: make([]*APIStorageProof, 0),
+	}
+	for _, k := range storageKeys {
+		key, err := hex.DecodeString(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid storage key %s: %s", k, err)
+		}
+		trieKey, proof, err := vmContext.StorageProof(address, key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := trie.VerifyProof(ap.Root, trieKey, proof)
+		if err != nil {
+			return nil, err
+		}
+		sp := &APIStorageProof{
+			Key:     k,
+			TrieKey: hex.EncodeToString(trieKey),
+			Value:   hex.EncodeToString(value),
+			Proof:   make([]string, 0),
+		}
+		for _, p := range proof {
+			sp.Proof = append(sp.Proof, hex.EncodeToString(p))
+		}
+		ap.StorageProof = append(ap.StorageProof, sp)
+	}
+	return ap, nil
+}
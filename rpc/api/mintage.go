@@ -81,6 +81,9 @@ func (m *MintageApi) GetMintageBlock(param *MintageParams) (*types.StateBlock, e
 	if tm.Balance.Compare(minPledgeAmount) == types.BalanceCompSmaller {
 		return nil, fmt.Errorf("not enough balance %s, expect %s", tm.Balance, minPledgeAmount)
 	}
+	if err := contract.VerifyNEP5Tx(param.NEP5TxId, minPledgeAmount.Int, param.Beneficial); err != nil {
+		return nil, err
+	}
 	send := &types.StateBlock{
 		Type:           types.ContractSend,
 		Token:          tm.Type,
@@ -111,11 +114,7 @@ func (m *MintageApi) GetRewardBlock(input *types.StateBlock) (*types.StateBlock,
 	}
 	if len(blocks) > 0 {
 		reward.Timestamp = common.TimeNow().UTC().Unix()
-		h := blocks[0].VMContext.Cache.Trie().Hash()
-		if h == nil {
-			return nil, errors.New("trie hash is nil")
-		}
-		reward.Extra = *h
+		reward.Extra = blocks[0].VMContext.ContractRoot(types.MintageAddress)
 		return reward, nil
 	}
 
@@ -175,8 +174,7 @@ func (m *MintageApi) GetWithdrawRewardBlock(input *types.StateBlock) (*types.Sta
 
 	if len(blocks) > 0 {
 		reward.Timestamp = common.TimeNow().UTC().Unix()
-		h := blocks[0].VMContext.Cache.Trie().Hash()
-		reward.Extra = *h
+		reward.Extra = blocks[0].VMContext.ContractRoot(types.MintageAddress)
 		return reward, nil
 	}
 
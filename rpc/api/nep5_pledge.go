@@ -85,6 +85,9 @@ func (p *NEP5PledgeApi) GetPledgeBlock(param *PledgeParam) (*types.StateBlock, e
 	if err != nil {
 		return nil, err
 	}
+	if err := contract.VerifyNEP5Tx(param.NEP5TxId, param.Amount.Int, param.Beneficial); err != nil {
+		return nil, err
+	}
 
 	send := &types.StateBlock{
 		Type:           types.ContractSend,
@@ -112,15 +115,14 @@ func (p *NEP5PledgeApi) GetPledgeBlock(param *PledgeParam) (*types.StateBlock, e
 
 func (p *NEP5PledgeApi) GetPledgeRewardBlock(input *types.StateBlock) (*types.StateBlock, error) {
 	reward := &types.StateBlock{}
-
-	blocks, err := p.pledge.DoReceive(p.vmContext, reward, input)
+	vmContext := vmstore.NewVMContext(p.ledger)
+	blocks, err := p.pledge.DoReceive(vmContext, reward, input)
 	if err != nil {
 		return nil, err
 	}
 	if len(blocks) > 0 {
 		reward.Timestamp = common.TimeNow().UTC().Unix()
-		h := blocks[0].VMContext.Cache.Trie().Hash()
-		reward.Extra = *h
+		reward.Extra = blocks[0].VMContext.ContractRoot(types.NEP5PledgeAddress)
 		return reward, nil
 	}
 
@@ -212,15 +214,14 @@ func (p *NEP5PledgeApi) GetWithdrawPledgeBlock(param *WithdrawPledgeParam) (*typ
 
 func (p *NEP5PledgeApi) GetWithdrawRewardBlock(input *types.StateBlock) (*types.StateBlock, error) {
 	reward := &types.StateBlock{}
-
-	blocks, err := p.withdraw.DoReceive(p.vmContext, reward, input)
+	vmContext := vmstore.NewVMContext(p.ledger)
+	blocks, err := p.withdraw.DoReceive(vmContext, reward, input)
 	if err != nil {
 		return nil, err
 	}
 	if len(blocks) > 0 {
 		reward.Timestamp = common.TimeNow().UTC().Unix()
-		h := blocks[0].VMContext.Cache.Trie().Hash()
-		reward.Extra = *h
+		reward.Extra = blocks[0].VMContext.ContractRoot(types.NEP5PledgeAddress)
 		return reward, nil
 	}
 
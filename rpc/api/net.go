@@ -1,19 +1,26 @@
 package api
 
 import (
+	"errors"
+
+	peer "github.com/libp2p/go-libp2p-peer"
+	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/event"
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/ledger"
 	"github.com/qlcchain/go-qlc/log"
+	"github.com/qlcchain/go-qlc/p2p"
 	"go.uber.org/zap"
 )
 
 type NetApi struct {
 	ledger *ledger.Ledger
+	eb     event.EventBus
 	logger *zap.SugaredLogger
 }
 
-func NewNetApi(l *ledger.Ledger) *NetApi {
-	return &NetApi{ledger: l, logger: log.NewLogger("api_net")}
+func NewNetApi(l *ledger.Ledger, eb event.EventBus) *NetApi {
+	return &NetApi{ledger: l, eb: eb, logger: log.NewLogger("api_net")}
 }
 
 func (q *NetApi) OnlineRepresentatives() []types.Address {
@@ -23,3 +30,91 @@ func (q *NetApi) OnlineRepresentatives() []types.Address {
 	}
 	return as
 }
+
+var ErrP2PNotRunning = errors.New("p2p service is not running")
+
+// Peers returns the connected peers with the network id, genesis hash, p2p version and frontier count exchanged
+// by the handshake, the latency, the traffic and the connected time
+func (q *NetApi) Peers() []*p2p.PeerInfo {
+	peers := make([]*p2p.PeerInfo, 0)
+	q.eb.Publish(string(common.EventPeersInfo), &peers)
+	return peers
+}
+
+// DeliveryStats returns the delivery of the PublishReq, ConfirmReq and ConfirmAck messages to every peer, the
+// messages are resent until the peer answers or the resend limit is reached
+func (q *NetApi) DeliveryStats() []*p2p.DeliveryStats {
+	stats := make([]*p2p.DeliveryStats, 0)
+	q.eb.Publish(string(common.EventDeliveryStats), &stats)
+	return stats
+}
+
+// ConnectPeer connects to a peer by the multiaddr like /ip4/127.0.0.1/tcp/9734/ipfs/QmdFSukPUMF3t1JxjvTo14SEEb5JV9JBT6PukGRo6A2g4f
+func (q *NetApi) ConnectPeer(addr string) error {
+	return q.publish(common.EventConnectPeer, addr)
+}
+
+func (q *NetApi) DisconnectPeer(peerID string) error {
+	return q.publish(common.EventDisconnectPeer, peerID)
+}
+
+// BanPeer adds the peer to the ban list and disconnects it, the ban list is kept in the ledger and survives restarts
+func (q *NetApi) BanPeer(peerID string) error {
+	if _, err := peer.IDB58Decode(peerID); err != nil {
+		return err
+	}
+	if err := q.ledger.AddBannedPeer(peerID); err != nil {
+		return err
+	}
+	if err := q.publish(common.EventDisconnectPeer, peerID); err != nil && err != p2p.ErrPeerIsNotConnected &&
+		err != ErrP2PNotRunning {
+		return err
+	}
+	return nil
+}
+
+func (q *NetApi) UnbanPeer(peerID string) error {
+	return q.ledger.DeleteBannedPeer(peerID)
+}
+
+// BannedPeers returns the ban list, peer id to the unix time it was banned
+func (q *NetApi) BannedPeers() (map[string]int64, error) {
+	return q.ledger.GetBannedPeers()
+}
+
+// APISyncProgress is the progress of the sync round, Syncing is false if there is no unfinished round
+type APISyncProgress struct {
+	Syncing   bool               `json:"syncing"`
+	StartTime int64              `json:"startTime"`
+	Total     int                `json:"total"`
+	Done      int                `json:"done"`
+	Ranges    []*types.SyncRange `json:"ranges"`
+}
+
+// SyncProgress returns the progress of the sync round, the accounts are split into ranges by the open block and
+// the ranges are synced from the peers concurrently
+func (q *NetApi) SyncProgress() (*APISyncProgress, error) {
+	progress, err := q.ledger.GetSyncProgress()
+	if err != nil {
+		return nil, err
+	}
+	if progress == nil {
+		return &APISyncProgress{Ranges: make([]*types.SyncRange, 0)}, nil
+	}
+	return &APISyncProgress{
+		Syncing:   true,
+		StartTime: progress.StartTime,
+		Total:     len(progress.Ranges),
+		Done:      progress.DoneCount(),
+		Ranges:    progress.Ranges,
+	}, nil
+}
+
+func (q *NetApi) publish(topic common.TopicType, arg string) error {
+	if !q.eb.HasCallback(string(topic)) {
+		return ErrP2PNotRunning
+	}
+	var err error
+	q.eb.Publish(string(topic), arg, &err)
+	return err
+}
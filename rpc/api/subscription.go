@@ -0,0 +1,151 @@
+package api
+
+import (
+	"sync"
+
+	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/event"
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/ledger"
+	"github.com/qlcchain/go-qlc/log"
+	"github.com/qlcchain/go-qlc/vm/vmstore"
+	"go.uber.org/zap"
+)
+
+const blockSubscriptionBuffer = 1024
+
+// BlockFilter reports whether a block should be delivered to a subscriber
+type BlockFilter func(block *types.StateBlock) bool
+
+// BlockSubscription forwards blocks published on the event bus to subscribers
+type BlockSubscription struct {
+	ledger    *ledger.Ledger
+	vmContext *vmstore.VMContext
+	eb        event.EventBus
+	logger    *zap.SugaredLogger
+}
+
+func NewBlockSubscription(l *ledger.Ledger, eb event.EventBus) *BlockSubscription {
+	return &BlockSubscription{ledger: l, vmContext: vmstore.NewVMContext(l), eb: eb, logger: log.NewLogger("api_subscription")}
+}
+
+// Subscribe calls fn for every block published on topic that passes filter, a nil filter accepts all blocks.
+// Blocks are delivered in publish order from a separate goroutine, so a slow subscriber never blocks the publisher;
+// blocks are dropped when the subscriber falls too far behind. The returned function cancels the subscription.
+func (s *BlockSubscription) Subscribe(topic common.TopicType, filter BlockFilter, fn func(*APIBlock)) (func(), error) {
+	ch := make(chan *types.StateBlock, blockSubscriptionBuffer)
+	quit := make(chan struct{})
+
+	handler := func(block *types.StateBlock) {
+		if filter != nil && !filter(block) {
+			return
+		}
+		select {
+		case ch <- block:
+		default:
+			s.logger.Warnf("subscription of %s is full, drop block %s", topic, block.GetHash())
+		}
+	}
+	if err := s.eb.Subscribe(string(topic), handler); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			select {
+			case <-quit:
+				return
+			case block := <-ch:
+				b, err := generateAPIBlock(s.vmContext, block)
+				if err != nil {
+					s.logger.Error(err)
+					b = new(APIBlock).fromStateBlock(block)
+				}
+				fn(b)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if err := s.eb.Unsubscribe(string(topic), handler); err != nil {
+				s.logger.Error(err)
+			}
+			close(quit)
+		})
+	}, nil
+}
+
+// AccountFilter accepts blocks created by the address and sends to the address
+func AccountFilter(address types.Address) BlockFilter {
+	return func(block *types.StateBlock) bool {
+		if block.GetAddress() == address {
+			return true
+		}
+		return block.GetType() == types.Send && block.GetLink() == address.ToHash()
+	}
+}
+
+// LogSubscription forwards the contract logs published on the event bus to subscribers
+type LogSubscription struct {
+	eb     event.EventBus
+	logger *zap.SugaredLogger
+}
+
+func NewLogSubscription(eb event.EventBus) *LogSubscription {
+	return &LogSubscription{eb: eb, logger: log.NewLogger("api_log_subscription")}
+}
+
+// Subscribe calls fn for every contract log selected by filter when the block of the log is added to the ledger, a
+// nil filter accepts all logs. Logs are delivered like the blocks of BlockSubscription.Subscribe, the returned
+// function cancels the subscription.
+func (s *LogSubscription) Subscribe(filter *APIContractLogFilter, fn func(*APIContractLog)) (func(), error) {
+	ch := make(chan *APIContractLog, blockSubscriptionBuffer)
+	quit := make(chan struct{})
+
+	handler := func(address types.Address, block *types.StateBlock, logs *types.VmLogs) {
+		for i, vmLog := range logs.Logs {
+			if filter != nil && !filter.match(address, vmLog, block.GetTimestamp()) {
+				continue
+			}
+			l := &APIContractLog{
+				Address:   address,
+				Hash:      block.GetHash(),
+				Index:     i,
+				Timestamp: block.GetTimestamp(),
+				Topics:    vmLog.Topics,
+				Data:      vmLog.Data,
+			}
+			select {
+			case ch <- l:
+			default:
+				s.logger.Warnf("log subscription is full, drop log %d of %s", i, l.Hash)
+			}
+		}
+	}
+	if err := s.eb.Subscribe(string(common.EventAddVmLogs), handler); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			select {
+			case <-quit:
+				return
+			case l := <-ch:
+				fn(l)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if err := s.eb.Unsubscribe(string(common.EventAddVmLogs), handler); err != nil {
+				s.logger.Error(err)
+			}
+			close(quit)
+		})
+	}, nil
+}
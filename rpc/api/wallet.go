@@ -95,6 +95,85 @@ func (w *WalletApi) NewWallet(passphrase string, seed *string) (types.Address, e
 	return w.wallet.NewWalletBySeed(seedStr, passphrase)
 }
 
+// Export returns the keystore of the wallet, the seed in it is encrypted by the wallet passphrase
+func (w *WalletApi) Export(address types.Address, passphrase string) (string, error) {
+	session := w.wallet.NewSession(address)
+	b, err := session.VerifyPassword(passphrase)
+	if err != nil {
+		return "", err
+	}
+	if !b {
+		return "", errors.New("password is invalid")
+	}
+	ks, err := session.Keystore()
+	if err != nil {
+		return "", err
+	}
+	return ks.String(), nil
+}
+
+// Import creates wallet from the content of a keystore which is exported by Export
+func (w *WalletApi) Import(content string, passphrase string) (types.Address, error) {
+	return w.wallet.Import(content, passphrase)
+}
+
+func (w *WalletApi) session(address types.Address, passphrase string) (*wallet.Session, error) {
+	session := w.wallet.NewSession(address)
+	b, err := session.VerifyPassword(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if !b {
+		return nil, errors.New("password is invalid")
+	}
+	return session, nil
+}
+
+// Accounts returns all accounts derived from the wallet seed with their labels
+func (w *WalletApi) Accounts(address types.Address, passphrase string) ([]*wallet.AccountInfo, error) {
+	session, err := w.session(address, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return session.Accounts()
+}
+
+// NewAccount derives a new account from the wallet seed, label it is a optional parameter
+func (w *WalletApi) NewAccount(address types.Address, passphrase string, label *string) (types.Address, error) {
+	session, err := w.session(address, passphrase)
+	if err != nil {
+		return types.ZeroAddress, err
+	}
+	var l string
+	if label != nil {
+		l = *label
+	}
+	return session.NewAccount(l)
+}
+
+// Discover scans the ledger for used accounts of the wallet, gapLimit it is a optional parameter,
+// if not set, the gap limit in config is used
+func (w *WalletApi) Discover(address types.Address, passphrase string, gapLimit *uint32) ([]types.Address, error) {
+	session, err := w.session(address, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var limit uint32
+	if gapLimit != nil {
+		limit = *gapLimit
+	}
+	return session.Discover(limit)
+}
+
+// SetLabel labels an account of the wallet, an empty label removes the label
+func (w *WalletApi) SetLabel(address types.Address, passphrase string, account types.Address, label string) error {
+	session, err := w.session(address, passphrase)
+	if err != nil {
+		return err
+	}
+	return session.SetLabel(account, label)
+}
+
 func (w *WalletApi) List() ([]types.Address, error) {
 	addrs, err := w.wallet.WalletIds()
 	if err != nil {
@@ -4,87 +4,97 @@ import (
 	"github.com/qlcchain/go-qlc/rpc/api"
 )
 
-func (r *RPC) getApi(apiModule string) API {
+func (r *RPC) getApi(apiModule string) []API {
 	switch apiModule {
 	case "qlcclassic":
-		return API{
+		return []API{{
 			Namespace: "qlcclassic",
 			Version:   "1.0",
 			Service:   api.NewQlcApi(r.ledger, r.eb),
 			Public:    true,
-		}
+		}}
 	case "account":
-		return API{
+		return []API{{
 			Namespace: "account",
 			Version:   "1.0",
 			Service:   api.NewAccountApi(),
 			Public:    true,
-		}
+		}}
 	case "ledger":
-		return API{
+		return []API{{
 			Namespace: "ledger",
 			Version:   "1.0",
 			Service:   api.NewLedgerApi(r.ledger, r.relation, r.eb),
 			Public:    true,
-		}
+		}, {
+			Namespace: "ledger",
+			Version:   "1.0",
+			Service:   NewLedgerSubscription(r.ledger, r.eb),
+			Public:    true,
+		}}
 	case "net":
-		return API{
+		return []API{{
 			Namespace: "net",
 			Version:   "1.0",
-			Service:   api.NewNetApi(r.ledger),
+			Service:   api.NewNetApi(r.ledger, r.eb),
 			Public:    true,
-		}
+		}}
 	case "util":
-		return API{
+		return []API{{
 			Namespace: "util",
 			Version:   "1.0",
 			Service:   api.NewUtilApi(r.ledger),
 			Public:    true,
-		}
+		}}
 	case "wallet":
-		return API{
+		return []API{{
 			Namespace: "wallet",
 			Version:   "1.0",
 			Service:   api.NewWalletApi(r.ledger, r.wallet),
 			Public:    true,
-		}
+		}}
 	case "contract":
-		return API{
+		return []API{{
+			Namespace: "contract",
+			Version:   "1.0",
+			Service:   api.NewContractApi(r.ledger, r.relation, r.eb),
+			Public:    true,
+		}, {
 			Namespace: "contract",
 			Version:   "1.0",
-			Service:   api.NewContractApi(r.ledger),
+			Service:   NewContractSubscription(r.eb),
 			Public:    true,
-		}
+		}}
 	case "mintage":
-		return API{
+		return []API{{
 			Namespace: "mintage",
 			Version:   "1.0",
 			Service:   api.NewMintageApi(r.ledger),
 			Public:    true,
-		}
+		}}
 	case "pledge":
-		return API{
+		return []API{{
 			Namespace: "pledge",
 			Version:   "1.0",
 			Service:   api.NewNEP5PledgeApi(r.ledger),
 			Public:    true,
-		}
+		}}
 	case "sms":
-		return API{
+		return []API{{
 			Namespace: "sms",
 			Version:   "1.0",
 			Service:   api.NewSMSApi(r.ledger, r.relation),
 			Public:    true,
-		}
+		}}
 	default:
-		return API{}
+		return nil
 	}
 }
 
 func (r *RPC) GetApis(apiModule ...string) []API {
 	var apis []API
 	for _, m := range apiModule {
-		apis = append(apis, r.getApi(m))
+		apis = append(apis, r.getApi(m)...)
 	}
 	return apis
 }
@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/qlcchain/go-qlc/common/event"
+	"github.com/qlcchain/go-qlc/log"
+	"github.com/qlcchain/go-qlc/rpc/api"
+	"go.uber.org/zap"
+)
+
+// ContractSubscription exposes the contract pub/sub methods, it is registered under the contract namespace
+// together with api.ContractApi, so clients subscribe with contract_subscribe and unsubscribe with contract_unsubscribe
+type ContractSubscription struct {
+	logs   *api.LogSubscription
+	logger *zap.SugaredLogger
+}
+
+func NewContractSubscription(eb event.EventBus) *ContractSubscription {
+	return &ContractSubscription{logs: api.NewLogSubscription(eb), logger: log.NewLogger("rpc_contract_subscription")}
+}
+
+// Logs notifies every contract log selected by filter when the block of the log is added to the ledger
+func (s *ContractSubscription) Logs(ctx context.Context, filter *api.APIContractLogFilter) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return nil, ErrNotificationsUnsupported
+	}
+
+	subscription := notifier.CreateSubscription()
+	cancel, err := s.logs.Subscribe(filter, func(l *api.APIContractLog) {
+		if err := notifier.Notify(subscription.ID, l); err != nil {
+			s.logger.Debugf("notify %s error: %s", subscription.ID, err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer cancel()
+		select {
+		case <-subscription.Err():
+			s.logger.Debugf("subscription %s unsubscribed", subscription.ID)
+		case <-notifier.Closed():
+			s.logger.Debugf("subscription %s closed", subscription.ID)
+		}
+	}()
+	return subscription, nil
+}
@@ -0,0 +1,61 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/event"
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/rpc/api"
+	"github.com/qlcchain/go-qlc/test/mock"
+)
+
+func TestContractSubscription_Logs(t *testing.T) {
+	eb := event.New()
+	server := NewServer()
+	if err := server.RegisterName("contract", NewContractSubscription(eb)); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer func() {
+		client.Close()
+		server.Stop()
+	}()
+
+	address := mock.Address()
+	topic := mock.Hash()
+	ch := make(chan *api.APIContractLog, 10)
+	filter := &api.APIContractLogFilter{Address: address, Topics: []types.Hash{types.ZeroHash, topic}}
+	sub, err := client.Subscribe(context.Background(), "contract", ch, "logs", filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	time.Sleep(100 * time.Millisecond)
+
+	blk := mock.StateBlock()
+	logs := &types.VmLogs{Logs: []*types.VmLog{
+		{Topics: []types.Hash{mock.Hash(), mock.Hash()}},
+		{Topics: []types.Hash{mock.Hash(), topic}, Data: []byte{1}},
+	}}
+	eb.Publish(string(common.EventAddVmLogs), mock.Address(), mock.StateBlock(), logs)
+	eb.Publish(string(common.EventAddVmLogs), address, blk, logs)
+
+	select {
+	case l := <-ch:
+		if l.Hash != blk.GetHash() || l.Index != 1 || l.Address != address || l.Topics[1] != topic {
+			t.Fatalf("invalid log %v", l)
+		}
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+	select {
+	case l := <-ch:
+		t.Fatalf("unexpected log %v", l)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,67 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/event"
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/ledger"
+	"github.com/qlcchain/go-qlc/log"
+	"github.com/qlcchain/go-qlc/rpc/api"
+	"go.uber.org/zap"
+)
+
+// LedgerSubscription exposes the ledger pub/sub methods, it is registered under the ledger namespace
+// together with api.LedgerApi, so clients subscribe with ledger_subscribe and unsubscribe with ledger_unsubscribe
+type LedgerSubscription struct {
+	blocks *api.BlockSubscription
+	logger *zap.SugaredLogger
+}
+
+func NewLedgerSubscription(l *ledger.Ledger, eb event.EventBus) *LedgerSubscription {
+	return &LedgerSubscription{blocks: api.NewBlockSubscription(l, eb), logger: log.NewLogger("rpc_subscription")}
+}
+
+// NewBlock notifies every block added to the ledger
+func (s *LedgerSubscription) NewBlock(ctx context.Context) (*Subscription, error) {
+	return s.subscribe(ctx, common.EventAddRelation, nil)
+}
+
+// ConfirmedBlock notifies every block confirmed by consensus
+func (s *LedgerSubscription) ConfirmedBlock(ctx context.Context) (*Subscription, error) {
+	return s.subscribe(ctx, common.EventConfirmedBlock, nil)
+}
+
+// AccountBlocks notifies blocks added to the ledger which are created by the address or sent to the address
+func (s *LedgerSubscription) AccountBlocks(ctx context.Context, address types.Address) (*Subscription, error) {
+	return s.subscribe(ctx, common.EventAddRelation, api.AccountFilter(address))
+}
+
+func (s *LedgerSubscription) subscribe(ctx context.Context, topic common.TopicType, filter api.BlockFilter) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return nil, ErrNotificationsUnsupported
+	}
+
+	subscription := notifier.CreateSubscription()
+	cancel, err := s.blocks.Subscribe(topic, filter, func(block *api.APIBlock) {
+		if err := notifier.Notify(subscription.ID, block); err != nil {
+			s.logger.Debugf("notify %s error: %s", subscription.ID, err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer cancel()
+		select {
+		case <-subscription.Err():
+			s.logger.Debugf("subscription %s unsubscribed", subscription.ID)
+		case <-notifier.Closed():
+			s.logger.Debugf("subscription %s closed", subscription.ID)
+		}
+	}()
+	return subscription, nil
+}
@@ -0,0 +1,104 @@
+package rpc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/qlcchain/go-qlc/common"
+	"github.com/qlcchain/go-qlc/common/event"
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/config"
+	"github.com/qlcchain/go-qlc/ledger"
+	"github.com/qlcchain/go-qlc/rpc/api"
+	"github.com/qlcchain/go-qlc/test/mock"
+)
+
+func setupSubscriptionTestCase(t *testing.T) (func(t *testing.T), *Client, event.EventBus) {
+	dir := filepath.Join(config.QlcTestDataDir(), "subscription", uuid.New().String())
+	l := ledger.NewLedger(dir)
+	eb := event.New()
+
+	server := NewServer()
+	if err := server.RegisterName("ledger", NewLedgerSubscription(l, eb)); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+
+	return func(t *testing.T) {
+		client.Close()
+		server.Stop()
+		if err := l.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+	}, client, eb
+}
+
+func TestLedgerSubscription_NewBlock(t *testing.T) {
+	teardownTestCase, client, eb := setupSubscriptionTestCase(t)
+	defer teardownTestCase(t)
+
+	ch := make(chan *api.APIBlock, 10)
+	sub, err := client.Subscribe(context.Background(), "ledger", ch, "newBlock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	time.Sleep(100 * time.Millisecond)
+
+	blk := mock.StateBlock()
+	eb.Publish(string(common.EventAddRelation), blk)
+
+	select {
+	case b := <-ch:
+		if b.Hash != blk.GetHash() {
+			t.Fatalf("invalid block, exp: %s, act: %s", blk.GetHash(), b.Hash)
+		}
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+func TestLedgerSubscription_AccountBlocks(t *testing.T) {
+	teardownTestCase, client, eb := setupSubscriptionTestCase(t)
+	defer teardownTestCase(t)
+
+	blk := mock.StateBlock()
+	ch := make(chan *api.APIBlock, 10)
+	sub, err := client.Subscribe(context.Background(), "ledger", ch, "accountBlocks", blk.GetAddress())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	time.Sleep(100 * time.Millisecond)
+
+	eb.Publish(string(common.EventAddRelation), mock.StateBlock())
+	eb.Publish(string(common.EventConfirmedBlock), blk)
+	eb.Publish(string(common.EventAddRelation), blk)
+
+	send := mock.StateBlock()
+	send.Type = types.Send
+	send.Link = blk.Address.ToHash()
+	eb.Publish(string(common.EventAddRelation), send)
+
+	for _, exp := range []types.Hash{blk.GetHash(), send.GetHash()} {
+		select {
+		case b := <-ch:
+			if b.Hash != exp {
+				t.Fatalf("invalid block, exp: %s, act: %s", exp, b.Hash)
+			}
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+}
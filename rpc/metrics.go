@@ -0,0 +1,63 @@
+package rpc
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/qlcchain/go-qlc/common/metrics"
+)
+
+const metricsPath = "/metrics"
+
+var (
+	rpcRequests = metrics.NewCounterVec("qlc_rpc_requests_total",
+		"count of the rpc requests by method", "method")
+	rpcRequestErrors = metrics.NewCounterVec("qlc_rpc_request_errors_total",
+		"count of the rpc requests which returned an error by method", "method")
+	rpcRequestSeconds = metrics.NewHistogramVec("qlc_rpc_request_seconds",
+		"latency of the rpc requests by method", "method", metrics.DefBuckets)
+)
+
+// observeRequest records a call of the rpc method which started at start
+func observeRequest(method string, start time.Time, failed bool) {
+	rpcRequests.Inc(method)
+	rpcRequestSeconds.Observe(method, time.Since(start).Seconds())
+	if failed {
+		rpcRequestErrors.Inc(method)
+	}
+}
+
+// startMetrics serves the metrics of the node in the prometheus text format on endpoint
+func (r *RPC) startMetrics(endpoint string) error {
+	network, address, err := scheme(endpoint)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, metrics.Handler())
+	r.metricsListener = listener
+	r.metricsServer = &http.Server{
+		Handler:      mux,
+		ReadTimeout:  DefaultHTTPTimeouts.ReadTimeout,
+		WriteTimeout: DefaultHTTPTimeouts.WriteTimeout,
+		IdleTimeout:  DefaultHTTPTimeouts.IdleTimeout,
+	}
+	go r.metricsServer.Serve(listener)
+	r.logger.Info("metrics endpoint opened, ", "url:", listener.Addr())
+	return nil
+}
+
+// stopMetrics terminates the metrics endpoint
+func (r *RPC) stopMetrics() {
+	if r.metricsServer != nil {
+		_ = r.metricsServer.Close()
+		r.metricsServer = nil
+		r.metricsListener = nil
+		r.logger.Debug("metrics endpoint closed, ", "endpoint:", r.config.Metrics.Endpoint)
+	}
+}
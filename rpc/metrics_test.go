@@ -0,0 +1,45 @@
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qlcchain/go-qlc/common/metrics"
+)
+
+func TestRPC_Metrics(t *testing.T) {
+	server := NewServer()
+	if err := server.RegisterName("metrics", new(Service)); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	var result Result
+	for i := 0; i < 2; i++ {
+		if err := client.Call(&result, "metrics_echo", "s", 1, &Args{"a"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c := rpcRequests.Value("metrics_echo"); c != 2 {
+		t.Fatal("requests", c)
+	}
+	if c := rpcRequestErrors.Value("metrics_echo"); c != 0 {
+		t.Fatal("errors", c)
+	}
+	if c, _ := rpcRequestSeconds.With("metrics_echo").Count(); c != 2 {
+		t.Fatal("latency", c)
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, metricsPath, nil))
+	body := rec.Body.String()
+	for _, s := range []string{`qlc_rpc_requests_total{method="metrics_echo"} 2`,
+		`qlc_rpc_request_seconds_count{method="metrics_echo"} 2`} {
+		if !strings.Contains(body, s) {
+			t.Fatal(s, body)
+		}
+	}
+}
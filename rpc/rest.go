@@ -0,0 +1,389 @@
+package rpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/ledger"
+	"github.com/qlcchain/go-qlc/rpc/api"
+	"github.com/rs/cors"
+)
+
+const (
+	restDefaultLimit = 20
+	restMaxLimit     = 100
+	restOpenAPIPath  = "/openapi.json"
+)
+
+// restParam is a path or query parameter of a REST route
+type restParam struct {
+	Name        string
+	In          string
+	Type        string
+	Description string
+}
+
+// restRoute is a GET route of the REST gateway, Response is a value of the response type and only used to generate
+// the OpenAPI document
+type restRoute struct {
+	Path     string
+	Summary  string
+	Params   []restParam
+	Response interface{}
+	handle   func(params map[string]string) (interface{}, error)
+}
+
+// restError is an error with the HTTP status returned to the client
+type restError struct {
+	status int
+	msg    string
+}
+
+func (e *restError) Error() string {
+	return e.msg
+}
+
+func badRequest(format string, a ...interface{}) error {
+	return &restError{status: http.StatusBadRequest, msg: fmt.Sprintf(format, a...)}
+}
+
+// RESTGateway serves the block explorer REST api, it is backed by the same LedgerApi as the JSON-RPC
+type RESTGateway struct {
+	ledgerApi *api.LedgerApi
+	routes    []*restRoute
+}
+
+func NewRESTGateway(ledgerApi *api.LedgerApi) *RESTGateway {
+	g := &RESTGateway{ledgerApi: ledgerApi}
+	g.routes = []*restRoute{
+		{
+			Path:     "/blocks/{hash}",
+			Summary:  "block by hash",
+			Params:   []restParam{{Name: "hash", In: "path", Type: "string", Description: "block hash"}},
+			Response: api.APIBlock{},
+			handle:   g.block,
+		},
+		{
+			Path:     "/accounts/{address}",
+			Summary:  "account with the balances of its tokens",
+			Params:   []restParam{{Name: "address", In: "path", Type: "string", Description: "account address"}},
+			Response: api.APIAccount{},
+			handle:   g.account,
+		},
+		{
+			Path:    "/accounts/{address}/history",
+			Summary: "blocks of the account, the newest first",
+			Params: []restParam{
+				{Name: "address", In: "path", Type: "string", Description: "account address"},
+				{Name: "cursor", In: "query", Type: "string", Description: "cursor of the page returned by the previous page"},
+				{Name: "limit", In: "query", Type: "integer", Description: fmt.Sprintf("page size, default %d, max %d", restDefaultLimit, restMaxLimit)},
+			},
+			Response: api.APIBlockPage{},
+			handle:   g.accountHistory,
+		},
+		{
+			Path:     "/tokens",
+			Summary:  "all the tokens",
+			Response: []*types.TokenInfo{},
+			handle:   g.tokens,
+		},
+		{
+			Path:     "/representatives",
+			Summary:  "representatives sorted by voting weight",
+			Response: api.APIAccountBalances{},
+			handle:   g.representatives,
+		},
+	}
+	return g
+}
+
+func (g *RESTGateway) block(params map[string]string) (interface{}, error) {
+	hash, err := types.NewHash(params["hash"])
+	if err != nil {
+		return nil, badRequest("invalid hash %s", params["hash"])
+	}
+	blocks, err := g.ledgerApi.BlocksInfo([]types.Hash{hash})
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 {
+		return nil, ledger.ErrBlockNotFound
+	}
+	return blocks[0], nil
+}
+
+func (g *RESTGateway) account(params map[string]string) (interface{}, error) {
+	address, err := types.HexToAddress(params["address"])
+	if err != nil {
+		return nil, badRequest("invalid address %s", params["address"])
+	}
+	return g.ledgerApi.AccountInfo(address)
+}
+
+func (g *RESTGateway) accountHistory(params map[string]string) (interface{}, error) {
+	address, err := types.HexToAddress(params["address"])
+	if err != nil {
+		return nil, badRequest("invalid address %s", params["address"])
+	}
+	limit := restDefaultLimit
+	if s := params["limit"]; len(s) > 0 {
+		if limit, err = strconv.Atoi(s); err != nil || limit < 1 || limit > restMaxLimit {
+			return nil, badRequest("invalid limit %s", s)
+		}
+	}
+	if s := params["cursor"]; len(s) > 0 {
+		if c, err := strconv.ParseInt(s, 10, 64); err != nil || c <= 0 {
+			return nil, badRequest("invalid cursor %s", s)
+		}
+	}
+	return g.ledgerApi.AccountHistory(address, limit, params["cursor"])
+}
+
+func (g *RESTGateway) tokens(params map[string]string) (interface{}, error) {
+	return g.ledgerApi.Tokens()
+}
+
+func (g *RESTGateway) representatives(params map[string]string) (interface{}, error) {
+	sorting := true
+	return g.ledgerApi.Representatives(&sorting)
+}
+
+// match returns the path parameters if the path matches the route
+func (r *restRoute) match(path string) (map[string]string, bool) {
+	ps := strings.Split(strings.Trim(r.Path, "/"), "/")
+	ss := strings.Split(strings.Trim(path, "/"), "/")
+	if len(ps) != len(ss) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, p := range ps {
+		if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+			params[p[1:len(p)-1]] = ss[i]
+		} else if p != ss[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func (g *RESTGateway) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeREST(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	if req.URL.Path == restOpenAPIPath {
+		writeREST(w, http.StatusOK, g.OpenAPI())
+		return
+	}
+	for _, route := range g.routes {
+		params, ok := route.match(req.URL.Path)
+		if !ok {
+			continue
+		}
+		for k, v := range req.URL.Query() {
+			if _, ok := params[k]; !ok && len(v) > 0 {
+				params[k] = v[0]
+			}
+		}
+		result, err := route.handle(params)
+		if err != nil {
+			writeREST(w, restStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+		writeREST(w, http.StatusOK, result)
+		return
+	}
+	writeREST(w, http.StatusNotFound, map[string]string{"error": "not found"})
+}
+
+func restStatus(err error) int {
+	if e, ok := err.(*restError); ok {
+		return e.status
+	}
+	switch err {
+	case ledger.ErrBlockNotFound, ledger.ErrAccountNotFound, ledger.ErrTokenNotFound:
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func writeREST(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// OpenAPI generates the OpenAPI 3 document of the routes, the response schemas are built from the response types
+func (g *RESTGateway) OpenAPI() map[string]interface{} {
+	errorSchema := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"error": map[string]interface{}{"type": "string"}},
+	}
+	paths := make(map[string]interface{})
+	for _, route := range g.routes {
+		params := make([]interface{}, 0, len(route.Params))
+		for _, p := range route.Params {
+			params = append(params, map[string]interface{}{
+				"name":        p.Name,
+				"in":          p.In,
+				"required":    p.In == "path",
+				"description": p.Description,
+				"schema":      map[string]interface{}{"type": p.Type},
+			})
+		}
+		paths[route.Path] = map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary":    route.Summary,
+				"parameters": params,
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": route.Summary,
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{
+								"schema": jsonSchema(reflect.TypeOf(route.Response), make(map[reflect.Type]bool)),
+							},
+						},
+					},
+					"default": map[string]interface{}{
+						"description": "error",
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{"schema": errorSchema},
+						},
+					},
+				},
+			},
+		}
+	}
+	return map[string]interface{}{
+		"openapi": "3.0.0",
+		"info": map[string]interface{}{
+			"title":   "QLC Chain REST API",
+			"version": "1.0",
+		},
+		"paths": paths,
+	}
+}
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	bigIntType        = reflect.TypeOf(big.Int{})
+)
+
+// jsonSchema describes how the type is encoded by encoding/json, seen breaks the recursive types
+func jsonSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == bigIntType:
+		return map[string]interface{}{"type": "integer"}
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) ||
+		t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		return map[string]interface{}{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": jsonSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": jsonSchema(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		properties := make(map[string]interface{})
+		structProperties(t, seen, properties)
+		return map[string]interface{}{"type": "object", "properties": properties}
+	}
+	return map[string]interface{}{}
+}
+
+func structProperties(t reflect.Type, seen map[reflect.Type]bool, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		// the fields of an embedded struct are promoted
+		if f.Anonymous && len(name) == 0 && ft.Kind() == reflect.Struct {
+			structProperties(ft, seen, properties)
+			continue
+		}
+		if len(f.PkgPath) > 0 {
+			continue
+		}
+		if len(name) == 0 {
+			name = f.Name
+		}
+		properties[name] = jsonSchema(f.Type, seen)
+	}
+}
+
+// startREST starts the REST gateway on the endpoint
+func (r *RPC) startREST(endpoint string, allowedOrigins []string) error {
+	network, address, err := scheme(endpoint)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return err
+	}
+	var handler http.Handler = NewRESTGateway(api.NewLedgerApi(r.ledger, r.relation, r.eb))
+	if len(allowedOrigins) > 0 {
+		handler = cors.New(cors.Options{
+			AllowedOrigins: allowedOrigins,
+			AllowedMethods: []string{http.MethodGet},
+			MaxAge:         600,
+			AllowedHeaders: []string{"*"},
+		}).Handler(handler)
+	}
+	r.restListener = listener
+	r.restServer = &http.Server{
+		Handler:      handler,
+		ReadTimeout:  DefaultHTTPTimeouts.ReadTimeout,
+		WriteTimeout: DefaultHTTPTimeouts.WriteTimeout,
+		IdleTimeout:  DefaultHTTPTimeouts.IdleTimeout,
+	}
+	go r.restServer.Serve(listener)
+	r.logger.Info("REST endpoint opened, ", "url:", listener.Addr())
+	return nil
+}
+
+// stopREST terminates the REST gateway
+func (r *RPC) stopREST() {
+	if r.restServer != nil {
+		_ = r.restServer.Close()
+		r.restServer = nil
+		r.restListener = nil
+		r.logger.Debug("REST endpoint closed, ", "endpoint:", r.config.REST.Endpoint)
+	}
+}
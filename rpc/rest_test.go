@@ -0,0 +1,94 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/qlcchain/go-qlc/common/event"
+	"github.com/qlcchain/go-qlc/config"
+	"github.com/qlcchain/go-qlc/ledger"
+	"github.com/qlcchain/go-qlc/rpc/api"
+	"github.com/qlcchain/go-qlc/test/mock"
+)
+
+func TestRESTGateway(t *testing.T) {
+	dir := filepath.Join(config.QlcTestDataDir(), "rest", uuid.New().String())
+	l := ledger.NewLedger(dir)
+	defer func() {
+		_ = l.Close()
+		_ = os.RemoveAll(dir)
+	}()
+	server := httptest.NewServer(NewRESTGateway(api.NewLedgerApi(l, nil, event.GetEventBus(dir))))
+	defer server.Close()
+
+	get := func(method, path string, v interface{}) int {
+		req, err := http.NewRequest(method, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rsp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rsp.Body.Close()
+		if v != nil {
+			if err := json.NewDecoder(rsp.Body).Decode(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rsp.StatusCode
+	}
+
+	cases := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{http.MethodGet, "/blocks/123", http.StatusBadRequest},
+		{http.MethodGet, "/blocks/" + mock.Hash().String(), http.StatusNotFound},
+		{http.MethodGet, "/accounts/" + mock.Address().String(), http.StatusNotFound},
+		{http.MethodGet, "/accounts/" + mock.Address().String() + "/history?limit=0", http.StatusBadRequest},
+		{http.MethodGet, "/accounts/" + mock.Address().String() + "/history?cursor=abc", http.StatusBadRequest},
+		{http.MethodGet, "/representatives", http.StatusOK},
+		{http.MethodGet, "/unknown", http.StatusNotFound},
+		{http.MethodPost, "/representatives", http.StatusMethodNotAllowed},
+	}
+	for _, c := range cases {
+		if status := get(c.method, c.path, nil); status != c.status {
+			t.Fatal("invalid status", c.method, c.path, status)
+		}
+	}
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]struct {
+			Get struct {
+				Responses map[string]struct {
+					Content map[string]struct {
+						Schema struct {
+							Properties map[string]interface{} `json:"properties"`
+						} `json:"schema"`
+					} `json:"content"`
+				} `json:"responses"`
+			} `json:"get"`
+		} `json:"paths"`
+	}
+	if status := get(http.MethodGet, restOpenAPIPath, &doc); status != http.StatusOK {
+		t.Fatal("invalid status", status)
+	}
+	if doc.OpenAPI != "3.0.0" || len(doc.Paths) != 5 {
+		t.Fatal("invalid openapi document", doc)
+	}
+	// the fields of the embedded state block are promoted
+	properties := doc.Paths["/blocks/{hash}"].Get.Responses["200"].Content["application/json"].Schema.Properties
+	for _, name := range []string{"hash", "tokenName", "amount", "address", "balance", "previous"} {
+		if _, ok := properties[name]; !ok {
+			t.Fatal("property not found", name, properties)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package rpc
 import (
 	"errors"
 	"net"
+	"net/http"
 	"net/url"
 	"strings"
 	"sync"
@@ -32,6 +33,12 @@ type RPC struct {
 	wsListener net.Listener
 	wsHandler  *Server
 
+	restListener net.Listener
+	restServer   *http.Server
+
+	metricsListener net.Listener
+	metricsServer   *http.Server
+
 	wsCli              *WebSocketCli
 	config             *config.Config
 	DashboardTargetURL string
@@ -208,7 +215,8 @@ func (r *RPC) StopRPC() {
 	if r.config.RPC.Enable && r.config.RPC.WSEnabled {
 		r.stopWS()
 	}
-
+	r.stopREST()
+	r.stopMetrics()
 }
 
 func (r *RPC) StartRPC() error {
@@ -250,6 +258,29 @@ func (r *RPC) StartRPC() error {
 			return err
 		}
 	}
+
+	if r.config.RPC.Enable && r.config.REST != nil && r.config.REST.Enabled {
+		if err := r.startREST(r.config.REST.Endpoint, r.config.REST.Cors); err != nil {
+			r.logger.Info(err)
+			r.stopInProcess()
+			r.stopIPC()
+			r.stopHTTP()
+			r.stopWS()
+			return err
+		}
+	}
+
+	if r.config.Metrics != nil && r.config.Metrics.Enabled {
+		if err := r.startMetrics(r.config.Metrics.Endpoint); err != nil {
+			r.logger.Info(err)
+			r.stopInProcess()
+			r.stopIPC()
+			r.stopHTTP()
+			r.stopWS()
+			r.stopREST()
+			return err
+		}
+	}
 	//if len(r.config.DashboardTargetURL) > 0 {
 	//	apis := api.GetPublicApis()
 	//	if len(r.config.PublicModules) != 0 {
@@ -24,6 +24,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	mapset "github.com/deckarep/golang-set"
 	"github.com/qlcchain/go-qlc/log"
@@ -308,7 +309,10 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	}
 
 	// execute RPC method and return result
+	start := time.Now()
 	reply := req.callb.method.Func.Call(arguments)
+	method := req.svcname + serviceMethodSeparator + formatName(req.callb.method.Name)
+	observeRequest(method, start, req.callb.errPos >= 0 && !reply[req.callb.errPos].IsNil())
 	if len(reply) == 0 {
 		return codec.CreateResponse(req.id, nil), nil
 	}
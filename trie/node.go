@@ -10,6 +10,7 @@ package trie
 import (
 	"sort"
 	"sync"
+	"unicode/utf8"
 
 	"github.com/qlcchain/go-qlc/common/util"
 
@@ -212,7 +213,7 @@ func (t *TrieNode) serializeChildren(children map[byte]*TrieNode) map[string][]b
 
 	var parsedChildren = make(map[string][]byte, len(children))
 	for key, child := range children {
-		parsedChildren[string(key)] = child.Hash()[:]
+		parsedChildren[string([]byte{key})] = child.Hash()[:]
 	}
 	return parsedChildren
 }
@@ -240,6 +241,15 @@ func (t *TrieNode) Serialize() ([]byte, error) {
 	return tn.MarshalMsg(nil)
 }
 
+// childKey returns the key byte of a serialized child, a key above 0x7f used to be serialized in UTF-8
+func childKey(key string) byte {
+	if len(key) == 1 {
+		return key[0]
+	}
+	r, _ := utf8.DecodeRuneInString(key)
+	return byte(r)
+}
+
 func (t *TrieNode) parseChildren(children map[string][]byte) (map[byte]*TrieNode, error) {
 	var result = make(map[byte]*TrieNode)
 	for key, child := range children {
@@ -247,8 +257,7 @@ func (t *TrieNode) parseChildren(children map[string][]byte) (map[byte]*TrieNode
 		if err != nil {
 			return nil, err
 		}
-		tmp := []byte(key)
-		result[tmp[0]] = &TrieNode{
+		result[childKey(key)] = &TrieNode{
 			hash: &childHash,
 		}
 	}
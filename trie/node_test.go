@@ -22,7 +22,8 @@ func TestTrieNode_Deserialize(t *testing.T) {
 	node := &TrieNode{
 		nodeType: FullNode,
 		children: map[byte]*TrieNode{
-			byte(73): node1,
+			byte(73):  node1,
+			byte(229): node1,
 		},
 	}
 	bytes, err := node.Serialize()
@@ -39,6 +40,15 @@ func TestTrieNode_Deserialize(t *testing.T) {
 	if newNode.nodeType != node.nodeType {
 		t.Fatal("invalid type")
 	}
+	for key := range node.children {
+		if c, ok := newNode.children[key]; !ok || *c.Hash() != *node1.Hash() {
+			t.Fatal("invalid child", key)
+		}
+	}
+	// a key above 0x7f used to be serialized in UTF-8
+	if k := childKey(string(rune(229))); k != 229 {
+		t.Fatal("invalid utf8 child key", k)
+	}
 
 	//if !reflect.DeepEqual(newNode.children, node.children) {
 	//	t.Fatal("invalid children", "act ", util.ToIndentString(newNode.children),
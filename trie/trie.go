@@ -71,7 +71,7 @@ func (trie *Trie) getNodeFromDb(key *types.Hash) *TrieNode {
 	}
 }
 
-func (trie *Trie) saveNodeToDb(txn *db.BadgerStoreTxn, node *TrieNode) error {
+func (trie *Trie) saveNodeToDb(txn db.StoreTxn, node *TrieNode) error {
 	if data, err := node.Serialize(); err != nil {
 		return fmt.Errorf("serialize trie node failed, error is %s", err)
 	} else {
@@ -100,9 +100,9 @@ func (trie *Trie) deleteUnSavedRefValueMap(node *TrieNode) {
 	}
 }
 
-func (trie *Trie) saveRefValueMap(txn *db.BadgerStoreTxn) {
+func (trie *Trie) saveRefValueMap(txn db.StoreTxn) {
 	for key, value := range trie.unSavedRefValueMap {
-		err := txn.Set(key[:], value)
+		err := txn.Set(trie.encodeKey(key[:]), value)
 		if err != nil {
 			trie.log.Errorf("save %s, error %s", key.String(), err)
 		}
@@ -131,6 +131,7 @@ func (trie *Trie) getRefValue(key []byte) ([]byte, error) {
 	k := trie.encodeKey(key)
 	var result []byte
 	if err = txn.Get(k, func(i []byte, b byte) error {
+		result = make([]byte, len(i))
 		copy(result, i)
 		return nil
 	}); err == nil {
@@ -207,12 +208,18 @@ func (trie *Trie) Clone() *Trie {
 	return newTrie
 }
 
-func (trie *Trie) Save() (func(), error) {
-	txn := trie.db.NewTransaction(true)
-	defer func() {
-		txn.Commit(nil)
-		txn.Discard()
-	}()
+// Save saves the nodes of the trie, they are saved in txns if it is given, otherwise in a new transaction
+func (trie *Trie) Save(txns ...db.StoreTxn) (func(), error) {
+	var txn db.StoreTxn
+	if len(txns) > 0 {
+		txn = txns[0]
+	} else {
+		txn = trie.db.NewTransaction(true)
+		defer func() {
+			txn.Commit(nil)
+			txn.Discard()
+		}()
+	}
 
 	err := trie.traverseSave(txn, trie.Root)
 	if err != nil {
@@ -226,7 +233,7 @@ func (trie *Trie) Save() (func(), error) {
 	}, nil
 }
 
-func (trie *Trie) traverseSave(txn *db.BadgerStoreTxn, node *TrieNode) error {
+func (trie *Trie) traverseSave(txn db.StoreTxn, node *TrieNode) error {
 	if node == nil {
 		return nil
 	}
@@ -424,6 +431,19 @@ func (trie *Trie) GetValue(key []byte) []byte {
 	return trie.LeafNodeValue(leafNode)
 }
 
+// Lookup returns the value of key and whether key is in the trie, an empty value of a key in the trie is not nil
+func (trie *Trie) Lookup(key []byte) ([]byte, bool) {
+	leafNode := trie.getLeafNode(trie.Root, key)
+	if leafNode == nil {
+		return nil, false
+	}
+	value := trie.LeafNodeValue(leafNode)
+	if value == nil {
+		value = make([]byte, 0)
+	}
+	return value, true
+}
+
 func (trie *Trie) NewIterator(prefix []byte) *Iterator {
 	return NewIterator(trie, prefix)
 }
@@ -0,0 +1,135 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/qlcchain/go-qlc/common/types"
+)
+
+var ErrInvalidProof = errors.New("invalid trie proof")
+
+// Proof is the serialized nodes on the path from the root of the trie to a key in order, it is followed by the ref
+// value if the path ends at a hash node
+type Proof [][]byte
+
+// Prove returns the proof of the value of key, the proof of a missing key ends at the node where the path to the
+// key leaves the trie
+func (trie *Trie) Prove(key []byte) (Proof, error) {
+	var proof Proof
+	node := trie.Root
+	for node != nil {
+		data, err := node.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		proof = append(proof, data)
+
+		switch node.NodeType() {
+		case FullNode:
+			if len(key) == 0 {
+				node = node.child
+			} else {
+				node = node.children[key[0]]
+				key = key[1:]
+			}
+		case ShortNode:
+			if !bytes.HasPrefix(key, node.key) {
+				return proof, nil
+			}
+			key = key[len(node.key):]
+			node = node.child
+		case HashNode:
+			if len(key) == 0 {
+				value, err := trie.getRefValue(node.value)
+				if err != nil {
+					return nil, fmt.Errorf("get ref value of %s: %s", node.Hash().String(), err)
+				}
+				proof = append(proof, value)
+			}
+			return proof, nil
+		default:
+			return proof, nil
+		}
+	}
+	return proof, nil
+}
+
+// VerifyProof checks the proof against the root of the trie and returns the value of key, the value is nil if the
+// proof shows that key is not in the trie. A value node of a hash size can not be told from the hash node of a ref
+// value, so a caller expecting a value longer than a hash should reject a value of a hash size.
+func VerifyProof(root types.Hash, key []byte, proof Proof) ([]byte, error) {
+	expected := &root
+	for i := 0; i < len(proof); i++ {
+		node := new(TrieNode)
+		if err := node.Deserialize(proof[i]); err != nil {
+			return nil, err
+		}
+		// the hash is recomputed from the content, the serialized hash is not trusted
+		node.hash = nil
+		if *node.Hash() != *expected {
+			return nil, ErrInvalidProof
+		}
+
+		var next *TrieNode
+		switch node.NodeType() {
+		case FullNode:
+			if len(key) == 0 {
+				next = node.child
+			} else {
+				next = node.children[key[0]]
+				key = key[1:]
+			}
+		case ShortNode:
+			if !bytes.HasPrefix(key, node.key) {
+				return nil, checkProofEnd(proof, i)
+			}
+			key = key[len(node.key):]
+			next = node.child
+		case ValueNode:
+			if len(key) != 0 {
+				return nil, checkProofEnd(proof, i)
+			}
+			return node.value, checkProofEnd(proof, i)
+		case HashNode:
+			if len(key) != 0 {
+				return nil, checkProofEnd(proof, i)
+			}
+			if i+1 >= len(proof) {
+				return nil, ErrInvalidProof
+			}
+			if h := types.HashData(proof[i+1]); !bytes.Equal(h[:], node.value) {
+				return nil, ErrInvalidProof
+			}
+			return proof[i+1], checkProofEnd(proof, i+1)
+		default:
+			return nil, ErrInvalidProof
+		}
+
+		if next == nil {
+			return nil, checkProofEnd(proof, i)
+		}
+		expected = next.Hash()
+	}
+
+	// an empty trie has no node to prove
+	if len(proof) == 0 && root.IsZero() {
+		return nil, nil
+	}
+	return nil, ErrInvalidProof
+}
+
+func checkProofEnd(proof Proof, i int) error {
+	if i != len(proof)-1 {
+		return ErrInvalidProof
+	}
+	return nil
+}
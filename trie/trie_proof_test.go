@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/test/mock"
+)
+
+func TestTrie_Prove(t *testing.T) {
+	teardownTestCase, trie := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	values := map[string][]byte{
+		"":        []byte("NilNilNilNilNil"),
+		"IamG":    []byte("ki10$%^%&@#!@#"),
+		"IamGood": []byte("a1230xm90zm19ma"),
+		"tesab":   bytes.Repeat([]byte("value.555"), 10),
+		"tes":     []byte("asdfvale....asdfasdfasdfvalue.555val"),
+	}
+	for key, value := range values {
+		trie.SetValue([]byte(key), value)
+	}
+	callback, err := trie.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback()
+
+	// the proof of the reloaded trie is verified against the root
+	root := *trie.Hash()
+	trie = NewTrie(trie.db, &root, NewSimpleTrieNodePool())
+	for key, value := range values {
+		proof, err := trie.Prove([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v, err := VerifyProof(root, []byte(key), proof); err != nil || !bytes.Equal(v, value) {
+			t.Fatal("invalid value of", key, v, err)
+		}
+		if _, err := VerifyProof(mock.Hash(), []byte(key), proof); err != ErrInvalidProof {
+			t.Fatal("proof should not be verified by another root", err)
+		}
+	}
+
+	// the proof of a missing key shows the key is not in the trie
+	for _, key := range []string{"Iam", "IamGo", "IamGoodBye", "x"} {
+		proof, err := trie.Prove([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v, err := VerifyProof(root, []byte(key), proof); err != nil || v != nil {
+			t.Fatal("invalid missing value of", key, v, err)
+		}
+	}
+
+	proof, err := trie.Prove([]byte("tesab"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := append(Proof{}, proof...)
+	forged[len(forged)-1] = []byte("forged")
+	if _, err := VerifyProof(root, []byte("tesab"), forged); err != ErrInvalidProof {
+		t.Fatal("forged ref value should be rejected", err)
+	}
+	if _, err := VerifyProof(root, []byte("tesab"), proof[:len(proof)-1]); err != ErrInvalidProof {
+		t.Fatal("truncated proof should be rejected", err)
+	}
+	if _, err := VerifyProof(root, []byte("IamG"), proof); err != ErrInvalidProof {
+		t.Fatal("proof of another key should be rejected", err)
+	}
+
+	// an empty trie has an empty proof
+	empty := NewTrie(trie.db, nil, NewSimpleTrieNodePool())
+	if proof, err := empty.Prove([]byte("IamG")); err != nil || len(proof) != 0 {
+		t.Fatal(proof, err)
+	}
+	if v, err := VerifyProof(types.ZeroHash, []byte("IamG"), nil); err != nil || v != nil {
+		t.Fatal(v, err)
+	}
+}
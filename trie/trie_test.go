@@ -529,6 +529,27 @@ func TestTrieSaveAndLoadCase1(t *testing.T) {
 	}
 }
 
+func TestTrieSaveAndLoadRefValue(t *testing.T) {
+	teardownTestCase, trie := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	// a value longer than a hash is saved as a ref value
+	key := []byte("IamRef")
+	value := bytes.Repeat([]byte("0123456789"), 10)
+	trie.SetValue(key, value)
+
+	callback, err := trie.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback()
+
+	newTrie := NewTrie(trie.db, trie.Hash(), NewSimpleTrieNodePool())
+	if getValue := newTrie.GetValue(key); !bytes.Equal(value, getValue) {
+		t.Fatal("error!", hex.EncodeToString(value), "==>", hex.EncodeToString(getValue))
+	}
+}
+
 func TestTrieSaveAndLoad(t *testing.T) {
 	teardownTestCase, trie := setupTestCase(t)
 	defer teardownTestCase(t)
@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"reflect"
+
+	"github.com/qlcchain/go-qlc/common/types"
 )
 
 // The ABIContract holds information about a contract's context and available
@@ -71,6 +74,46 @@ func (abi ABIContract) PackVariable(name string, args ...interface{}) ([]byte, e
 	return arguments, nil
 }
 
+// PackEvent packs the given event name to a log conforming the ABI. The first topic is the event id unless the
+// event is anonymous, it is followed by a topic for every indexed argument. An indexed argument which packs to a
+// single word is the topic itself, otherwise the topic is the hash of the packed argument. The non-indexed
+// arguments are packed to the log data in order.
+func (abi ABIContract) PackEvent(name string, args ...interface{}) (*types.VmLog, error) {
+	event, exist := abi.Events[name]
+	if !exist {
+		return nil, fmt.Errorf("event '%s' not found", name)
+	}
+	if len(args) != len(event.Inputs) {
+		return nil, fmt.Errorf("argument count mismatch: %d for %d", len(args), len(event.Inputs))
+	}
+
+	log := &types.VmLog{Topics: make([]types.Hash, 0)}
+	if !event.Anonymous {
+		log.Topics = append(log.Topics, event.Id())
+	}
+	var data []interface{}
+	for i, input := range event.Inputs {
+		if !input.Indexed {
+			data = append(data, args[i])
+			continue
+		}
+		packed, err := input.Type.pack(reflect.ValueOf(args[i]))
+		if err != nil {
+			return nil, err
+		}
+		topic := types.HashData(packed)
+		if !input.Type.requiresLengthPrefix() && len(packed) == types.HashSize {
+			topic, _ = types.BytesToHash(packed)
+		}
+		log.Topics = append(log.Topics, topic)
+	}
+	var err error
+	if log.Data, err = event.Inputs.NonIndexed().Pack(data...); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
 // UnpackMethod output in v according to the abi specification
 func (abi ABIContract) UnpackMethod(v interface{}, name string, output []byte) (err error) {
 	if len(output) <= 4 {
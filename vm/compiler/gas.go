@@ -5,7 +5,8 @@ func (c *SSAFunctionCompiler) InsertGasCounters(gp GasPolicy) {
 
 	for i, _ := range cfg.Blocks {
 		blk := &cfg.Blocks[i]
-		totalCost := int64(0)
+		// the jump ending the block is not in its code, it is charged too so that an empty loop uses gas
+		totalCost := gp.GetCost(blk.jmpOp())
 		for _, ins := range blk.Code {
 			totalCost += gp.GetCost(ins.Op)
 			if totalCost < 0 {
@@ -21,3 +22,16 @@ func (c *SSAFunctionCompiler) InsertGasCounters(gp GasPolicy) {
 	}
 	c.Code = cfg.ToInsSeq()
 }
+
+func (blk *BasicBlock) jmpOp() string {
+	switch blk.JmpKind {
+	case JmpEither:
+		return "jmp_either"
+	case JmpTable:
+		return "jmp_table"
+	case JmpReturn:
+		return "return"
+	default:
+		return "jmp"
+	}
+}
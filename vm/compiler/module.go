@@ -7,6 +7,7 @@ import (
 
 	//"fmt"
 
+	"github.com/go-interpreter/wagon/disasm"
 	"github.com/go-interpreter/wagon/wasm"
 
 	//"github.com/go-interpreter/wagon/validate"
@@ -215,40 +216,36 @@ func (m *Module) CompileForInterpreter(gp GasPolicy) (_retCode []InterpreterCode
 		}
 	}
 
-	// numFuncImports := len(ret)
+	numFuncImports := len(ret)
 	ret = append(ret, make([]InterpreterCode, len(m.Base.FunctionIndexSpace))...)
 
-	// for i, f := range m.Base.FunctionIndexSpace {
-	//fmt.Printf("Compiling function %d (%+v) with %d locals\n", i, f.Sig, len(f.Body.Locals))
-	// d, err := disasm.SimpleDisassemble(f, m.Base)
-	// if err != nil {
-	// 	panic(err)
-	// }
-	// compiler := NewSSAFunctionCompiler(m.Base, d)
-	// compiler.CallIndexOffset = numFuncImports
-	// compiler.Compile(importTypeIDs)
-	// if m.DisableFloatingPoint {
-	// 	compiler.FilterFloatingPoint()
-	// }
-	// if gp != nil {
-	// 	compiler.InsertGasCounters(gp)
-	// }
-	// //fmt.Println(compiler.Code)
-	// //fmt.Printf("%+v\n", compiler.NewCFGraph())
-	// numRegs := compiler.RegAlloc()
-	// //fmt.Println(compiler.Code)
-	// numLocals := 0
-	// for _, v := range f.Body.Locals {
-	// 	numLocals += int(v.Count)
-	// }
-	// ret[numFuncImports+i] = InterpreterCode{
-	// 	NumRegs:    numRegs,
-	// 	NumParams:  len(f.Sig.ParamTypes),
-	// 	NumLocals:  numLocals,
-	// 	NumReturns: len(f.Sig.ReturnTypes),
-	// 	Bytes:      compiler.Serialize(),
-	// }
-	// }
+	for i, f := range m.Base.FunctionIndexSpace {
+		d, err := disasm.NewDisassembly(f, m.Base)
+		if err != nil {
+			return nil, err
+		}
+		compiler := NewSSAFunctionCompiler(m.Base, d)
+		compiler.CallIndexOffset = numFuncImports
+		compiler.Compile(importTypeIDs)
+		if m.DisableFloatingPoint {
+			compiler.FilterFloatingPoint()
+		}
+		if gp != nil {
+			compiler.InsertGasCounters(gp)
+		}
+		numRegs := compiler.RegAlloc()
+		numLocals := 0
+		for _, v := range f.Body.Locals {
+			numLocals += int(v.Count)
+		}
+		ret[numFuncImports+i] = InterpreterCode{
+			NumRegs:    numRegs,
+			NumParams:  len(f.Sig.ParamTypes),
+			NumLocals:  numLocals,
+			NumReturns: len(f.Sig.ReturnTypes),
+			Bytes:      compiler.Serialize(),
+		}
+	}
 
 	return ret, nil
 }
@@ -29,12 +29,16 @@ const (
 	[
 		{"type":"function","name":"Mintage","inputs":[{"name":"tokenId","type":"tokenId"},{"name":"tokenName","type":"string"},{"name":"tokenSymbol","type":"string"},{"name":"totalSupply","type":"uint256"},{"name":"decimals","type":"uint8"},{"name":"beneficial","type":"address"},{"name":"NEP5TxId","type":"string"}]},
 		{"type":"function","name":"Withdraw","inputs":[{"name":"tokenId","type":"tokenId"}]},
+		{"type":"event","name":"Mintage","inputs":[{"name":"tokenId","type":"tokenId","indexed":true},{"name":"beneficial","type":"address","indexed":true},{"name":"tokenSymbol","type":"string"},{"name":"totalSupply","type":"uint256"},{"name":"pledgeAmount","type":"uint256"}]},
+		{"type":"event","name":"Withdraw","inputs":[{"name":"tokenId","type":"tokenId","indexed":true},{"name":"pledgeAddress","type":"address","indexed":true},{"name":"amount","type":"uint256"}]},
 		{"type":"variable","name":"token","inputs":[{"name":"tokenId","type":"tokenId"},{"name":"tokenName","type":"string"},{"name":"tokenSymbol","type":"string"},{"name":"totalSupply","type":"uint256"},{"name":"decimals","type":"uint8"},{"name":"owner","type":"address"},{"name":"pledgeAmount","type":"uint256"},{"name":"withdrawTime","type":"int64"},{"name":"pledgeAddress","type":"address"},{"name":"NEP5TxId","type":"string"}]},
 		{"type":"variable","name":"genesisToken","inputs":[{"name":"tokenId","type":"tokenId"},{"name":"tokenName","type":"string"},{"name":"tokenSymbol","type":"string"},{"name":"totalSupply","type":"uint256"},{"name":"decimals","type":"uint8"},{"name":"owner","type":"address"},{"name":"pledgeAmount","type":"uint256"},{"name":"withdrawTime","type":"int64"},{"name":"pledgeAddress","type":"address"}]}
 	]`
 
 	MethodNameMintage         = "Mintage"
 	MethodNameMintageWithdraw = "Withdraw"
+	EventNameMintage          = "Mintage"
+	EventNameMintageWithdraw  = "Withdraw"
 	VariableNameToken         = "token"
 	VariableNameGenesisToken  = "genesisToken"
 )
@@ -27,11 +27,15 @@ const (
 	[
 		{"type":"function","name":"NEP5Pledge", "inputs":[{"name":"beneficial","type":"address"},{"name":"pledgeAddress","type":"address"},{"name":"pType","type":"uint8"},{"name":"NEP5TxId","type":"string"}]},
 		{"type":"function","name":"WithdrawNEP5Pledge","inputs":[{"name":"beneficial","type":"address"},{"name":"amount","type":"uint256"},{"name":"pType","type":"uint8"}]},
+		{"type":"event","name":"NEP5Pledge","inputs":[{"name":"beneficial","type":"address","indexed":true},{"name":"pledgeAddress","type":"address","indexed":true},{"name":"pType","type":"uint8"},{"name":"amount","type":"uint256"},{"name":"withdrawTime","type":"int64"},{"name":"NEP5TxId","type":"string"}]},
+		{"type":"event","name":"WithdrawNEP5Pledge","inputs":[{"name":"beneficial","type":"address","indexed":true},{"name":"pledgeAddress","type":"address","indexed":true},{"name":"pType","type":"uint8"},{"name":"amount","type":"uint256"},{"name":"NEP5TxId","type":"string"}]},
 		{"type":"variable","name":"nep5PledgeInfo","inputs":[{"name":"pType","type":"uint8"},{"name":"amount","type":"uint256"},{"name":"withdrawTime","type":"int64"},{"name":"beneficial","type":"address"},{"name":"pledgeAddress","type":"address"},{"name":"NEP5TxId","type":"string"}]}
 	]`
 
 	MethodNEP5Pledge         = "NEP5Pledge"
 	MethodWithdrawNEP5Pledge = "WithdrawNEP5Pledge"
+	EventNEP5Pledge          = "NEP5Pledge"
+	EventWithdrawNEP5Pledge  = "WithdrawNEP5Pledge"
 	VariableNEP5PledgeInfo   = "nep5PledgeInfo"
 )
 
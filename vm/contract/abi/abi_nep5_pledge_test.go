@@ -8,10 +8,12 @@
 package abi
 
 import (
+	"math/big"
 	"sort"
 	"testing"
 
 	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/test/mock"
 )
 
 func TestSortNEP5PledgeInfo(t *testing.T) {
@@ -46,3 +48,24 @@ func TestSortNEP5PledgeInfo(t *testing.T) {
 		t.Log(v)
 	}
 }
+
+func TestNEP5PledgeABI_PackEvent(t *testing.T) {
+	beneficial := mock.Address()
+	pledgeAddress := mock.Address()
+	log, err := NEP5PledgeABI.PackEvent(EventNEP5Pledge, beneficial, pledgeAddress, uint8(Vote), big.NewInt(100),
+		int64(1000), "0x01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log.Topics) != 3 || log.Topics[0] != NEP5PledgeABI.Events[EventNEP5Pledge].Id() ||
+		log.Topics[1] != beneficial.ToHash() || log.Topics[2] != pledgeAddress.ToHash() {
+		t.Fatal("invalid topics", log.Topics)
+	}
+	info := new(NEP5PledgeInfo)
+	if err := NEP5PledgeABI.UnpackEvent(info, EventNEP5Pledge, log.Data); err != nil {
+		t.Fatal(err)
+	}
+	if info.PType != uint8(Vote) || info.Amount.Int64() != 100 || info.WithdrawTime != 1000 || info.NEP5TxId != "0x01" {
+		t.Fatal("invalid data", info)
+	}
+}
@@ -8,6 +8,8 @@
 package contract
 
 import (
+	"fmt"
+
 	"github.com/pkg/errors"
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/vm/abi"
@@ -71,6 +73,54 @@ func GetChainContract(addr types.Address, methodSelector []byte) (ChainContract,
 	return nil, ok, nil
 }
 
+// Refund fills block with the ContractRefund block which returns the amount of the contract send input to its
+// sender, it is the receive of a contract send whose DoReceive fails. The data of the block is the refund data of
+// the chain contract c.
+func Refund(ctx *vmstore.VMContext, c ChainContract, block, input *types.StateBlock) (*ContractBlock, error) {
+	amount, err := ctx.CalculateAmount(input)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Sign() <= 0 {
+		return nil, errors.New("nothing to refund")
+	}
+	am, err := ctx.GetAccountMeta(input.Address)
+	if err != nil {
+		return nil, err
+	}
+	tm := am.Token(input.Token)
+	if tm == nil {
+		return nil, fmt.Errorf("token %s of %s not found", input.Token.String(), input.Address.String())
+	}
+	prev, err := ctx.GetStateBlock(tm.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	block.Type = types.ContractRefund
+	block.Address = input.Address
+	block.Representative = tm.Representative
+	block.Token = input.Token
+	block.Link = input.GetHash()
+	block.Data = c.GetRefundData()
+	block.Previous = tm.Header
+	block.Balance = tm.Balance.Add(amount)
+	block.Vote = prev.GetVote()
+	block.Network = prev.GetNetwork()
+	block.Oracle = prev.GetOracle()
+	block.Storage = prev.GetStorage()
+
+	return &ContractBlock{
+		VMContext: ctx,
+		Block:     block,
+		ToAddress: input.Address,
+		BlockType: types.ContractRefund,
+		Amount:    amount,
+		Token:     input.Token,
+		Data:      block.Data,
+	}, nil
+}
+
 func IsChainContract(addr types.Address) bool {
 	if _, ok := contractCache[addr]; ok {
 		return true
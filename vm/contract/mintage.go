@@ -110,6 +110,10 @@ func (m *Mintage) DoReceive(ctx *vmstore.VMContext, block *types.StateBlock, inp
 		return nil, fmt.Errorf("invalid block amount %d", amount.Int)
 	}
 
+	if err := VerifyNEP5Tx(param.NEP5TxId, amount.Int, param.Beneficial); err != nil {
+		return nil, err
+	}
+
 	if _, err := ctx.GetStorage(types.MintageAddress[:], []byte(param.NEP5TxId)); err == nil {
 		return nil, fmt.Errorf("invalid nep5 tx id %s", param.NEP5TxId)
 	} else {
@@ -142,6 +146,13 @@ func (m *Mintage) DoReceive(ctx *vmstore.VMContext, block *types.StateBlock, inp
 		}
 	}
 
+	if log, err := cabi.MintageABI.PackEvent(cabi.EventNameMintage, param.TokenId, param.Beneficial,
+		param.TokenSymbol, param.TotalSupply, amount.Int); err != nil {
+		return nil, err
+	} else {
+		ctx.AddLog(log)
+	}
+
 	return []*ContractBlock{
 		{
 			VMContext: ctx,
@@ -260,6 +271,12 @@ func (m *WithdrawMintage) DoReceive(ctx *vmstore.VMContext, block, input *types.
 	}
 
 	if tokenInfo.PledgeAmount.Sign() > 0 {
+		if log, err := cabi.MintageABI.PackEvent(cabi.EventNameMintageWithdraw, tokenInfo.TokenId,
+			tokenInfo.PledgeAddress, tokenInfo.PledgeAmount); err != nil {
+			return nil, err
+		} else {
+			ctx.AddLog(log)
+		}
 		return []*ContractBlock{
 			{
 				VMContext: ctx,
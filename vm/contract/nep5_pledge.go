@@ -103,6 +103,10 @@ func (*Nep5Pledge) DoReceive(ctx *vmstore.VMContext, block, input *types.StateBl
 		NEP5TxId:      param.NEP5TxId,
 	}
 
+	if err := VerifyNEP5Tx(param.NEP5TxId, amount.Int, param.Beneficial); err != nil {
+		return nil, err
+	}
+
 	if _, err := ctx.GetStorage(types.NEP5PledgeAddress[:], []byte(param.NEP5TxId)); err == nil {
 		return nil, fmt.Errorf("invalid nep5 tx id")
 	} else {
@@ -184,6 +188,13 @@ func (*Nep5Pledge) DoReceive(ctx *vmstore.VMContext, block, input *types.StateBl
 		break
 	}
 
+	if log, err := cabi.NEP5PledgeABI.PackEvent(cabi.EventNEP5Pledge, info.Beneficial, info.PledgeAddress,
+		info.PType, info.Amount, info.WithdrawTime, info.NEP5TxId); err != nil {
+		return nil, err
+	} else {
+		ctx.AddLog(log)
+	}
+
 	return []*ContractBlock{
 		{
 			VMContext: ctx,
@@ -298,6 +309,14 @@ func (*WithdrawNep5Pledge) DoReceive(ctx *vmstore.VMContext, block, input *types
 	block.Representative = tm.Representative
 	block.Balance = am.CoinBalance.Add(amount)
 
+	if log, err := cabi.NEP5PledgeABI.PackEvent(cabi.EventWithdrawNEP5Pledge, pledgeInfo.PledgeInfo.Beneficial,
+		pledgeInfo.PledgeInfo.PledgeAddress, pledgeInfo.PledgeInfo.PType, amount.Int,
+		pledgeInfo.PledgeInfo.NEP5TxId); err != nil {
+		return nil, err
+	} else {
+		ctx.AddLog(log)
+	}
+
 	return []*ContractBlock{
 		{
 			VMContext: ctx,
@@ -0,0 +1,193 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/qlcchain/go-qlc/common/types"
+)
+
+var (
+	ErrNEP5TxNotFound   = errors.New("nep5 tx not found")
+	ErrNEP5TxAmount     = errors.New("invalid nep5 tx amount")
+	ErrNEP5TxBeneficial = errors.New("invalid nep5 tx beneficial")
+)
+
+// NEP5Tx is the NEP5 lock transaction on the NEO chain referenced by a mintage or a pledge, Amount is the locked
+// amount in raw and Beneficial is the QLC address the lock is made for
+type NEP5Tx struct {
+	TxId       string        `json:"txId"`
+	Amount     *big.Int      `json:"amount"`
+	Beneficial types.Address `json:"beneficial"`
+}
+
+// NEP5Verifier confirms the NEP5 lock transaction referenced by the chain contracts
+type NEP5Verifier interface {
+	// Verify returns an error if the transaction txId does not exist or does not lock amount for beneficial
+	Verify(txId string, amount *big.Int, beneficial types.Address) error
+}
+
+// NEP5TxUnverifiedError is returned when the verifier fails to look up the transaction, the failure may be
+// transient so the transaction is neither verified nor rejected
+type NEP5TxUnverifiedError struct {
+	TxId string
+	Err  error
+}
+
+func (e *NEP5TxUnverifiedError) Error() string {
+	return fmt.Sprintf("verify nep5 tx %s: %s", e.TxId, e.Err)
+}
+
+// IsNEP5TxUnverified reports whether err is a NEP5TxUnverifiedError
+func IsNEP5TxUnverified(err error) bool {
+	_, ok := err.(*NEP5TxUnverifiedError)
+	return ok
+}
+
+const nep5VerifiedCacheSize = 1024
+
+var (
+	nep5Lock     sync.RWMutex
+	nep5Verifier NEP5Verifier
+	nep5Verified = gcache.New(nep5VerifiedCacheSize).LRU().Build()
+)
+
+// SetNEP5Verifier sets the verifier consulted by the receive of the mintage and the pledge contracts, no
+// transaction is verified if v is nil
+func SetNEP5Verifier(v NEP5Verifier) {
+	nep5Lock.Lock()
+	defer nep5Lock.Unlock()
+	nep5Verifier = v
+	nep5Verified = gcache.New(nep5VerifiedCacheSize).LRU().Build()
+}
+
+// VerifyNEP5Tx verifies the NEP5 lock transaction referenced by a contract send. ErrNEP5TxNotFound,
+// ErrNEP5TxAmount and ErrNEP5TxBeneficial reject the transaction, any other failure of the verifier is returned
+// as a NEP5TxUnverifiedError. Only the verified transactions are cached.
+func VerifyNEP5Tx(txId string, amount *big.Int, beneficial types.Address) error {
+	key := fmt.Sprintf("%s/%s/%s", txId, amount, beneficial.String())
+	nep5Lock.RLock()
+	v, verified := nep5Verifier, nep5Verified
+	nep5Lock.RUnlock()
+	if v == nil || verified.Has(key) {
+		return nil
+	}
+	if err := v.Verify(txId, amount, beneficial); err != nil {
+		switch err {
+		case ErrNEP5TxNotFound, ErrNEP5TxAmount, ErrNEP5TxBeneficial:
+			return err
+		default:
+			return &NEP5TxUnverifiedError{TxId: txId, Err: err}
+		}
+	}
+	_ = verified.Set(key, true)
+	return nil
+}
+
+func checkNEP5Tx(tx *NEP5Tx, amount *big.Int, beneficial types.Address) error {
+	if tx.Amount == nil || amount == nil || tx.Amount.Cmp(amount) != 0 {
+		return ErrNEP5TxAmount
+	}
+	if tx.Beneficial != beneficial {
+		return ErrNEP5TxBeneficial
+	}
+	return nil
+}
+
+// FileNEP5Verifier verifies the transactions against a JSON file which holds an array of NEP5Tx, it stands in for
+// the NEO chain in tests and private networks. The file is read on every verification so transactions can be
+// added while the node is running.
+type FileNEP5Verifier struct {
+	path string
+}
+
+func NewFileNEP5Verifier(path string) *FileNEP5Verifier {
+	return &FileNEP5Verifier{path: path}
+}
+
+func (f *FileNEP5Verifier) Verify(txId string, amount *big.Int, beneficial types.Address) error {
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	var txs []*NEP5Tx
+	if err := json.Unmarshal(data, &txs); err != nil {
+		return err
+	}
+	for _, tx := range txs {
+		if tx.TxId == txId {
+			return checkNEP5Tx(tx, amount, beneficial)
+		}
+	}
+	return ErrNEP5TxNotFound
+}
+
+const nep5RPCTimeout = 10 * time.Second
+
+// RPCNEP5Verifier queries the transactions from a JSON-RPC service with the method, the method takes the tx id as
+// its only parameter and returns the NEP5Tx, a null result means the transaction is not found
+type RPCNEP5Verifier struct {
+	endpoint string
+	method   string
+	client   *http.Client
+}
+
+func NewRPCNEP5Verifier(endpoint, method string) *RPCNEP5Verifier {
+	return &RPCNEP5Verifier{endpoint: endpoint, method: method, client: &http.Client{Timeout: nep5RPCTimeout}}
+}
+
+type nep5RPCRequest struct {
+	Version string        `json:"jsonrpc"`
+	Id      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type nep5RPCResponse struct {
+	Result *NEP5Tx `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (r *RPCNEP5Verifier) Verify(txId string, amount *big.Int, beneficial types.Address) error {
+	body, err := json.Marshal(&nep5RPCRequest{Version: "2.0", Id: 1, Method: r.method, Params: []interface{}{txId}})
+	if err != nil {
+		return err
+	}
+	rsp, err := r.client.Post(r.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("nep5 rpc status %s", rsp.Status)
+	}
+	result := new(nep5RPCResponse)
+	if err := json.NewDecoder(rsp.Body).Decode(result); err != nil {
+		return err
+	}
+	if result.Error != nil {
+		return fmt.Errorf("nep5 rpc error %d: %s", result.Error.Code, result.Error.Message)
+	}
+	if result.Result == nil || result.Result.TxId != txId {
+		return ErrNEP5TxNotFound
+	}
+	return checkNEP5Tx(result.Result, amount, beneficial)
+}
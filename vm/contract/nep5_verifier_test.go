@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/test/mock"
+)
+
+func testNEP5Verifier(t *testing.T, v NEP5Verifier, tx *NEP5Tx) {
+	if err := v.Verify(tx.TxId, big.NewInt(100), tx.Beneficial); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Verify(tx.TxId, big.NewInt(99), tx.Beneficial); err != ErrNEP5TxAmount {
+		t.Fatal(err)
+	}
+	if err := v.Verify(tx.TxId, big.NewInt(100), mock.Address()); err != ErrNEP5TxBeneficial {
+		t.Fatal(err)
+	}
+	if err := v.Verify("unknown", big.NewInt(100), tx.Beneficial); err != ErrNEP5TxNotFound {
+		t.Fatal(err)
+	}
+}
+
+func TestFileNEP5Verifier_Verify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nep5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tx := &NEP5Tx{TxId: "0x4b7c", Amount: big.NewInt(100), Beneficial: mock.Address()}
+	data, _ := json.Marshal([]*NEP5Tx{tx})
+	path := filepath.Join(dir, "nep5.json")
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	testNEP5Verifier(t, NewFileNEP5Verifier(path), tx)
+
+	if err := NewFileNEP5Verifier(filepath.Join(dir, "none.json")).Verify(tx.TxId, tx.Amount, tx.Beneficial); err == nil {
+		t.Fatal("missing file should fail")
+	}
+}
+
+func TestRPCNEP5Verifier_Verify(t *testing.T) {
+	tx := &NEP5Tx{TxId: "0x4b7c", Amount: big.NewInt(100), Beneficial: mock.Address()}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := new(nep5RPCRequest)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil || req.Method != "nep5_getLockTransaction" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		rsp := map[string]interface{}{"jsonrpc": "2.0", "id": req.Id, "result": nil}
+		if req.Params[0] == tx.TxId {
+			rsp["result"] = tx
+		}
+		_ = json.NewEncoder(w).Encode(rsp)
+	}))
+	defer server.Close()
+
+	testNEP5Verifier(t, NewRPCNEP5Verifier(server.URL, "nep5_getLockTransaction"), tx)
+	if err := NewRPCNEP5Verifier(server.URL, "other").Verify(tx.TxId, tx.Amount, tx.Beneficial); err == nil {
+		t.Fatal("rpc error should fail")
+	}
+}
+
+type rejectVerifier struct{}
+
+func (rejectVerifier) Verify(txId string, amount *big.Int, beneficial types.Address) error {
+	return ErrNEP5TxNotFound
+}
+
+type countVerifier struct {
+	count int
+	err   error
+}
+
+func (v *countVerifier) Verify(txId string, amount *big.Int, beneficial types.Address) error {
+	v.count++
+	return v.err
+}
+
+func TestSetNEP5Verifier(t *testing.T) {
+	if err := VerifyNEP5Tx("0x01", big.NewInt(1), mock.Address()); err != nil {
+		t.Fatal(err)
+	}
+	SetNEP5Verifier(rejectVerifier{})
+	defer SetNEP5Verifier(nil)
+	if err := VerifyNEP5Tx("0x01", big.NewInt(1), mock.Address()); err != ErrNEP5TxNotFound {
+		t.Fatal("verifier is not consulted", err)
+	}
+}
+
+func TestVerifyNEP5Tx_Cache(t *testing.T) {
+	v := &countVerifier{err: errors.New("timeout")}
+	SetNEP5Verifier(v)
+	defer SetNEP5Verifier(nil)
+
+	beneficial := mock.Address()
+	// a failed verification is not cached, it may be transient
+	for i := 0; i < 2; i++ {
+		if err := VerifyNEP5Tx("0x01", big.NewInt(1), beneficial); !IsNEP5TxUnverified(err) {
+			t.Fatal("verifier error is not unverified", err)
+		}
+	}
+	v.err = nil
+	for i := 0; i < 2; i++ {
+		if err := VerifyNEP5Tx("0x01", big.NewInt(1), beneficial); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v.count != 3 {
+		t.Fatal("verified tx is not cached", v.count)
+	}
+	// the cache is by tx id, amount and beneficial
+	if err := VerifyNEP5Tx("0x01", big.NewInt(2), beneficial); err != nil || v.count != 4 {
+		t.Fatal(v.count, err)
+	}
+}
@@ -8,6 +8,7 @@
 package engine
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -16,8 +17,8 @@ import (
 	"github.com/qlcchain/go-qlc/ledger"
 	"github.com/qlcchain/go-qlc/log"
 	"github.com/qlcchain/go-qlc/vm/abi"
+	"github.com/qlcchain/go-qlc/vm/compiler"
 	"github.com/qlcchain/go-qlc/vm/exec"
-	"github.com/qlcchain/go-qlc/vm/platform"
 	"github.com/qlcchain/go-qlc/vm/resolver"
 	"go.uber.org/zap"
 )
@@ -27,6 +28,15 @@ const (
 	ContractInitMethod = "init"
 )
 
+const (
+	//ContractGasLimit is the most gas a call of a contract can use, every instruction costs one gas
+	ContractGasLimit = 10000000
+	//ContractMaxMemoryPages is the most memory pages of 64KB a contract can use
+	ContractMaxMemoryPages = 256
+	//ContractMaxCallStackDepth is the deepest call stack of a contract
+	ContractMaxCallStackDepth = 1024
+)
+
 type SignFunc func(addr types.Address, data []byte) (types.Signature, error)
 
 type GenResult struct {
@@ -44,20 +54,27 @@ type ExecuteEngine struct {
 	logger *zap.SugaredLogger
 }
 
-// NewExecuteEngine new vm execute engine
-func NewExecuteEngine(caller types.Address, contract *types.SmartContractBlock, ledger *ledger.Ledger) *ExecuteEngine {
-	vm, _ := exec.NewVirtualMachine(contract.Abi.Abi, exec.VMConfig{
+// NewExecuteEngine new vm execute engine, the wasm code and the abi schema of the contract are loaded, the code is
+// metered so that a call fails once it uses more than ContractGasLimit
+func NewExecuteEngine(caller types.Address, contract *types.SmartContractBlock, ledger *ledger.Ledger) (*ExecuteEngine, error) {
+	vm, err := exec.NewVirtualMachine(contract.Abi.Abi, exec.VMConfig{
+		MaxMemoryPages:       ContractMaxMemoryPages,
+		MaxCallStackDepth:    ContractMaxCallStackDepth,
 		DefaultMemoryPages:   128,
 		DefaultTableSize:     65536,
+		GasLimit:             ContractGasLimit,
 		DisableFloatingPoint: false,
-	}, resolver.NewResolver(), nil)
-
-	aotSvc := platform.FullAOTCompile(vm)
-	if aotSvc != nil {
-		vm.SetAOTService(aotSvc)
+	}, resolver.NewResolver(), &compiler.SimpleGasPolicy{GasPerInstruction: 1})
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract code: %s", err)
 	}
 
-	schema, _ := abi.JSONToABIContract(strings.NewReader(contract.AbiSchema))
+	//the native code generation of compiler is disabled, contracts always run in the interpreter
+
+	schema, err := abi.JSONToABIContract(strings.NewReader(contract.AbiSchema))
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract abi schema: %s", err)
+	}
 
 	return &ExecuteEngine{
 		vm,
@@ -66,7 +83,7 @@ func NewExecuteEngine(caller types.Address, contract *types.SmartContractBlock,
 		caller,
 		ledger,
 		log.NewLogger("engine"),
-	}
+	}, nil
 }
 
 // InitCall init method on deployment
@@ -76,6 +93,9 @@ func (e *ExecuteEngine) InitCall(args []byte) (result []byte, err error) {
 
 // Call invoke to run the contract code by block.data
 func (e *ExecuteEngine) Call(data []byte) (result []byte, err error) {
+	if len(data) < 4 {
+		return nil, errors.New("invalid contract data")
+	}
 	method, err := e.schema.MethodById(data[:4])
 	if err != nil {
 		return nil, err
@@ -85,9 +105,9 @@ func (e *ExecuteEngine) Call(data []byte) (result []byte, err error) {
 
 func (e *ExecuteEngine) call(action string, args []byte) (result []byte, err error) {
 	defer func() {
-		if err := recover(); err != nil {
+		if r := recover(); r != nil {
 			result = nil
-			err = errors.New("execute engine call panic error")
+			err = fmt.Errorf("execute engine call panic error: %v", r)
 		}
 	}()
 
@@ -61,6 +61,21 @@ func (e *ExecuteEngine) GenerateBlock(block *types.StateBlock, signFn SignFunc)
 	}, nil
 }
 
+// Run executes the contract method encoded in the data of the contract send block, block is the send block itself
+// or the receive block of sendBlock. The contract changes no balance, so no block is generated.
 func (e *ExecuteEngine) Run(block, sendBlock *types.StateBlock) (blockList []*types.StateBlock, isRetry bool, err error) {
+	input := block
+	if sendBlock != nil {
+		input = sendBlock
+	}
+	if input.GetType() != types.ContractSend {
+		return nil, false, fmt.Errorf("invalid contract block type %s", input.GetType().String())
+	}
+	if types.Address(input.GetLink()) != e.block.Address {
+		return nil, false, fmt.Errorf("block %s does not call contract %s", input.GetHash().String(), e.block.Address.String())
+	}
+	if _, err := e.Call(input.GetData()); err != nil {
+		return nil, false, err
+	}
 	return nil, false, nil
 }
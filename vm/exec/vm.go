@@ -119,6 +119,9 @@ func NewVirtualMachine(
 	impResolver ImportResolver,
 	gasPolicy compiler.GasPolicy,
 ) (_retVM *VirtualMachine, retErr error) {
+	// the module is untrusted, a malformed one must not crash the caller while it is loaded or compiled
+	defer utils.CatchPanic(&retErr)
+
 	if config.EnableJIT {
 		fmt.Println("Warning: JIT support is removed.")
 	}
@@ -135,8 +138,6 @@ func NewVirtualMachine(
 		return nil, err
 	}
 
-	defer utils.CatchPanic(&retErr)
-
 	table := make([]uint32, 0)
 	globals := make([]int64, 0)
 	funcImports := make([]FunctionImportInfo, 0)
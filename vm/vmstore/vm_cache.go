@@ -8,6 +8,8 @@
 package vmstore
 
 import (
+	"sort"
+
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/trie"
 )
@@ -16,28 +18,70 @@ type VMCache struct {
 	logList types.VmLogs
 	storage map[string][]byte
 
-	trie      *trie.Trie
-	trieDirty bool
+	// storage tries of the contracts, the cached storage of a dirty contract is not applied to its trie yet
+	tries    map[types.Address]*trie.Trie
+	dirty    map[types.Address]bool
+	loadTrie func(address types.Address) *trie.Trie
 }
 
-func NewVMCache(trie *trie.Trie) *VMCache {
+// NewVMCache creates a cache whose storage tries are loaded by loadTrie on first use
+func NewVMCache(loadTrie func(address types.Address) *trie.Trie) *VMCache {
 	return &VMCache{
-		storage:   make(map[string][]byte),
-		trie:      trie.Clone(),
-		trieDirty: false,
+		storage:  make(map[string][]byte),
+		tries:    make(map[types.Address]*trie.Trie),
+		dirty:    make(map[types.Address]bool),
+		loadTrie: loadTrie,
+	}
+}
+
+// storageAddress returns the contract of the storage key, the storage key is the storage prefix followed by the
+// contract address
+func storageAddress(key []byte) (types.Address, bool) {
+	if len(key) < 1+types.AddressSize {
+		return types.ZeroAddress, false
+	}
+	address, err := types.BytesToAddress(key[1 : 1+types.AddressSize])
+	if err != nil {
+		return types.ZeroAddress, false
+	}
+	return address, true
+}
+
+func (cache *VMCache) trie(address types.Address) *trie.Trie {
+	t, ok := cache.tries[address]
+	if !ok {
+		t = cache.loadTrie(address)
+		cache.tries[address] = t
 	}
+	return t
 }
 
-func (cache *VMCache) Trie() *trie.Trie {
-	if cache.trieDirty {
-		for key, value := range cache.storage {
-			cache.trie.SetValue([]byte(key), value)
+// Trie returns the storage trie of the contract with the cached storage of the contract applied, the storage is
+// applied sorted by key
+func (cache *VMCache) Trie(address types.Address) *trie.Trie {
+	t := cache.trie(address)
+	if cache.dirty[address] {
+		var keys []string
+		for key := range cache.storage {
+			if a, ok := storageAddress([]byte(key)); ok && a == address {
+				keys = append(keys, key)
+			}
 		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			t.SetValue([]byte(key), cache.storage[key])
+		}
+		delete(cache.dirty, address)
+	}
+	return t
+}
 
-		cache.storage = make(map[string][]byte)
-		cache.trieDirty = false
+// Tries returns the storage tries of the contracts whose storage is read or written
+func (cache *VMCache) Tries() map[types.Address]*trie.Trie {
+	for address := range cache.dirty {
+		cache.Trie(address)
 	}
-	return cache.trie
+	return cache.tries
 }
 
 func (cache *VMCache) SetStorage(key []byte, value []byte) {
@@ -46,28 +90,34 @@ func (cache *VMCache) SetStorage(key []byte, value []byte) {
 	}
 
 	cache.storage[string(key)] = value
-	cache.trieDirty = true
+	if address, ok := storageAddress(key); ok {
+		cache.trie(address)
+		cache.dirty[address] = true
+	}
 }
 
 func (cache *VMCache) GetStorage(key []byte) []byte {
 	if value, ok := cache.storage[string(key)]; ok && value != nil {
 		return value
 	}
-
-	return cache.trie.GetValue(key)
+	return nil
 }
 
 func (cache *VMCache) LogList() types.VmLogs {
 	return cache.logList
 }
 
+func (cache *VMCache) AppendLog(log *types.VmLog) {
+	cache.logList.Logs = append(cache.logList.Logs, log)
+}
+
 func (cache *VMCache) Storage() map[string][]byte {
 	return cache.storage
 }
 
 func (cache *VMCache) Clear() {
-	//TODO: reset trie
 	cache.logList.Logs = cache.logList.Logs[:0]
-	cache.trieDirty = false
 	cache.storage = make(map[string][]byte)
+	cache.tries = make(map[types.Address]*trie.Trie)
+	cache.dirty = make(map[types.Address]bool)
 }
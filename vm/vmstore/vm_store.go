@@ -10,11 +10,13 @@ package vmstore
 import (
 	"bytes"
 	"errors"
+	"sort"
+
 	"github.com/qlcchain/go-qlc/trie"
 
-	"github.com/dgraph-io/badger"
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/ledger"
+	"github.com/qlcchain/go-qlc/ledger/db"
 	"github.com/qlcchain/go-qlc/log"
 	"go.uber.org/zap"
 )
@@ -27,7 +29,7 @@ type ContractStore interface {
 	CalculateAmount(block *types.StateBlock) (types.Balance, error)
 	IsUserAccount(address types.Address) (bool, error)
 	GetAccountMeta(address types.Address) (*types.AccountMeta, error)
-	SaveStorage() error
+	SaveStorage(txns ...db.StoreTxn) error
 }
 
 const (
@@ -46,13 +48,81 @@ type VMContext struct {
 }
 
 func NewVMContext(l *ledger.Ledger) *VMContext {
-	//TODO: fix trie
-	t := trie.NewTrie(l.Store, nil, trie.NewSimpleTrieNodePool())
-	return &VMContext{
+	v := &VMContext{
 		ledger: l,
 		logger: log.NewLogger("vm_context"),
-		Cache:  NewVMCache(t),
 	}
+	v.Cache = NewVMCache(v.loadTrie)
+	return v
+}
+
+//loadTrie loads the storage trie of the contract at the root committed by the latest ContractReward block, the trie
+//of a contract without a root is built from its storage in the flat store
+func (v *VMContext) loadTrie(address types.Address) *trie.Trie {
+	t, err := v.committedTrie(address)
+	if err != nil {
+		v.logger.Error(err)
+	}
+	if t != nil {
+		return t
+	}
+	t = trie.NewTrie(v.ledger.Store, nil, trie.NewSimpleTrieNodePool())
+	err = v.Iterator(address[:], func(key []byte, value []byte) error {
+		k := make([]byte, len(key))
+		copy(k, key)
+		val := make([]byte, len(value))
+		copy(val, value)
+		t.SetValue(k, val)
+		return nil
+	})
+	if err != nil {
+		v.logger.Error(err)
+	}
+	return t
+}
+
+//ContractRoot returns the root of the storage trie of the contract with the storage changes of the context applied,
+//it is committed in the Extra of the ContractReward block
+func (v *VMContext) ContractRoot(address types.Address) types.Hash {
+	if h := v.Cache.Trie(address).Hash(); h != nil {
+		return *h
+	}
+	return types.ZeroHash
+}
+
+//StorageProof returns the key of the storage of the contract in its storage trie and the proof of the value against
+//the root returned by ContractRoot
+func (v *VMContext) StorageProof(address types.Address, key []byte) ([]byte, trie.Proof, error) {
+	storageKey := getStorageKey(address[:], key)
+	proof, err := v.Cache.Trie(address).Prove(storageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return storageKey, proof, nil
+}
+
+//InitContractRoot commits the storage of the contract to its storage trie if the contract has no root yet, it moves
+//the storage written before the trie commitment, such as the genesis token info, into the trie
+func (v *VMContext) InitContractRoot(address types.Address) error {
+	if _, err := v.ledger.GetContractRoot(address); err == nil {
+		return nil
+	} else if err != ledger.ErrContractRootNotFound {
+		return err
+	}
+
+	t := v.loadTrie(address)
+	fn, err := t.Save()
+	if err != nil {
+		return err
+	}
+	fn()
+
+	root := types.ZeroHash
+	if h := t.Hash(); h != nil {
+		root = *h
+	}
+	v.logger.Infof("init contract %s root %s", address.String(), root.String())
+	return v.ledger.SetContractRoot(address, root)
 }
 
 func (v *VMContext) IsUserAccount(address types.Address) (bool, error) {
@@ -74,7 +144,7 @@ func getStorageKey(prefix, key []byte) []byte {
 func (v *VMContext) GetStorage(prefix, key []byte) ([]byte, error) {
 	storageKey := getStorageKey(prefix, key)
 	if storage := v.Cache.GetStorage(storageKey); storage == nil {
-		if val, err := v.get(storageKey); err == nil {
+		if val, err := v.load(storageKey); err == nil {
 			return val, nil
 		} else {
 			return nil, err
@@ -84,6 +154,39 @@ func (v *VMContext) GetStorage(prefix, key []byte) ([]byte, error) {
 	}
 }
 
+//committedTrie returns the storage trie of the contract at its current root, it is nil if the contract has no root
+//and its storage is still kept in the flat store
+func (v *VMContext) committedTrie(address types.Address) (*trie.Trie, error) {
+	root, err := v.ledger.GetContractRoot(address)
+	if err != nil {
+		if err == ledger.ErrContractRootNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if root.IsZero() {
+		return trie.NewTrie(v.ledger.Store, nil, nil), nil
+	}
+	return trie.NewTrie(v.ledger.Store, &root, nil), nil
+}
+
+//load reads the saved storage, the storage of a contract with a root is read from its storage trie
+func (v *VMContext) load(key []byte) ([]byte, error) {
+	if address, ok := storageAddress(key); ok {
+		t, err := v.committedTrie(address)
+		if err != nil {
+			return nil, err
+		}
+		if t != nil {
+			if val, ok := t.Lookup(key); ok {
+				return val, nil
+			}
+			return nil, ErrStorageNotFound
+		}
+	}
+	return v.get(key)
+}
+
 func (v *VMContext) SetStorage(prefix, key []byte, value []byte) error {
 	storageKey := getStorageKey(prefix, key)
 
@@ -93,6 +196,23 @@ func (v *VMContext) SetStorage(prefix, key []byte, value []byte) error {
 }
 
 func (v *VMContext) Iterator(prefix []byte, fn func(key []byte, value []byte) error) error {
+	storagePrefix := getStorageKey(prefix, nil)
+	if address, ok := storageAddress(storagePrefix); ok {
+		t, err := v.committedTrie(address)
+		if err != nil {
+			return err
+		}
+		if t != nil {
+			it := t.NewIterator(storagePrefix)
+			for key, val, ok := it.Next(); ok; key, val, ok = it.Next() {
+				if err := fn(key, val); err != nil {
+					v.logger.Error(err)
+				}
+			}
+			return nil
+		}
+	}
+
 	txn := v.ledger.Store.NewTransaction(false)
 	defer func() {
 		txn.Discard()
@@ -124,10 +244,61 @@ func (v *VMContext) GetAccountMeta(address types.Address) (*types.AccountMeta, e
 	return v.ledger.GetAccountMeta(address)
 }
 
-func (v *VMContext) SaveStorage() error {
+func (v *VMContext) GetStateBlock(hash types.Hash) (*types.StateBlock, error) {
+	return v.ledger.GetStateBlock(hash)
+}
+
+//AddLog emits the log of the contract execution, the logs are saved with the contract storage
+func (v *VMContext) AddLog(log *types.VmLog) {
+	v.Cache.AppendLog(log)
+}
+
+//Logs returns the logs emitted by the contract execution, it is nil if nothing is emitted
+func (v *VMContext) Logs() *types.VmLogs {
+	logs := v.Cache.LogList()
+	if len(logs.Logs) == 0 {
+		return nil
+	}
+	return &logs
+}
+
+//StorageDiff is a storage change of the context which is not saved yet
+type StorageDiff struct {
+	Key      []byte `json:"key"`
+	Previous []byte `json:"previous"`
+	Value    []byte `json:"value"`
+}
+
+//StorageDiffs returns the storage changes cached by the context, sorted by key, the previous values are the saved
+//storage, key is the contract prefix followed by the storage key
+func (v *VMContext) StorageDiffs() ([]*StorageDiff, error) {
+	diffs := make([]*StorageDiff, 0, len(v.Cache.storage))
+	for k, val := range v.Cache.storage {
+		previous, err := v.load([]byte(k))
+		if err != nil && err != ErrStorageNotFound {
+			return nil, err
+		}
+		diffs = append(diffs, &StorageDiff{Key: []byte(k)[1:], Previous: previous, Value: val})
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return bytes.Compare(diffs[i].Key, diffs[j].Key) < 0
+	})
+	return diffs, nil
+}
+
+//SaveStorage saves the storage of the contracts without a root to the flat store, the storage of a contract with a
+//root is only saved in its storage trie by SaveTrie
+func (v *VMContext) SaveStorage(txns ...db.StoreTxn) error {
 	storage := v.Cache.storage
 	for k, val := range storage {
-		err := v.set([]byte(k), val)
+		if address, ok := storageAddress([]byte(k)); ok {
+			if _, err := v.ledger.GetContractRoot(address); err == nil {
+				continue
+			} else if err != ledger.ErrContractRootNotFound {
+				return err
+			}
+		}
+		err := v.set([]byte(k), val, txns...)
 		if err != nil {
 			v.logger.Error(err)
 			return err
@@ -136,12 +307,16 @@ func (v *VMContext) SaveStorage() error {
 	return nil
 }
 
-func (v *VMContext) SaveTrie() error {
-	fn, err := v.Cache.Trie().Save()
-	if err != nil {
-		return err
+//SaveTrie saves the nodes of the storage tries changed by the context, the root of a contract is only moved when
+//its ContractReward block is processed
+func (v *VMContext) SaveTrie(txns ...db.StoreTxn) error {
+	for _, t := range v.Cache.Tries() {
+		fn, err := t.Save(txns...)
+		if err != nil {
+			return err
+		}
+		fn()
 	}
-	fn()
 	return nil
 }
 
@@ -158,7 +333,7 @@ func (v *VMContext) get(key []byte) ([]byte, error) {
 		return nil
 	})
 	if err != nil {
-		if err == badger.ErrKeyNotFound {
+		if err == db.ErrKeyNotFound {
 			return nil, ErrStorageNotFound
 		}
 		return nil, err
@@ -166,7 +341,10 @@ func (v *VMContext) get(key []byte) ([]byte, error) {
 	return storage, nil
 }
 
-func (v *VMContext) set(key []byte, value []byte) error {
+func (v *VMContext) set(key []byte, value []byte, txns ...db.StoreTxn) error {
+	if len(txns) > 0 {
+		return txns[0].Set(key, value)
+	}
 	txn := v.ledger.Store.NewTransaction(true)
 	defer func() {
 		txn.Commit(nil)
@@ -177,7 +355,7 @@ func (v *VMContext) set(key []byte, value []byte) error {
 	//})
 	//if err == nil {
 	//	return ErrStorageExists
-	//} else if err != badger.ErrKeyNotFound {
+	//} else if err != db.ErrKeyNotFound {
 	//	return err
 	//}
 	return txn.Set(key, value)
@@ -14,9 +14,11 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/config"
 	"github.com/qlcchain/go-qlc/ledger"
 	"github.com/qlcchain/go-qlc/test/mock"
+	"github.com/qlcchain/go-qlc/trie"
 )
 
 func setupTestCase(t *testing.T) (func(t *testing.T), *VMContext) {
@@ -83,7 +85,7 @@ func TestLedger_Storage(t *testing.T) {
 		}
 	}
 
-	cacheTrie := context.Cache.Trie()
+	cacheTrie := context.Cache.Trie(types.Address(prefix))
 	if cacheTrie == nil {
 		t.Fatal("invalid trie")
 	}
@@ -94,3 +96,124 @@ func TestLedger_Storage(t *testing.T) {
 		t.Log(hash.String())
 	}
 }
+
+func TestVMContext_StorageDiffs(t *testing.T) {
+	teardownTestCase, context := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	prefix := mock.Hash()
+	key := []byte{10, 20, 30}
+	if err := context.SetStorage(prefix[:], key, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := context.SaveStorage(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewVMContext(context.ledger)
+	if err := ctx.SetStorage(prefix[:], key, []byte{2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.SetStorage(prefix[:], []byte{1}, []byte{3}); err != nil {
+		t.Fatal(err)
+	}
+	diffs, err := ctx.StorageDiffs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 2 {
+		t.Fatal("invalid diff count", len(diffs))
+	}
+	if !bytes.Equal(diffs[0].Key, append(prefix[:], 1)) || diffs[0].Previous != nil || !bytes.Equal(diffs[0].Value, []byte{3}) {
+		t.Fatal("invalid new storage", diffs[0])
+	}
+	if !bytes.Equal(diffs[1].Key, append(prefix[:], key...)) || !bytes.Equal(diffs[1].Previous, []byte{1}) ||
+		!bytes.Equal(diffs[1].Value, []byte{2}) {
+		t.Fatal("invalid changed storage", diffs[1])
+	}
+}
+
+func TestVMContext_ContractRoot(t *testing.T) {
+	teardownTestCase, context := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	address := mock.Address()
+	key := []byte{10, 20, 30}
+	if err := context.SetStorage(address[:], key, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := context.SaveStorage(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the storage saved before the trie commitment is moved into the trie
+	if err := context.InitContractRoot(address); err != nil {
+		t.Fatal(err)
+	}
+	root, err := context.ledger.GetContractRoot(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := context.ContractRoot(address); r != root || r.IsZero() {
+		t.Fatal("invalid contract root", r, root)
+	}
+
+	// the root follows the storage changes of the context
+	ctx := NewVMContext(context.ledger)
+	if val, err := ctx.GetStorage(address[:], key); err != nil || !bytes.Equal(val, []byte{1}) {
+		t.Fatal("invalid storage", val, err)
+	}
+	if err := ctx.SetStorage(address[:], key, []byte{2}); err != nil {
+		t.Fatal(err)
+	}
+	changed := ctx.ContractRoot(address)
+	if changed == root {
+		t.Fatal("contract root should be changed")
+	}
+	if err := ctx.SaveTrie(); err != nil {
+		t.Fatal(err)
+	}
+	if err := context.ledger.SetContractRoot(address, changed); err != nil {
+		t.Fatal(err)
+	}
+
+	// the trie is reloaded at the new root
+	ctx = NewVMContext(context.ledger)
+	if r := ctx.ContractRoot(address); r != changed {
+		t.Fatal("invalid reloaded root", r, changed)
+	}
+	if val := ctx.Cache.Trie(address).GetValue(getStorageKey(address[:], key)); !bytes.Equal(val, []byte{2}) {
+		t.Fatal("invalid trie value", val)
+	}
+	// the storage of a contract with a root is read from the trie and never saved to the flat store
+	if val, err := ctx.GetStorage(address[:], key); err != nil || !bytes.Equal(val, []byte{2}) {
+		t.Fatal("invalid storage", val, err)
+	}
+	other := NewVMContext(context.ledger)
+	if err := other.SetStorage(address[:], []byte{1}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.SaveStorage(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewVMContext(context.ledger).GetStorage(address[:], []byte{1}); err != ErrStorageNotFound {
+		t.Fatal(err)
+	}
+	var keys [][]byte
+	err = ctx.Iterator(address[:], func(key []byte, value []byte) error {
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil || len(keys) != 1 || !bytes.Equal(keys[0], getStorageKey(address[:], key)) {
+		t.Fatal("invalid iterator", keys, err)
+	}
+
+	// the storage is proved against the contract root
+	trieKey, proof, err := ctx.StorageProof(address, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val, err := trie.VerifyProof(changed, trieKey, proof); err != nil || !bytes.Equal(val, []byte{2}) {
+		t.Fatal("invalid proof", val, err)
+	}
+}
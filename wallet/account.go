@@ -0,0 +1,246 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/ledger/db"
+)
+
+const defaultGapLimit = 20
+
+var ErrEmptySeed = errors.New("can not find seed of the wallet")
+
+// AccountInfo is an account derived from the wallet seed
+type AccountInfo struct {
+	Address types.Address `json:"address"`
+	Index   uint32        `json:"index"`
+	Label   string        `json:"label"`
+	Used    bool          `json:"used"` //account has blocks or receivable blocks in the ledger
+}
+
+func (s *Session) seed() (*types.Seed, error) {
+	seedArray, err := s.GetSeed()
+	if err != nil {
+		return nil, err
+	}
+	if len(seedArray) == 0 {
+		return nil, ErrEmptySeed
+	}
+	return types.BytesToSeed(seedArray)
+}
+
+// isUsed returns true if the account has blocks in the ledger or pending blocks, an account with pending blocks
+// is used even if it has no block in the ledger yet
+func (s *Session) isUsed(address types.Address) (bool, error) {
+	if b, err := s.ledger.HasAccountMeta(address); err == nil && b {
+		return true, nil
+	}
+	pending := false
+	err := s.ledger.SearchPending(address, func(key *types.PendingKey, value *types.PendingInfo) error {
+		pending = true
+		return nil
+	})
+	return pending, err
+}
+
+// Discover scans the accounts derived from the seed in order and stops after gapLimit consecutive unused accounts,
+// the deterministic index is moved after the last used account. It returns all used accounts it found.
+// If gapLimit is zero, the gap limit of the wallet store is used
+func (s *Session) Discover(gapLimit uint32) ([]types.Address, error) {
+	if gapLimit == 0 {
+		gapLimit = s.gapLimit
+	}
+	seed, err := s.seed()
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]types.Address, 0)
+	next := uint32(0)
+	for i, gap := uint32(0), uint32(0); gap < gapLimit; i++ {
+		account, err := seed.Account(i)
+		if err != nil {
+			return nil, err
+		}
+		address := account.Address()
+		used, err := s.isUsed(address)
+		if err != nil {
+			return nil, err
+		}
+		if used {
+			accounts = append(accounts, address)
+			next = i + 1
+			gap = 0
+		} else {
+			gap++
+		}
+	}
+
+	index, err := s.GetDeterministicIndex()
+	if err != nil {
+		return nil, err
+	}
+	if int64(next) > index {
+		if err := s.SetDeterministicIndex(int64(next)); err != nil {
+			return nil, err
+		}
+	}
+	return accounts, nil
+}
+
+// NewAccount derives the account at the deterministic index, labels it and moves the index to the next one
+func (s *Session) NewAccount(label string) (types.Address, error) {
+	seed, err := s.seed()
+	if err != nil {
+		return types.ZeroAddress, err
+	}
+	index, err := s.GetDeterministicIndex()
+	if err != nil {
+		return types.ZeroAddress, err
+	}
+	account, err := seed.Account(uint32(index))
+	if err != nil {
+		return types.ZeroAddress, err
+	}
+	address := account.Address()
+	err = s.UpdateInTx(func(txn db.StoreTxn) error {
+		if err := s.setDeterministicIndex(txn, index+1); err != nil {
+			return err
+		}
+		return s.setLabel(txn, address, label)
+	})
+	if err != nil {
+		return types.ZeroAddress, err
+	}
+	return address, nil
+}
+
+// Accounts returns all accounts derived before the deterministic index
+func (s *Session) Accounts() ([]*AccountInfo, error) {
+	seed, err := s.seed()
+	if err != nil {
+		return nil, err
+	}
+	index, err := s.GetDeterministicIndex()
+	if err != nil {
+		return nil, err
+	}
+	labels, err := s.GetLabels()
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]*AccountInfo, 0)
+	for i := uint32(0); i < uint32(index); i++ {
+		account, err := seed.Account(i)
+		if err != nil {
+			return nil, err
+		}
+		address := account.Address()
+		used, err := s.isUsed(address)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, &AccountInfo{
+			Address: address,
+			Index:   i,
+			Label:   labels[address],
+			Used:    used,
+		})
+	}
+	return accounts, nil
+}
+
+// SetLabel labels an account of the wallet, an empty label removes the label
+func (s *Session) SetLabel(address types.Address, label string) error {
+	if _, err := s.GetRawKey(address); err != nil {
+		return fmt.Errorf("account %s does not belong to the wallet", address)
+	}
+	return s.UpdateInTx(func(txn db.StoreTxn) error {
+		return s.setLabel(txn, address, label)
+	})
+}
+
+func (s *Session) setLabel(txn db.StoreTxn, address types.Address, label string) error {
+	key := s.getLabelKey(address)
+	if len(label) == 0 {
+		err := txn.Delete(key)
+		if err == db.ErrKeyNotFound {
+			return nil
+		}
+		return err
+	}
+	return txn.Set(key, []byte(label))
+}
+
+func (s *Session) GetLabel(address types.Address) (string, error) {
+	var label string
+	err := s.ViewInTx(func(txn db.StoreTxn) error {
+		return txn.Get(s.getLabelKey(address), func(val []byte, b byte) error {
+			label = string(val)
+			return nil
+		})
+	})
+	if err == db.ErrKeyNotFound {
+		err = nil
+	}
+	return label, err
+}
+
+// GetLabels returns labels of all labelled accounts of the wallet
+func (s *Session) GetLabels() (map[types.Address]string, error) {
+	labels := make(map[types.Address]string)
+	err := s.ViewInTx(func(txn db.StoreTxn) error {
+		return s.iterateLabels(txn, func(key []byte, val []byte) error {
+			address, err := types.BytesToAddress(key[1+len(s.walletId):])
+			if err != nil {
+				return err
+			}
+			labels[address] = string(val)
+			return nil
+		})
+	})
+	return labels, err
+}
+
+func (s *Session) iterateLabels(txn db.StoreTxn, fn func(key []byte, val []byte) error) error {
+	prefix := s.getKey(idPrefixLabel)
+	return txn.Iterator(idPrefixLabel, func(key []byte, val []byte, b byte) error {
+		if !bytes.HasPrefix(key, prefix) {
+			return nil
+		}
+		return fn(key, val)
+	})
+}
+
+func (s *Session) removeLabels(txn db.StoreTxn) error {
+	var keys [][]byte
+	err := s.iterateLabels(txn, func(key []byte, val []byte) error {
+		k := make([]byte, len(key))
+		copy(k, key)
+		keys = append(keys, k)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := txn.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Session) getLabelKey(address types.Address) []byte {
+	key := s.getKey(idPrefixLabel)
+	return append(key, address[:]...)
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package wallet
+
+import (
+	"testing"
+
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/test/mock"
+)
+
+func TestSession_Discover(t *testing.T) {
+	teardownTestCase, store := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	seed, err := types.NewSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := func(i uint32) types.Address {
+		a, err := seed.Account(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a.Address()
+	}
+	for _, i := range []uint32{0, 3} {
+		if err := store.ledger.AddAccountMeta(mock.AccountMeta(address(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pendingKey := &types.PendingKey{Address: address(7), Hash: mock.Hash()}
+	pendingInfo := &types.PendingInfo{Source: mock.Address(), Amount: types.StringToBalance("100"), Type: mock.Hash()}
+	if err := store.ledger.AddPending(pendingKey, pendingInfo); err != nil {
+		t.Fatal(err)
+	}
+
+	// restoring the seed discovers all used accounts with the default gap limit
+	id, err := store.NewWalletBySeed(seed.String(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := store.NewSession(id)
+	if index, err := session.GetDeterministicIndex(); err != nil || index != 8 {
+		t.Fatal("invalid index", index, err)
+	}
+
+	accounts, err := session.Discover(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 || accounts[0] != address(0) {
+		t.Fatal("invalid accounts", accounts)
+	}
+	accounts, err = session.Discover(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 3 || accounts[2] != address(7) {
+		t.Fatal("invalid accounts", accounts)
+	}
+	if index, err := session.GetDeterministicIndex(); err != nil || index != 8 {
+		t.Fatal("invalid index", index, err)
+	}
+}
+
+func TestSession_NewAccount(t *testing.T) {
+	teardownTestCase, store := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	id, err := store.NewWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := store.NewSession(id)
+	addr, err := session.NewAccount("savings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label, err := session.GetLabel(addr); err != nil || label != "savings" {
+		t.Fatal("invalid label", label, err)
+	}
+
+	accounts, err := session.Accounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 2 || accounts[1].Address != addr || accounts[1].Index != 1 || accounts[1].Label != "savings" || accounts[1].Used {
+		t.Fatal("invalid accounts", accounts)
+	}
+
+	if err := session.SetLabel(accounts[0].Address, "main"); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.SetLabel(addr, ""); err != nil {
+		t.Fatal(err)
+	}
+	labels, err := session.GetLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 1 || labels[accounts[0].Address] != "main" {
+		t.Fatal("invalid labels", labels)
+	}
+	if err := session.SetLabel(mock.Address(), "other"); err == nil {
+		t.Fatal("label an account out of wallet should fail")
+	}
+
+	if err := store.RemoveWallet(id); err != nil {
+		t.Fatal(err)
+	}
+	if labels, err := session.GetLabels(); err != nil || len(labels) != 0 {
+		t.Fatal("labels should be removed with wallet", labels, err)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package wallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/common/util"
+)
+
+// KeystoreVersion is the version of the keystore file format
+const KeystoreVersion = 1
+
+var (
+	ErrInvalidKeystore  = errors.New("invalid keystore")
+	ErrKeystorePassword = errors.New("can not decrypt keystore, password is invalid")
+)
+
+// Keystore is the backup file of a wallet, it is a JSON document like
+//
+//	{
+//	  "version": 1,
+//	  "walletId": "qlc_1...",
+//	  "seed": "eyJjcnlwdG8iOnsiY2lwaGVydGV4dCI6...",
+//	  "index": 3,
+//	  "representative": "qlc_3...",
+//	  "walletVersion": 1
+//	}
+//
+// version is the keystore format version, walletId is the master address of the seed, seed is the wallet seed
+// encrypted by util.Encrypt (scrypt derived key, AES-GCM) with the wallet password, index is the deterministic
+// index, representative is the default representative of the wallet and walletVersion is the wallet schema version.
+type Keystore struct {
+	Version        int           `json:"version"`
+	WalletId       types.Address `json:"walletId"`
+	Seed           string        `json:"seed"`
+	Index          int64         `json:"index"`
+	Representative types.Address `json:"representative"`
+	WalletVersion  int64         `json:"walletVersion"`
+}
+
+// NewKeystore encrypts the seed with password and creates a keystore for it
+func NewKeystore(seed []byte, password string) (*Keystore, error) {
+	s, err := types.BytesToSeed(seed)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := util.Encrypt(hex.EncodeToString(seed), password)
+	if err != nil {
+		return nil, err
+	}
+	return &Keystore{
+		Version:       KeystoreVersion,
+		WalletId:      s.MasterAddress(),
+		Seed:          encrypted,
+		WalletVersion: Version,
+	}, nil
+}
+
+// ParseKeystore parses the JSON content of a keystore file
+func ParseKeystore(content []byte) (*Keystore, error) {
+	ks := new(Keystore)
+	if err := json.Unmarshal(content, ks); err != nil {
+		return nil, fmt.Errorf("%s: %s", ErrInvalidKeystore, err)
+	}
+	if ks.Version != KeystoreVersion {
+		return nil, fmt.Errorf("%s: unsupported version %d", ErrInvalidKeystore, ks.Version)
+	}
+	if len(ks.Seed) == 0 || ks.WalletId.IsZero() {
+		return nil, ErrInvalidKeystore
+	}
+	return ks, nil
+}
+
+// DecryptSeed decrypts the seed of the keystore and checks it against the wallet id
+func (ks *Keystore) DecryptSeed(password string) ([]byte, error) {
+	// util.Decrypt returns an empty string instead of an error when the password is wrong
+	raw, err := util.Decrypt(ks.Seed, password)
+	if err != nil || len(raw) == 0 {
+		return nil, ErrKeystorePassword
+	}
+	seed, err := hex.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	s, err := types.BytesToSeed(seed)
+	if err != nil {
+		return nil, err
+	}
+	if s.MasterAddress() != ks.WalletId {
+		return nil, fmt.Errorf("%s: seed does not match wallet %s", ErrInvalidKeystore, ks.WalletId)
+	}
+	return seed, nil
+}
+
+// String implements the fmt.Stringer interface.
+func (ks *Keystore) String() string {
+	return util.ToIndentString(ks)
+}
+
+// Account decrypts the seed and returns the account of address derived from it, the accounts before
+// the deterministic index of the keystore are searched, at least searchAccountCount of them
+func (ks *Keystore) Account(password string, address types.Address) (*types.Account, error) {
+	seedArray, err := ks.DecryptSeed(password)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := types.BytesToSeed(seedArray)
+	if err != nil {
+		return nil, err
+	}
+	count := max(uint32(ks.Index), searchAccountCount)
+	for i := uint32(0); i < count; i++ {
+		a, err := seed.Account(i)
+		if err != nil {
+			return nil, err
+		}
+		if a.Address() == address {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("can not find account[%s] in the keystore", address.String())
+}
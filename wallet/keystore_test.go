@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2019 QLC Chain Team
+ *
+ * This software is released under the MIT License.
+ * https://opensource.org/licenses/MIT
+ */
+
+package wallet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/qlcchain/go-qlc/common/types"
+	"github.com/qlcchain/go-qlc/test/mock"
+)
+
+func TestKeystore_Account(t *testing.T) {
+	seed, err := types.NewSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := NewKeystore(seed[:], "pwd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks2, err := ParseKeystore([]byte(ks.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ks2.WalletId != seed.MasterAddress() {
+		t.Fatal("invalid wallet id", ks2.WalletId)
+	}
+
+	a, err := seed.Account(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acc, err := ks2.Account("pwd", a.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := mock.Hash()
+	sign := acc.Sign(hash)
+	if !a.Address().Verify(hash[:], sign[:]) {
+		t.Fatal("invalid account")
+	}
+	if _, err := ks2.Account("invalid", a.Address()); err != ErrKeystorePassword {
+		t.Fatal(err)
+	}
+	if _, err := ks2.Account("pwd", mock.Address()); err == nil {
+		t.Fatal("find account out of keystore")
+	}
+
+	if _, err := ParseKeystore([]byte(strings.Replace(ks.String(), `"version": 1`, `"version": 2`, 1))); err == nil {
+		t.Fatal("parse keystore of unsupported version")
+	}
+}
@@ -18,6 +18,12 @@ type walletStoreApi interface {
 	SetRepresentative(address types.Address) error
 	IsAccountExist(addr types.Address) bool
 	GetAccounts() ([]types.Address, error)
+	Accounts() ([]*AccountInfo, error)
+	NewAccount(label string) (types.Address, error)
+	Discover(gapLimit uint32) ([]types.Address, error)
+	SetLabel(address types.Address, label string) error
+	GetLabel(address types.Address) (string, error)
+	GetLabels() (map[types.Address]string, error)
 	ChangePassword(password string) error
 	EnterPassword(password string) error
 	VerifyPassword(password string) (bool, error)
@@ -8,15 +8,17 @@
 package wallet
 
 import (
+	"bytes"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"sync"
 
 	"github.com/qlcchain/go-qlc/common"
 
-	"github.com/dgraph-io/badger"
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/common/util"
 	"github.com/qlcchain/go-qlc/crypto"
@@ -34,6 +36,7 @@ const (
 	idPrefixIndex
 	idPrefixRepresentation
 	idPrefixWork
+	idPrefixLabel
 )
 
 const (
@@ -44,9 +47,10 @@ const (
 type WalletStore struct {
 	io.Closer
 	db.Store
-	dir    string
-	ledger *ledger.Ledger
-	logger *zap.SugaredLogger
+	dir      string
+	ledger   *ledger.Ledger
+	logger   *zap.SugaredLogger
+	gapLimit uint32
 }
 
 type Session struct {
@@ -55,6 +59,7 @@ type Session struct {
 	ledger          *ledger.Ledger
 	logger          *zap.SugaredLogger
 	maxAccountCount uint64
+	gapLimit        uint32
 	walletId        []byte
 	password        *crypto.SecureString
 }
@@ -69,6 +74,7 @@ func (ws *WalletStore) NewSession(walletId types.Address) *Session {
 		ledger:          ws.ledger,
 		logger:          log.NewLogger("wallet session: " + walletId.String()),
 		maxAccountCount: searchAccountCount,
+		gapLimit:        ws.gapLimit,
 		walletId:        walletId.Bytes(),
 	}
 	//update database
@@ -92,7 +98,7 @@ func (s *Session) removeWallet(txn db.StoreTxn) error {
 		}
 	}
 
-	return nil
+	return s.removeLabels(txn)
 }
 
 func (s *Session) EnterPassword(password string) error {
@@ -162,7 +168,7 @@ func (s *Session) GetSeed() ([]byte, error) {
 		})
 	})
 
-	if err == badger.ErrKeyNotFound {
+	if err == db.ErrKeyNotFound {
 		err = nil
 	}
 
@@ -400,12 +406,83 @@ func (s *Session) GenerateChangeBlock(account types.Address, representative type
 
 }
 
+// Import restores the wallet of the session from the content of a keystore file encrypted by password,
+// the wallet password is set to password
 func (s *Session) Import(content string, password string) error {
-	panic("implement me")
+	ks, err := ParseKeystore([]byte(content))
+	if err != nil {
+		return err
+	}
+	err = s.UpdateInTx(func(txn db.StoreTxn) error {
+		return s.importByTxn(txn, ks, password)
+	})
+	if err != nil {
+		return err
+	}
+	s.setPassword(password)
+	return nil
+}
+
+//importByTxn saves the keystore by txn, the password of the session is left to the caller once txn is committed
+func (s *Session) importByTxn(txn db.StoreTxn, ks *Keystore, password string) error {
+	if !bytes.Equal(ks.WalletId.Bytes(), s.walletId) {
+		return fmt.Errorf("keystore of wallet %s can not be imported to %s", ks.WalletId, hex.EncodeToString(s.walletId))
+	}
+	seed, err := ks.DecryptSeed(password)
+	if err != nil {
+		return err
+	}
+	encryptSeed, err := util.EncryptBytes(seed, []byte(password))
+	if err != nil {
+		return err
+	}
+	if err := txn.Set(s.getKey(idPrefixSeed), encryptSeed); err != nil {
+		return err
+	}
+	if err := s.setDeterministicIndex(txn, ks.Index); err != nil {
+		return err
+	}
+	if err := s.setVersion(txn, ks.WalletVersion); err != nil {
+		return err
+	}
+	if !ks.Representative.IsZero() {
+		return txn.Set(s.getKey(idPrefixRepresentation), ks.Representative[:])
+	}
+	return nil
 }
 
+// Export writes the wallet to path as a keystore file, the seed is encrypted by the current wallet password
 func (s *Session) Export(path string) error {
-	panic("implement me")
+	ks, err := s.Keystore()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(ks.String()), 0600)
+}
+
+// Keystore returns the keystore of the wallet, the seed is encrypted by the current wallet password
+func (s *Session) Keystore() (*Keystore, error) {
+	seed, err := s.GetSeed()
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) == 0 {
+		return nil, ErrEmptySeed
+	}
+	ks, err := NewKeystore(seed, string(s.getPassword()))
+	if err != nil {
+		return nil, err
+	}
+	if index, err := s.GetDeterministicIndex(); err == nil {
+		ks.Index = index
+	}
+	if version, err := s.GetVersion(); err == nil {
+		ks.WalletVersion = version
+	}
+	if rep, err := s.GetRepresentative(); err == nil {
+		ks.Representative = rep
+	}
+	return ks, nil
 }
 
 func (s *Session) GetVersion() (int64, error) {
@@ -472,7 +549,7 @@ func (s *Session) GetWork(account types.Address) (types.Work, error) {
 		})
 	})
 
-	if err != nil && err == badger.ErrKeyNotFound {
+	if err != nil && err == db.ErrKeyNotFound {
 		err = nil
 	}
 
@@ -10,6 +10,7 @@ package wallet
 import (
 	"encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -108,6 +109,79 @@ func TestSession_ChangePassword(t *testing.T) {
 	}
 }
 
+func TestSession_ExportImport(t *testing.T) {
+	teardownTestCase, store := setupTestCase(t)
+	defer teardownTestCase(t)
+
+	pwd := "37yBR94bvj4wbkYc"
+	seed, err := types.NewSeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := store.NewWalletBySeed(seed.String(), pwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := store.NewSession(id)
+	if _, err := session.VerifyPassword(pwd); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.SetDeterministicIndex(5); err != nil {
+		t.Fatal(err)
+	}
+	rep := mock.Address()
+	if err := session.SetRepresentative(rep); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(store.dir, "keystore.json")
+	if err := session.Export(path); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Import(string(content), pwd); err == nil {
+		t.Fatal("import an exist wallet should fail")
+	}
+	if err := store.RemoveWallet(id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Import(string(content), "invalid"); err != ErrKeystorePassword {
+		t.Fatal(err)
+	}
+
+	id2, err := store.Import(string(content), pwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id2 != id {
+		t.Fatal("invalid wallet id", id2)
+	}
+	session2 := store.NewSession(id2)
+	if _, err := session2.VerifyPassword(pwd); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := session2.GetSeed(); err != nil || !reflect.DeepEqual(s, seed[:]) {
+		t.Fatal("seed mismatch", err)
+	}
+	if index, err := session2.GetDeterministicIndex(); err != nil || index != 5 {
+		t.Fatal("invalid index", index, err)
+	}
+	if r, err := session2.GetRepresentative(); err != nil || r != rep {
+		t.Fatal("invalid representative", r, err)
+	}
+	if current, err := store.CurrentId(); err != nil || current != id {
+		t.Fatal("invalid current id", current, err)
+	}
+
+	other := store.NewSession(mock.Address())
+	if err := other.Import(string(content), pwd); err == nil {
+		t.Fatal("import keystore to other wallet should fail")
+	}
+}
+
 func TestSession_ValidPassword(t *testing.T) {
 	teardownTestCase, store := setupTestCase(t)
 	defer teardownTestCase(t)
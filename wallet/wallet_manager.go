@@ -13,6 +13,7 @@ type walletManager interface {
 	WalletIds() ([]types.Address, error)
 	NewWalletBySeed(seed string) (types.Address, error)
 	NewWallet() (types.Address, error)
+	Import(content, password string) (types.Address, error)
 	CurrentId() (types.Address, error)
 	RemoveWallet(id types.Address) error
 	IsWalletExist(address types.Address) (bool, error)
@@ -13,7 +13,6 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/dgraph-io/badger"
 	"github.com/pkg/errors"
 	"github.com/qlcchain/go-qlc/common/types"
 	"github.com/qlcchain/go-qlc/config"
@@ -41,11 +40,17 @@ func NewWalletStore(cfg *config.Config) *WalletStore {
 			logger.Fatal(err.Error())
 		}
 
+		gapLimit := uint32(defaultGapLimit)
+		if cfg.Wallet != nil && cfg.Wallet.GapLimit > 0 {
+			gapLimit = uint32(cfg.Wallet.GapLimit)
+		}
+
 		cache[dir] = &WalletStore{
-			ledger: ledger.NewLedger(cfg.LedgerDir()),
-			logger: logger,
-			Store:  store,
-			dir:    dir,
+			ledger:   ledger.NewLedger(cfg.LedgerDir()),
+			logger:   logger,
+			Store:    store,
+			dir:      dir,
+			gapLimit: gapLimit,
 		}
 	}
 	return cache[dir]
@@ -63,7 +68,7 @@ func (ws *WalletStore) WalletIds() ([]types.Address, error) {
 		})
 	})
 
-	if err != nil && err == badger.ErrKeyNotFound {
+	if err != nil && err == db.ErrKeyNotFound {
 		err = nil
 	}
 
@@ -133,8 +138,68 @@ func (ws *WalletStore) NewWalletBySeed(seed, password string) (types.Address, er
 
 		return nil
 	})
+	if err != nil {
+		return walletId, err
+	}
+
+	ws.discover(session)
+	return walletId, nil
+}
+
+// discover finds the used accounts of a restored seed
+func (ws *WalletStore) discover(session *Session) {
+	if accounts, err := session.Discover(0); err != nil {
+		ws.logger.Error(err)
+	} else if len(accounts) > 0 {
+		ws.logger.Infof("discover %d accounts of wallet %s", len(accounts), hex.EncodeToString(session.walletId))
+	}
+}
+
+// Import creates wallet from the content of a keystore file encrypted by password
+func (ws *WalletStore) Import(content, password string) (types.Address, error) {
+	ks, err := ParseKeystore([]byte(content))
+	if err != nil {
+		return types.ZeroAddress, err
+	}
+	walletId := ks.WalletId
+	if b, err := ws.IsWalletExist(walletId); b && err == nil {
+		return walletId, fmt.Errorf("wallet[%s] already exist", walletId)
+	}
+
+	session := ws.NewSession(walletId)
+	ids, err := ws.WalletIds()
+	if err != nil {
+		return types.ZeroAddress, err
+	}
+
+	ids = append(ids, walletId)
+
+	err = ws.UpdateInTx(func(txn db.StoreTxn) error {
+		if err := session.importByTxn(txn, ks, password); err != nil {
+			return err
+		}
+
+		//add new walletId to ids
+		key := []byte{idPrefixIds}
+		bytes, err := json.Marshal(&ids)
+		if err != nil {
+			return err
+		}
+		err = txn.Set(key, bytes)
+		if err != nil {
+			return err
+		}
+
+		// update current wallet id
+		return ws.setCurrentId(txn, walletId.Bytes())
+	})
+	if err != nil {
+		return types.ZeroAddress, err
+	}
+	session.setPassword(password)
 
-	return walletId, err
+	ws.discover(session)
+	return walletId, nil
 }
 
 // IsWalletExist check is the wallet exist by master address